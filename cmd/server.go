@@ -27,6 +27,9 @@ func main() {
 	//if err := seeds.Seed(application.DB); err != nil {
 	//	log.Fatalf("Seeding error: %v", err)
 	//}
+	// Background jobs: lesson status transitions, series materialization.
+	application.Scheduler.Start()
+	defer application.Scheduler.Stop()
 	router := routes.Setup(application)
 	port := ":" + cfg.Port
 	log.Printf("Starting server on port %s...", port)
@@ -1,11 +1,21 @@
 package app
 
 import (
+	"fmt"
+	"time"
+
 	"gorm.io/gorm"
 	"vibely-backend/src/config"
 	"vibely-backend/src/database"
+	"vibely-backend/src/discord"
+	"vibely-backend/src/events"
+	"vibely-backend/src/jobs"
+	"vibely-backend/src/mail"
+	"vibely-backend/src/meeting"
 	"vibely-backend/src/repositories"
 	"vibely-backend/src/services"
+	"vibely-backend/src/storage"
+	"vibely-backend/src/webhook"
 )
 
 type Application struct {
@@ -16,6 +26,27 @@ type Application struct {
 	LessonService services.LessonService
 	CourseService services.CourseService
 	TAService     services.TutorAvailabilityService
+	Moderation    services.ModerationService
+
+	LessonSeriesService services.LessonSeriesService
+	RescheduleService   services.RescheduleService
+
+	CancellationPolicyService services.CancellationPolicyService
+	BookingService            services.BookingService
+	TutorOfferingService      services.TutorOfferingService
+	BookingRulesService       services.BookingRulesService
+	EnrollmentService         services.EnrollmentService
+	LessonMaterialService     services.LessonMaterialService
+	HomeworkService           services.HomeworkService
+	QuizService               services.QuizService
+	MessageService            services.MessageService
+	NotificationService       services.NotificationService
+	WebhookService            services.WebhookService
+	MailService               services.MailService
+
+	// Events carries domain events to users connected to the real-time stream.
+	Events    events.Bus
+	Scheduler *jobs.Scheduler
 }
 
 func New(cfg config.Config) (*Application, error) {
@@ -24,15 +55,156 @@ func New(cfg config.Config) (*Application, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Keep enough events for clients to resume after a short disconnect.
+	eventBus := events.NewBus(1000)
 	userRepository := repositories.NewUserRepository(db)
+	lessonRepository := repositories.NewLessonRepository(db)
+	jobRepository := repositories.NewJobRepository(db)
+	// The Discord bot is optional; without it notifications skip Discord and lessons get no channels.
+	discordEnabled := cfg.DiscordBotToken != "" && cfg.DiscordGuildID != ""
+	notificationRepository := repositories.NewNotificationRepository(db)
+	if !mail.IsLanguage(cfg.MailDefaultLanguage) {
+		return nil, fmt.Errorf("unsupported mail language %q", cfg.MailDefaultLanguage)
+	}
+	mailTemplates, err := mail.LoadTemplates()
+	if err != nil {
+		return nil, err
+	}
+	mailTransport, err := mail.NewTransport(cfg.MailTransport, cfg.MailFrom, mail.SMTPSettings{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+	}, cfg.MailDir)
+	if err != nil {
+		return nil, err
+	}
+	mailService := services.NewMailService(repositories.NewMailRepository(db), userRepository, notificationRepository, jobRepository, mailTemplates, mailTransport, cfg.MailDefaultLanguage, cfg.FrontendUrl, cfg.BackendUrl)
+	notificationService := services.NewNotificationService(notificationRepository, mailService, jobRepository, eventBus, discordEnabled)
+	lessonReminderService := services.NewLessonReminderService(jobRepository, lessonRepository, events.Fanout(eventBus, notificationService), cfg.LessonReminderOffsets)
+	webhookService := services.NewWebhookService(repositories.NewWebhookRepository(db), jobRepository,
+		webhook.NewSender(cfg.WebhookTimeout, cfg.WebhookAllowInsecure), cfg.WebhookAllowInsecure)
+	// Domain events go to connected users, the notification center, lesson reminders,
+	// webhooks, meeting links and Discord.
+	subscribers := []events.Publisher{eventBus, notificationService, lessonReminderService, webhookService}
+	var discordService services.DiscordService
+	if discordEnabled {
+		discordClient := discord.NewClient(cfg.DiscordAPIURL, cfg.DiscordBotToken, cfg.DiscordGuildID, cfg.DiscordLessonCategoryID)
+		discordService = services.NewDiscordService(discordClient, repositories.NewDiscordRepository(db), lessonRepository, userRepository, jobRepository, cfg.DiscordChannelCleanupDelay)
+		subscribers = append(subscribers, discordService)
+	}
+	meetingProvider, err := meeting.NewProvider(cfg.MeetingProvider, cfg.MeetingBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	var meetingService services.MeetingService
+	if meetingProvider != nil {
+		meetingService = services.NewMeetingService(meetingProvider, lessonRepository, jobRepository)
+		subscribers = append(subscribers, meetingService)
+	}
+	publisher := events.Fanout(subscribers...)
 	authService := services.NewAuthService(cfg.AccessJWTSecretKey, cfg.RefreshJWTSecretKey)
 	userService := services.NewUserService(userRepository, authService)
-	lessonRepository := repositories.NewLessonRepository(db)
-	lessonService := services.NewLessonService(lessonRepository)
-	courseRepository := repositories.NewCourseRepository(db)
-	courseService := services.NewCourseService(courseRepository, lessonService)
+	moderationRepository := repositories.NewModerationRepository(db)
 	tutorAvailabilityRepository := repositories.NewTutorAvailabilityRepository(db)
-	tutorAvailabilityService := services.NewTutorAvailabilityService(tutorAvailabilityRepository, userRepository)
+	tutorAvailabilityService := services.NewTutorAvailabilityService(tutorAvailabilityRepository, userRepository, publisher)
+	cancellationPolicyRepository := repositories.NewCancellationPolicyRepository(db)
+	cancellationPolicyService := services.NewCancellationPolicyService(cancellationPolicyRepository, userRepository)
+	bookingRulesRepository := repositories.NewBookingRulesRepository(db)
+	bookingRulesService := services.NewBookingRulesService(bookingRulesRepository, userRepository)
+	lessonService := services.NewLessonService(lessonRepository, moderationRepository, cancellationPolicyRepository, bookingRulesRepository, tutorAvailabilityService, publisher)
+	courseRepository := repositories.NewCourseRepository(db)
+	courseService := services.NewCourseService(courseRepository, publisher)
+	moderationService := services.NewModerationService(moderationRepository, userRepository, courseRepository)
+	lessonSeriesRepository := repositories.NewLessonSeriesRepository(db)
+	lessonSeriesService := services.NewLessonSeriesService(
+		lessonSeriesRepository,
+		lessonRepository,
+		userRepository,
+		lessonService,
+		time.Duration(cfg.LessonSeriesHorizonDays)*24*time.Hour,
+	)
+	tutorOfferingRepository := repositories.NewTutorOfferingRepository(db)
+	tutorOfferingService := services.NewTutorOfferingService(tutorOfferingRepository, userRepository)
+	bookingService := services.NewBookingService(userRepository, lessonRepository, tutorOfferingRepository, lessonService, cfg.SlotHoldTTL)
+	enrollmentRepository := repositories.NewEnrollmentRepository(db)
+	enrollmentService := services.NewEnrollmentService(enrollmentRepository, moderationRepository, cancellationPolicyRepository, notificationService, publisher)
+	files, err := storage.NewLocalStorage(cfg.StorageDir)
+	if err != nil {
+		return nil, err
+	}
+	lessonMaterialRepository := repositories.NewLessonMaterialRepository(db)
+	lessonMaterialService := services.NewLessonMaterialService(lessonMaterialRepository, lessonRepository, files, cfg.MaxAttachmentSize)
+	homeworkRepository := repositories.NewHomeworkRepository(db)
+	homeworkService := services.NewHomeworkService(homeworkRepository, lessonMaterialRepository, lessonRepository, files, cfg.MaxAttachmentSize)
+	quizRepository := repositories.NewQuizRepository(db)
+	quizService := services.NewQuizService(quizRepository, courseRepository, lessonRepository)
+	messageRepository := repositories.NewMessageRepository(db)
+	messageService := services.NewMessageService(messageRepository, userRepository, lessonRepository, moderationRepository, publisher)
+	rescheduleRepository := repositories.NewRescheduleRepository(db)
+	rescheduleService := services.NewRescheduleService(rescheduleRepository, lessonService, cfg.RescheduleProposalTTL)
+
+	queueRunner := jobs.NewQueueRunner(jobRepository)
+	queueRunner.Handle(services.LessonReminderJob, lessonReminderService.SendReminder)
+	queueRunner.Handle(services.WebhookDeliveryJob, webhookService.Deliver)
+	queueRunner.Handle(services.SendEmailJob, mailService.SendEmail)
+	if discordEnabled {
+		queueRunner.Handle(services.DiscordDMJob, discordService.SendDM)
+		queueRunner.Handle(services.DiscordCreateLessonChannelsJob, discordService.CreateLessonChannels)
+		queueRunner.Handle(services.DiscordDeleteLessonChannelsJob, discordService.DeleteLessonChannels)
+	}
+	if meetingService != nil {
+		queueRunner.Handle(services.MeetingLinkJob, meetingService.CreateMeetingLink)
+	}
+
+	scheduler := jobs.NewScheduler(db)
+	scheduler.Register("run-queued-jobs", cfg.SchedulerInterval, func() error {
+		_, err := queueRunner.RunDue()
+		return err
+	})
+	scheduler.Register("purge-queued-jobs", 24*time.Hour, func() error {
+		_, err := queueRunner.PurgeFinished(30 * 24 * time.Hour)
+		return err
+	})
+	scheduler.Register("purge-webhook-deliveries", 24*time.Hour, func() error {
+		_, err := webhookService.PurgeDeliveries(30 * 24 * time.Hour)
+		return err
+	})
+	scheduler.Register("purge-outbox-emails", 24*time.Hour, func() error {
+		_, err := mailService.PurgeOutbox(30 * 24 * time.Hour)
+		return err
+	})
+	scheduler.Register("expire-unconfirmed-lessons", cfg.SchedulerInterval, func() error {
+		_, err := lessonService.ExpireUnconfirmedLessons(cfg.LessonConfirmationDeadline)
+		return err
+	})
+	scheduler.Register("start-due-lessons", cfg.SchedulerInterval, func() error {
+		_, err := lessonService.StartDueLessons()
+		return err
+	})
+	scheduler.Register("complete-finished-lessons", cfg.SchedulerInterval, func() error {
+		_, err := lessonService.CompleteFinishedLessons()
+		return err
+	})
+	scheduler.Register("expire-slot-holds", cfg.SchedulerInterval, func() error {
+		_, err := lessonService.ExpireSlotHolds()
+		return err
+	})
+	scheduler.Register("expire-reschedule-proposals", cfg.SchedulerInterval, func() error {
+		_, err := rescheduleService.ExpireProposals()
+		return err
+	})
+	scheduler.Register("expire-quiz-attempts", cfg.SchedulerInterval, func() error {
+		_, err := quizService.ExpireAttempts()
+		return err
+	})
+	scheduler.Register("send-notification-emails", cfg.SchedulerInterval, func() error {
+		_, err := notificationService.SendDueEmails()
+		return err
+	})
+	// Keep recurring lesson series materialized ahead of time.
+	scheduler.Register("extend-lesson-series", time.Hour, lessonSeriesService.ExtendHorizons)
+
 	return &Application{
 		Config:        cfg,
 		DB:            db,
@@ -41,5 +213,25 @@ func New(cfg config.Config) (*Application, error) {
 		LessonService: lessonService,
 		CourseService: courseService,
 		TAService:     tutorAvailabilityService,
+		Moderation:    moderationService,
+
+		LessonSeriesService: lessonSeriesService,
+		RescheduleService:   rescheduleService,
+
+		CancellationPolicyService: cancellationPolicyService,
+		BookingService:            bookingService,
+		TutorOfferingService:      tutorOfferingService,
+		BookingRulesService:       bookingRulesService,
+		EnrollmentService:         enrollmentService,
+		LessonMaterialService:     lessonMaterialService,
+		HomeworkService:           homeworkService,
+		QuizService:               quizService,
+		MessageService:            messageService,
+		NotificationService:       notificationService,
+		WebhookService:            webhookService,
+		MailService:               mailService,
+		Events:                    eventBus,
+
+		Scheduler: scheduler,
 	}, nil
 }
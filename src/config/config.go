@@ -4,6 +4,9 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -19,6 +22,60 @@ type Config struct {
 	DiscordRedirectURL  string
 	FrontendUrl         string
 	BackendUrl          string
+
+	// How many days ahead recurring lesson series are materialized into lessons.
+	LessonSeriesHorizonDays int
+
+	// Background scheduler settings.
+	SchedulerInterval time.Duration
+	// Scheduled lessons still unconfirmed this long before their start expire.
+	LessonConfirmationDeadline time.Duration
+	// Pending reschedule proposals expire after this long.
+	RescheduleProposalTTL time.Duration
+	// How long a slot is held for a student during checkout.
+	SlotHoldTTL time.Duration
+
+	// Directory uploaded files, such as lesson attachments, are stored in.
+	StorageDir string
+	// Largest lesson attachment accepted, in bytes.
+	MaxAttachmentSize int64
+
+	// How long before a lesson starts its participants are reminded of it.
+	LessonReminderOffsets []time.Duration
+
+	// Discord bot used for direct messages and lesson channels; disabled without a token.
+	DiscordBotToken string
+	DiscordGuildID  string
+	// Category the lesson channels are created in, optional.
+	DiscordLessonCategoryID string
+	// Overrides the Discord API's URL, e.g. to run against a local fake.
+	DiscordAPIURL string
+	// How long after a lesson ends its Discord channels are deleted.
+	DiscordChannelCleanupDelay time.Duration
+
+	// Online meetings of lessons: the provider ("jitsi", or "none" to disable meeting
+	// links), its server and how long before a lesson starts participants see the link.
+	MeetingProvider   string
+	MeetingBaseURL    string
+	MeetingLinkReveal time.Duration
+
+	// How long a webhook endpoint has to answer a delivery.
+	WebhookTimeout time.Duration
+	// Lets webhooks use plain http and private addresses, for local development only.
+	WebhookAllowInsecure bool
+
+	// Email: the transport ("smtp", "file" writing .eml files to MailDir, or "log"), the
+	// sender, and the language of users who didn't choose one ("pl" or "en").
+	MailTransport       string
+	MailFrom            string
+	MailDir             string
+	MailDefaultLanguage string
+	SMTPHost            string
+	SMTPPort            int
+	SMTPUsername        string
+	SMTPPassword        string
+	// Bearer token the mail provider reports bounces with; bounce reports are disabled without it.
+	MailBounceToken string
 }
 
 func NewConfig() Config {
@@ -40,6 +97,41 @@ func NewConfig() Config {
 		DiscordRedirectURL:  getEnv("DISCORD_REDIRECT_URL", ""),
 		FrontendUrl:         getEnv("FRONTEND_URL", ""),
 		BackendUrl:          getEnv("BACKEND_URL", ""),
+
+		LessonSeriesHorizonDays: getEnvInt("LESSON_SERIES_HORIZON_DAYS", 90),
+
+		SchedulerInterval:          getEnvDuration("SCHEDULER_INTERVAL", time.Minute),
+		LessonConfirmationDeadline: getEnvDuration("LESSON_CONFIRMATION_DEADLINE", 2*time.Hour),
+		RescheduleProposalTTL:      getEnvDuration("RESCHEDULE_PROPOSAL_TTL", 48*time.Hour),
+		SlotHoldTTL:                getEnvDuration("SLOT_HOLD_TTL", 10*time.Minute),
+
+		StorageDir:        getEnv("STORAGE_DIR", "uploads"),
+		MaxAttachmentSize: int64(getEnvInt("MAX_ATTACHMENT_SIZE_MB", 20)) << 20,
+
+		LessonReminderOffsets: getEnvDurations("LESSON_REMINDERS", []time.Duration{24 * time.Hour, time.Hour}),
+
+		DiscordBotToken:            getEnv("DISCORD_BOT_TOKEN", ""),
+		DiscordGuildID:             getEnv("DISCORD_GUILD_ID", ""),
+		DiscordLessonCategoryID:    getEnv("DISCORD_LESSON_CATEGORY_ID", ""),
+		DiscordAPIURL:              getEnv("DISCORD_API_URL", ""),
+		DiscordChannelCleanupDelay: getEnvDuration("DISCORD_CHANNEL_CLEANUP_DELAY", time.Hour),
+
+		MeetingProvider:   getEnv("MEETING_PROVIDER", "jitsi"),
+		MeetingBaseURL:    getEnv("MEETING_BASE_URL", ""),
+		MeetingLinkReveal: getEnvDuration("MEETING_LINK_REVEAL", 15*time.Minute),
+
+		WebhookTimeout:       getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookAllowInsecure: getEnv("WEBHOOK_ALLOW_INSECURE", "false") == "true",
+
+		MailTransport:       getEnv("MAIL_TRANSPORT", "log"),
+		MailFrom:            getEnv("MAIL_FROM", "Vibely <no-reply@vibely.local>"),
+		MailDir:             getEnv("MAIL_DIR", "mail-outbox"),
+		MailDefaultLanguage: getEnv("MAIL_DEFAULT_LANGUAGE", "pl"),
+		SMTPHost:            getEnv("SMTP_HOST", ""),
+		SMTPPort:            getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:        getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:        getEnv("SMTP_PASSWORD", ""),
+		MailBounceToken:     getEnv("MAIL_BOUNCE_TOKEN", ""),
 	}
 }
 func getEnv(key, defaultValue string) string {
@@ -49,3 +141,37 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvDurations parses a comma-separated list of durations, e.g. "24h,1h".
+func getEnvDurations(key string, defaultValue []time.Duration) []time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var durations []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		duration, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || duration <= 0 {
+			log.Printf("Ignoring invalid %s: %q", key, value)
+			return defaultValue
+		}
+		durations = append(durations, duration)
+	}
+	return durations
+}
@@ -34,6 +34,11 @@ func GetDB(user, password, name string) (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetConnMaxLifetime(5 * time.Minute)
 
+	// Lesson.Students is stored in lesson_students along with each student's attendance.
+	if err := db.SetupJoinTable(&models.Lesson{}, "Students", &models.LessonStudent{}); err != nil {
+		return nil, fmt.Errorf("failed to set up lesson_students: %w", err)
+	}
+
 	// Add all models for migration
 	// In database/database.go
 	err = db.AutoMigrate(
@@ -42,11 +47,88 @@ func GetDB(user, password, name string) (*gorm.DB, error) {
 		&models.Course{},
 		&models.TutorWeeklySchedule{},
 		&models.TutorScheduleException{},
+		&models.Report{},
+		&models.UserBlock{},
+		&models.LessonSeries{},
+		&models.LessonStatusHistory{},
+		&models.RescheduleProposal{},
+		&models.RescheduleOption{},
+		&models.RescheduleAcceptance{},
+		&models.TutorCancellationPolicy{},
+		&models.SeatCancellation{},
+		&models.SlotHold{},
+		&models.WaitlistEntry{},
+		&models.TutorOffering{},
+		&models.TutorBookingRules{},
+		&models.LessonNote{},
+		&models.Homework{},
+		&models.LessonAttachment{},
+		&models.HomeworkSubmission{},
+		&models.SubmissionFile{},
+		&models.Quiz{},
+		&models.QuizQuestion{},
+		&models.QuizAttempt{},
+		&models.QuizAnswer{},
+		&models.Conversation{},
+		&models.Message{},
+		&models.ConversationRead{},
+		&models.Notification{},
+		&models.NotificationPreferences{},
+		&models.QueuedJob{},
+		&models.LessonDiscordChannels{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.OutboxEmail{},
+		&models.EmailBounce{},
+		&models.EmailUnsubscribeToken{},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to automigrate: %w", err)
 	}
 
+	if err := applyConstraints(db); err != nil {
+		return nil, fmt.Errorf("failed to apply constraints: %w", err)
+	}
+
 	log.Println("Database migration completed successfully.")
 	return db, nil
 }
+
+// applyConstraints creates the database-level guarantees AutoMigrate cannot express.
+// Every statement is idempotent, so it is safe to run on each start.
+func applyConstraints(db *gorm.DB) error {
+	statements := []string{
+		// Needed for uuid equality inside a GiST exclusion constraint.
+		`CREATE EXTENSION IF NOT EXISTS btree_gist`,
+		// A tutor can never have two lessons occupying overlapping time ranges,
+		// even when two bookings race past the application checks.
+		`DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'lessons_tutor_no_overlap') THEN
+				ALTER TABLE lessons ADD CONSTRAINT lessons_tutor_no_overlap
+					EXCLUDE USING gist (tutor_id WITH =, tstzrange(start_time, end_time, '[)') WITH &&)
+					WHERE (status IN ('scheduled', 'confirmed', 'in_progress', 'done'));
+			END IF;
+		END
+		$$`,
+		// A lesson has at most one reschedule proposal waiting for an answer.
+		`CREATE UNIQUE INDEX IF NOT EXISTS reschedule_proposals_one_pending
+			ON reschedule_proposals (lesson_id) WHERE status = 'pending'`,
+		// A student waits at most once for the same lesson or course.
+		`CREATE UNIQUE INDEX IF NOT EXISTS waitlist_entries_lesson_student
+			ON waitlist_entries (lesson_id, student_id) WHERE lesson_id IS NOT NULL`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS waitlist_entries_course_student
+			ON waitlist_entries (course_id, student_id) WHERE course_id IS NOT NULL`,
+		// Each pair of users has one direct conversation, each lesson one thread.
+		`CREATE UNIQUE INDEX IF NOT EXISTS conversations_direct_pair
+			ON conversations (user_a_id, user_b_id) WHERE kind = 'direct'`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS conversations_lesson
+			ON conversations (lesson_id) WHERE kind = 'lesson'`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
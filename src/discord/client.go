@@ -0,0 +1,188 @@
+// Package discord talks to the Discord API as the platform's bot: it sends direct
+// messages and manages the private channels of lessons in our server.
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the base URL of the Discord REST API. Tests point the client at a
+// local fake instead.
+const DefaultAPIURL = "https://discord.com/api/v10"
+
+// Channel types
+const (
+	channelTypeText  = 0
+	channelTypeVoice = 2
+)
+
+// Permission bits granted to lesson participants.
+const (
+	permissionViewChannel  = 1 << 10
+	permissionSendMessages = 1 << 11
+	permissionConnect      = 1 << 20
+	permissionSpeak        = 1 << 21
+)
+
+// Client is the part of the Discord API the platform uses.
+type Client interface {
+	// SendDM sends a direct message to the user with the given Discord ID.
+	SendDM(userID, content string) error
+	// CreatePrivateChannels creates a text and a voice channel in the server that only
+	// the given users can see, returning their IDs.
+	CreatePrivateChannels(name string, userIDs []string) (textChannelID, voiceChannelID string, err error)
+	// DeleteChannel deletes a channel. Deleting a channel that no longer exists is not an error.
+	DeleteChannel(channelID string) error
+}
+
+// Error is a non-successful response of the Discord API.
+type Error struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("discord API returned %d: %s", e.StatusCode, e.Body)
+}
+
+type client struct {
+	baseURL    string
+	token      string
+	guildID    string
+	categoryID string
+	http       *http.Client
+}
+
+// NewClient creates a Client acting as the bot with the given token in the server
+// guildID. Lesson channels are created under the category categoryID, if set.
+func NewClient(baseURL, token, guildID, categoryID string) Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		guildID:    guildID,
+		categoryID: categoryID,
+		http:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type channel struct {
+	ID string `json:"id"`
+}
+
+func (c *client) SendDM(userID, content string) error {
+	var dm channel
+	if err := c.do(http.MethodPost, "/users/@me/channels", map[string]string{"recipient_id": userID}, &dm); err != nil {
+		return err
+	}
+	return c.do(http.MethodPost, "/channels/"+dm.ID+"/messages", map[string]string{"content": content}, nil)
+}
+
+type permissionOverwrite struct {
+	ID    string `json:"id"`
+	Type  int    `json:"type"` // 0 for a role, 1 for a member
+	Allow string `json:"allow"`
+	Deny  string `json:"deny"`
+}
+
+type createChannelRequest struct {
+	Name                 string                `json:"name"`
+	Type                 int                   `json:"type"`
+	ParentID             string                `json:"parent_id,omitempty"`
+	PermissionOverwrites []permissionOverwrite `json:"permission_overwrites"`
+}
+
+func (c *client) CreatePrivateChannels(name string, userIDs []string) (string, string, error) {
+	// The @everyone role shares the server's ID; hiding the channels from it leaves
+	// them visible to the listed users only.
+	overwrites := []permissionOverwrite{{ID: c.guildID, Type: 0, Allow: "0", Deny: permissions(permissionViewChannel)}}
+	for _, userID := range userIDs {
+		overwrites = append(overwrites, permissionOverwrite{
+			ID:    userID,
+			Type:  1,
+			Allow: permissions(permissionViewChannel, permissionSendMessages, permissionConnect, permissionSpeak),
+			Deny:  "0",
+		})
+	}
+
+	var text, voice channel
+	path := "/guilds/" + c.guildID + "/channels"
+	err := c.do(http.MethodPost, path, createChannelRequest{
+		Name: name, Type: channelTypeText, ParentID: c.categoryID, PermissionOverwrites: overwrites,
+	}, &text)
+	if err != nil {
+		return "", "", err
+	}
+	err = c.do(http.MethodPost, path, createChannelRequest{
+		Name: name, Type: channelTypeVoice, ParentID: c.categoryID, PermissionOverwrites: overwrites,
+	}, &voice)
+	if err != nil {
+		// Don't leave half of the pair behind.
+		_ = c.DeleteChannel(text.ID)
+		return "", "", err
+	}
+	return text.ID, voice.ID, nil
+}
+
+func (c *client) DeleteChannel(channelID string) error {
+	err := c.do(http.MethodDelete, "/channels/"+channelID, nil, nil)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// do sends a request with a JSON body, if any, and decodes the JSON response into out,
+// if not nil.
+func (c *client) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bot "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &Error{StatusCode: resp.StatusCode, Body: string(message)}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// permissions encodes permission bits the way the API expects them, as a decimal string.
+func permissions(bits ...int64) string {
+	var set int64
+	for _, bit := range bits {
+		set |= bit
+	}
+	return fmt.Sprint(set)
+}
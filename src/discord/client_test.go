@@ -0,0 +1,187 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// fakeAPI is a local stand-in for the Discord API recording the requests it gets.
+type fakeAPI struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+	bodies   []map[string]interface{}
+}
+
+func newFakeAPI(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, body map[string]interface{})) *fakeAPI {
+	f := &fakeAPI{t: t}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bot token" {
+			t.Errorf("Authorization = %q, want %q", got, "Bot token")
+		}
+		var body map[string]interface{}
+		if r.Body != nil && r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("%s %s: invalid JSON body: %v", r.Method, r.URL.Path, err)
+			}
+		}
+		f.mu.Lock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+		f.bodies = append(f.bodies, body)
+		f.mu.Unlock()
+		handler(w, r, body)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeAPI) client() Client {
+	return NewClient(f.server.URL, "token", "guild", "category")
+}
+
+func (f *fakeAPI) assertRequests(want ...string) {
+	f.t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.requests) != len(want) {
+		f.t.Fatalf("requests = %q, want %q", f.requests, want)
+	}
+	for i := range want {
+		if f.requests[i] != want[i] {
+			f.t.Fatalf("requests = %q, want %q", f.requests, want)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestSendDM(t *testing.T) {
+	api := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		switch r.URL.Path {
+		case "/users/@me/channels":
+			if body["recipient_id"] != "user-1" {
+				t.Errorf("recipient_id = %v, want user-1", body["recipient_id"])
+			}
+			writeJSON(w, http.StatusOK, map[string]string{"id": "dm-1"})
+		case "/channels/dm-1/messages":
+			if body["content"] != "hello" {
+				t.Errorf("content = %v, want hello", body["content"])
+			}
+			writeJSON(w, http.StatusOK, map[string]string{"id": "message-1"})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	if err := api.client().SendDM("user-1", "hello"); err != nil {
+		t.Fatalf("SendDM: %v", err)
+	}
+	api.assertRequests("POST /users/@me/channels", "POST /channels/dm-1/messages")
+}
+
+func TestSendDMReturnsAPIError(t *testing.T) {
+	api := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request, _ map[string]interface{}) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "Cannot send messages to this user"})
+	})
+
+	err := api.client().SendDM("user-1", "hello")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("SendDM error = %v, want a 403 *Error", err)
+	}
+	api.assertRequests("POST /users/@me/channels")
+}
+
+func TestCreatePrivateChannels(t *testing.T) {
+	api := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		if r.URL.Path != "/guilds/guild/channels" {
+			http.NotFound(w, r)
+			return
+		}
+		if body["parent_id"] != "category" {
+			t.Errorf("parent_id = %v, want category", body["parent_id"])
+		}
+		overwrites, _ := body["permission_overwrites"].([]interface{})
+		if len(overwrites) != 3 {
+			t.Errorf("got %d permission overwrites, want @everyone and 2 members", len(overwrites))
+		}
+		switch body["type"] {
+		case float64(channelTypeText):
+			writeJSON(w, http.StatusCreated, map[string]string{"id": "text-1"})
+		case float64(channelTypeVoice):
+			writeJSON(w, http.StatusCreated, map[string]string{"id": "voice-1"})
+		default:
+			t.Errorf("unexpected channel type %v", body["type"])
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	text, voice, err := api.client().CreatePrivateChannels("lesson", []string{"tutor", "student"})
+	if err != nil {
+		t.Fatalf("CreatePrivateChannels: %v", err)
+	}
+	if text != "text-1" || voice != "voice-1" {
+		t.Fatalf("channels = %q, %q, want text-1, voice-1", text, voice)
+	}
+	api.assertRequests("POST /guilds/guild/channels", "POST /guilds/guild/channels")
+}
+
+func TestCreatePrivateChannelsDeletesTextChannelWhenVoiceFails(t *testing.T) {
+	api := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		switch {
+		case r.Method == http.MethodPost && body["type"] == float64(channelTypeText):
+			writeJSON(w, http.StatusCreated, map[string]string{"id": "text-1"})
+		case r.Method == http.MethodPost:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "boom"})
+		case r.Method == http.MethodDelete && r.URL.Path == "/channels/text-1":
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	text, voice, err := api.client().CreatePrivateChannels("lesson", []string{"tutor", "student"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("CreatePrivateChannels error = %v, want a 500 *Error", err)
+	}
+	if text != "" || voice != "" {
+		t.Fatalf("channels = %q, %q, want none", text, voice)
+	}
+	api.assertRequests("POST /guilds/guild/channels", "POST /guilds/guild/channels", "DELETE /channels/text-1")
+}
+
+func TestDeleteChannel(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "deleted", status: http.StatusOK},
+		{name: "already gone", status: http.StatusNotFound},
+		{name: "forbidden", status: http.StatusForbidden, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request, _ map[string]interface{}) {
+				writeJSON(w, tt.status, map[string]string{"id": "channel-1"})
+			})
+
+			err := api.client().DeleteChannel("channel-1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeleteChannel error = %v, want error: %v", err, tt.wantErr)
+			}
+			api.assertRequests("DELETE /channels/channel-1")
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many events may wait for a slow subscriber before it is
+// disconnected. A disconnected client resumes from its last event ID on reconnect.
+const subscriberBuffer = 64
+
+// Bus is an in-process Publisher that subscribers can follow. It keeps the most recent
+// events so that reconnecting clients can catch up on what they missed.
+type Bus interface {
+	Publisher
+	// Subscribe follows the given topics. With a lastEventID above zero, the events on
+	// those topics published after it are replayed first.
+	Subscribe(topics []string, lastEventID int64) *Subscription
+}
+
+// Subscription is a subscriber's stream of events.
+type Subscription struct {
+	// Replay holds the missed events to send before those from Events.
+	Replay []Event
+	// Resync is set when some missed events are no longer kept, so the client must
+	// reload its state instead of relying on Replay alone.
+	Resync bool
+	// Events is closed when the subscription ends, either by Close or because the
+	// subscriber fell too far behind.
+	Events <-chan Event
+
+	bus    *memoryBus
+	events chan Event
+	topics map[string]bool
+	closed bool
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s)
+}
+
+type memoryBus struct {
+	mu          sync.Mutex
+	lastID      int64
+	history     []Event
+	historySize int
+	subscribers map[*Subscription]bool
+}
+
+// NewBus creates a Bus keeping the last historySize events for replay.
+func NewBus(historySize int) Bus {
+	return &memoryBus{
+		// Start from the clock so that IDs keep increasing across restarts, and a client
+		// resuming with an ID from before a restart is told to resync.
+		lastID:      time.Now().UnixMilli(),
+		historySize: historySize,
+		subscribers: make(map[*Subscription]bool),
+	}
+}
+
+func (b *memoryBus) Publish(eventType string, data interface{}, topics ...string) {
+	if len(topics) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Data: data, CreatedAt: time.Now(), topics: topics}
+	b.history = append(b.history, event)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for sub := range b.subscribers {
+		if !sub.follows(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+func (b *memoryBus) Subscribe(topics []string, lastEventID int64) *Subscription {
+	events := make(chan Event, subscriberBuffer)
+	sub := &Subscription{
+		Events: events,
+		bus:    b,
+		events: events,
+		topics: make(map[string]bool, len(topics)),
+	}
+	for _, topic := range topics {
+		sub.topics[topic] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if lastEventID > 0 {
+		// Event IDs have no gaps, so anything older than the oldest kept event is lost.
+		oldest := b.lastID + 1
+		if len(b.history) > 0 {
+			oldest = b.history[0].ID
+		}
+		sub.Resync = lastEventID+1 < oldest || lastEventID > b.lastID
+		for _, event := range b.history {
+			if event.ID > lastEventID && sub.follows(event) {
+				sub.Replay = append(sub.Replay, event)
+			}
+		}
+	}
+	b.subscribers[sub] = true
+	return sub
+}
+
+func (b *memoryBus) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drop(sub)
+}
+
+// drop removes a subscriber and closes its channel. The caller holds b.mu.
+func (b *memoryBus) drop(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
+
+// follows reports whether the event is on one of the subscription's topics.
+func (s *Subscription) follows(event Event) bool {
+	for _, topic := range event.topics {
+		if s.topics[topic] {
+			return true
+		}
+	}
+	return false
+}
+
+type fanout []Publisher
+
+// Fanout creates a Publisher that publishes each event to all of publishers, in order.
+func Fanout(publishers ...Publisher) Publisher {
+	return fanout(publishers)
+}
+
+func (f fanout) Publish(eventType string, data interface{}, topics ...string) {
+	for _, publisher := range f {
+		publisher.Publish(eventType, data, topics...)
+	}
+}
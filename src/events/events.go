@@ -0,0 +1,115 @@
+// Package events carries domain events, such as a lesson being confirmed, from the
+// services to users connected over the real-time channel.
+package events
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types
+const (
+	LessonCreated       = "lesson.created"
+	LessonUpdated       = "lesson.updated"
+	LessonReminder      = "lesson.reminder"
+	CourseUpdated       = "course.updated"
+	MessageCreated      = "message.created"
+	AvailabilityChanged = "availability.changed"
+	EnrollmentCreated   = "enrollment.created"
+	NotificationCreated = "notification.created"
+)
+
+// Lesson actions that are not a change of status. For status changes, the action of a
+// LessonChange is the lesson's new status.
+const (
+	LessonActionPostponed          = "postponed"
+	LessonActionRescheduled        = "rescheduled"
+	LessonActionDisputed           = "disputed"
+	LessonActionAttendanceRecorded = "attendance_recorded"
+)
+
+// Event is a domain event delivered to subscribers of any of its topics. IDs increase
+// with every event published, so a reconnecting client can resume after the last ID it saw.
+type Event struct {
+	ID        int64       `json:"id"`
+	Type      string      `json:"type"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+
+	topics []string
+}
+
+// Publisher publishes domain events.
+type Publisher interface {
+	// Publish delivers an event of the given type to the subscribers of any of topics.
+	// It never blocks on slow subscribers.
+	Publish(eventType string, data interface{}, topics ...string)
+}
+
+// UserTopic is the topic of events about a user's own lessons, courses and messages.
+func UserTopic(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+// UserIDFromTopic returns the user of a UserTopic.
+func UserIDFromTopic(topic string) (uuid.UUID, bool) {
+	id, ok := strings.CutPrefix(topic, "user:")
+	if !ok {
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(id)
+	return userID, err == nil
+}
+
+// UserTopics returns the UserTopic of each user.
+func UserTopics(userIDs ...uuid.UUID) []string {
+	topics := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		topics = append(topics, UserTopic(id))
+	}
+	return topics
+}
+
+// AvailabilityTopic is the topic of changes to a tutor's free slots, which anyone
+// looking at the tutor's calendar may follow.
+func AvailabilityTopic(tutorID uuid.UUID) string {
+	return "availability:" + tutorID.String()
+}
+
+// LessonChange is the payload of lesson events.
+type LessonChange struct {
+	LessonID  uuid.UUID  `json:"lesson_id"`
+	TutorID   uuid.UUID  `json:"tutor_id"`
+	CourseID  *uuid.UUID `json:"course_id,omitempty"`
+	Title     string     `json:"title"`
+	Status    string     `json:"status"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   time.Time  `json:"end_time"`
+	// Action tells what happened to the lesson.
+	Action string `json:"action"`
+	// ActorID is the user who made the change, nil for changes made by the system.
+	ActorID *uuid.UUID `json:"actor_id,omitempty"`
+}
+
+// CourseChange is the payload of course events.
+type CourseChange struct {
+	CourseID uuid.UUID `json:"course_id"`
+	TutorID  uuid.UUID `json:"tutor_id"`
+}
+
+// AvailabilityChange is the payload of availability events.
+type AvailabilityChange struct {
+	TutorID uuid.UUID `json:"tutor_id"`
+}
+
+// EnrollmentChange is the payload of enrollment events.
+type EnrollmentChange struct {
+	LessonID   *uuid.UUID `json:"lesson_id,omitempty"`
+	CourseID   *uuid.UUID `json:"course_id,omitempty"`
+	Title      string     `json:"title"`
+	TutorID    uuid.UUID  `json:"tutor_id"`
+	StudentID  uuid.UUID  `json:"student_id"`
+	Waitlisted bool       `json:"waitlisted"`
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/services"
+)
+
+// BookingHandler handles student self-booking of lessons.
+type BookingHandler struct {
+	App *app.Application
+}
+
+// NewBookingHandler creates a new BookingHandler.
+func NewBookingHandler(app *app.Application) *BookingHandler {
+	return &BookingHandler{App: app}
+}
+
+type createBookingRequest struct {
+	StartTime       string `json:"start_time" binding:"required"` // RFC3339, within a slot from /availability
+	OfferingID      string `json:"offering_id"`                   // required if the tutor publishes offerings
+	DurationMinutes int    `json:"duration_minutes"`              // set by the offering, if any
+	Subject         string `json:"subject"`                       // optional if the tutor teaches a single subject
+	Level           string `json:"level"`                         // optional if the tutor teaches a single level
+	Title           string `json:"title"`
+	Description     string `json:"description"`
+}
+
+// bind parses a booking request body into a services.BookingRequest.
+func (req *createBookingRequest) bind(c *gin.Context) (services.BookingRequest, bool) {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_time is required"})
+		return services.BookingRequest{}, false
+	}
+	start, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_time"})
+		return services.BookingRequest{}, false
+	}
+	var offeringID *uuid.UUID
+	if req.OfferingID != "" {
+		id, err := uuid.Parse(req.OfferingID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offering_id"})
+			return services.BookingRequest{}, false
+		}
+		offeringID = &id
+	}
+	return services.BookingRequest{
+		StartTime:       start,
+		OfferingID:      offeringID,
+		DurationMinutes: req.DurationMinutes,
+		Subject:         req.Subject,
+		Level:           req.Level,
+		Title:           req.Title,
+		Description:     req.Description,
+	}, true
+}
+
+// CreateBooking books a lesson with the tutor for the current user.
+func (h *BookingHandler) CreateBooking(c *gin.Context) {
+	tutorID, err := uuid.Parse(c.Param("tutorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req createBookingRequest
+	booking, ok := req.bind(c)
+	if !ok {
+		return
+	}
+
+	lesson, err := h.App.BookingService.BookLesson(tutorID, currentUser.ID, booking)
+	if err != nil {
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, lesson.ToDTO())
+}
+
+// CreateHold holds a slot of the tutor for the current user during checkout. The
+// response contains the hold ID and when it expires.
+func (h *BookingHandler) CreateHold(c *gin.Context) {
+	tutorID, err := uuid.Parse(c.Param("tutorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req createBookingRequest
+	booking, ok := req.bind(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.App.BookingService.HoldSlot(tutorID, currentUser.ID, booking)
+	if err != nil {
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// ConfirmHold books the lesson held by the current user.
+func (h *BookingHandler) ConfirmHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("holdID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hold ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	lesson, err := h.App.BookingService.ConfirmHold(holdID, currentUser.ID)
+	if err != nil {
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, lesson.ToDTO())
+}
+
+// ReleaseHold gives up the current user's hold.
+func (h *BookingHandler) ReleaseHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("holdID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hold ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.BookingService.ReleaseHold(holdID, currentUser.ID); err != nil {
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hold released"})
+}
@@ -31,6 +31,7 @@ type createCourseRequest struct {
 	Description string   `json:"description"`
 	Subject     string   `json:"subject"`
 	Level       string   `json:"level"`
+	MaxStudents int      `json:"max_students"` // 0 for no limit
 }
 
 // CreateCourse handles the creation of a new course.
@@ -76,6 +77,7 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 		Subject:     req.Subject,
 		Level:       req.Level,
 		Students:    students,
+		MaxStudents: req.MaxStudents,
 		CreatedAt:   time.Now(),
 	}
 	createdCourse, err := h.App.CourseService.CreateCourse(course)
@@ -101,7 +103,18 @@ func (h *CourseHandler) GetCourse(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, course.ToDTO())
+	// The tutor also sees how students did on the course's quizzes.
+	dto := course.ToDTO()
+	if currentUser, err := getCurrentUser(c); err == nil && currentUser.ID == course.TutorID {
+		results, err := h.App.QuizService.GetCourseQuizResults(courseID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		dto.QuizResults = results
+	}
+
+	c.JSON(http.StatusOK, dto)
 }
 func (h *CourseHandler) GetCourses(c *gin.Context) {
 	subject := c.Query("subject")
@@ -154,32 +167,43 @@ func (h *CourseHandler) GetCoursesForUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dtos)
 }
-func (h *CourseHandler) EnrollInCourse(c *gin.Context) {
-	courseIDStr := c.Param("courseID")
-	courseID, err := uuid.Parse(courseIDStr)
+
+// GetCourseProgress returns the course's progress with each student's attendance. The
+// tutor and admins see every student; a student only sees their own progress.
+func (h *CourseHandler) GetCourseProgress(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("courseID"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course ID"})
 		return
 	}
 
-	// Retrieve the authenticated user from context (set by middleware).
-	userVal, exists := c.Get("user")
-	if !exists {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	currentUser, ok := userVal.(models.User)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse user"})
+	course, err := h.App.CourseService.GetCourseByID(courseID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
 		return
 	}
 
-	updatedCourse, err := h.App.CourseService.EnrollStudent(courseID, currentUser)
+	progress, err := h.App.CourseService.GetCourseProgress(courseID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedCourse.ToDTO())
+	if currentUser.ID != course.TutorID && currentUser.Role != models.UserRoleAdmin {
+		own := []models.StudentCourseProgress{}
+		for _, student := range progress.Students {
+			if student.Student.ID == currentUser.ID {
+				own = append(own, student)
+			}
+		}
+		progress.Students = own
+	}
+
+	c.JSON(http.StatusOK, progress)
 }
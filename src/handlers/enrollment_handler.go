@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/models"
+	"vibely-backend/src/services"
+)
+
+// EnrollmentHandler handles seats and waitlists of group lessons and courses.
+type EnrollmentHandler struct {
+	App *app.Application
+}
+
+// NewEnrollmentHandler creates a new EnrollmentHandler.
+func NewEnrollmentHandler(app *app.Application) *EnrollmentHandler {
+	return &EnrollmentHandler{App: app}
+}
+
+// JoinLesson enrolls the current user in a group lesson, or puts them on its waitlist
+// (202 with their position) when the lesson is full.
+func (h *EnrollmentHandler) JoinLesson(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := h.App.EnrollmentService.JoinLesson(lessonID, currentUser)
+	if err != nil {
+		c.JSON(enrollmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if !result.Enrolled {
+		c.JSON(http.StatusAccepted, result.Entry.ToDTO(result.Position))
+		return
+	}
+
+	lesson, err := h.App.LessonService.GetLessonWithParticipants(lessonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lesson.ToDTO())
+}
+
+// LeaveLesson frees the current user's seat in a group lesson or removes them from its waitlist.
+func (h *EnrollmentHandler) LeaveLesson(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.EnrollmentService.LeaveLesson(lessonID, currentUser.ID); err != nil {
+		c.JSON(enrollmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left the lesson"})
+}
+
+// GetLessonWaitlist lists the lesson's waitlist. The tutor sees everyone waiting;
+// other users only see their own position.
+func (h *EnrollmentHandler) GetLessonWaitlist(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	lesson, err := h.App.LessonService.GetLessonByID(lessonID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "lesson not found"})
+		return
+	}
+
+	waitlist, err := h.App.EnrollmentService.GetLessonWaitlist(lessonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, waitlistDTOs(waitlist, currentUser, lesson.TutorID))
+}
+
+// JoinCourse enrolls the current user in a course and its upcoming lessons, or puts them
+// on its waitlist (202 with their position) when the course is full.
+func (h *EnrollmentHandler) JoinCourse(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("courseID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := h.App.EnrollmentService.JoinCourse(courseID, currentUser)
+	if err != nil {
+		c.JSON(enrollmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if !result.Enrolled {
+		c.JSON(http.StatusAccepted, result.Entry.ToDTO(result.Position))
+		return
+	}
+
+	course, err := h.App.CourseService.GetCourseWithParticipants(courseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, course.ToDTO())
+}
+
+// LeaveCourse unenrolls the current user from a course or removes them from its waitlist.
+func (h *EnrollmentHandler) LeaveCourse(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("courseID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.EnrollmentService.LeaveCourse(courseID, currentUser.ID); err != nil {
+		c.JSON(enrollmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left the course"})
+}
+
+// GetCourseWaitlist lists the course's waitlist. The tutor sees everyone waiting;
+// other users only see their own position.
+func (h *EnrollmentHandler) GetCourseWaitlist(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("courseID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	course, err := h.App.CourseService.GetCourseByID(courseID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+		return
+	}
+
+	waitlist, err := h.App.EnrollmentService.GetCourseWaitlist(courseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, waitlistDTOs(waitlist, currentUser, course.TutorID))
+}
+
+// waitlistDTOs converts a waitlist for the viewer: the tutor and admins get every entry,
+// anybody else only their own.
+func waitlistDTOs(waitlist []models.WaitlistEntry, viewer models.User, tutorID uuid.UUID) []models.WaitlistEntryDTO {
+	seesAll := viewer.ID == tutorID || viewer.Role == models.UserRoleAdmin
+	dtos := []models.WaitlistEntryDTO{}
+	for i, entry := range waitlist {
+		if seesAll || entry.StudentID == viewer.ID {
+			dtos = append(dtos, entry.ToDTO(i+1))
+		}
+	}
+	return dtos
+}
+
+// enrollmentErrorStatus maps enrollment errors to HTTP status codes.
+func enrollmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUsersBlocked):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrAlreadyEnrolled), errors.Is(err, services.ErrAlreadyWaitlisted),
+		errors.Is(err, services.ErrEnrollmentClosed), errors.Is(err, services.ErrLessonConflict):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrNotEnrolled):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
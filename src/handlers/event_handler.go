@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/events"
+)
+
+const (
+	// heartbeatInterval keeps idle streams from being closed by proxies.
+	heartbeatInterval = 25 * time.Second
+	// maxFollowedTutors caps how many tutors' availability one stream may follow.
+	maxFollowedTutors = 20
+)
+
+// EventHandler streams domain events to connected users as server-sent events.
+type EventHandler struct {
+	App *app.Application
+}
+
+// NewEventHandler creates a new EventHandler.
+func NewEventHandler(app *app.Application) *EventHandler {
+	return &EventHandler{App: app}
+}
+
+// StreamEvents streams the current user's lesson, course and message events, plus
+// availability changes of the tutors listed in ?tutors=<id>,<id>. A client reconnecting
+// with the Last-Event-ID header (or ?last_event_id=) first receives the events it missed;
+// when they can't all be replayed it gets a "resync" event and should reload its data.
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	topics := []string{events.UserTopic(currentUser.ID)}
+	if tutors := c.Query("tutors"); tutors != "" {
+		ids := strings.Split(tutors, ",")
+		if len(ids) > maxFollowedTutors {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d tutors can be followed", maxFollowedTutors)})
+			return
+		}
+		for _, id := range ids {
+			tutorID, err := uuid.Parse(strings.TrimSpace(id))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+				return
+			}
+			topics = append(topics, events.AvailabilityTopic(tutorID))
+		}
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var lastID int64
+	if lastEventID != "" {
+		lastID, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || lastID < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last event ID"})
+			return
+		}
+	}
+
+	sub := h.App.Events.Subscribe(topics, lastID)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if sub.Resync {
+		fmt.Fprint(c.Writer, "event: resync\ndata: {}\n\n")
+	}
+	for _, event := range sub.Replay {
+		if err := writeEvent(c.Writer, event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-sub.Events:
+			// A closed channel means the client fell behind; it resumes on reconnect.
+			return ok && writeEvent(w, event) == nil
+		case <-heartbeat.C:
+			_, err := fmt.Fprint(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+// writeEvent writes one event in the text/event-stream format.
+func writeEvent(w io.Writer, event events.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/models"
+	"vibely-backend/src/services"
+)
+
+// HomeworkHandler handles homework submissions and grading.
+type HomeworkHandler struct {
+	App *app.Application
+}
+
+// NewHomeworkHandler creates a new HomeworkHandler.
+func NewHomeworkHandler(app *app.Application) *HomeworkHandler {
+	return &HomeworkHandler{App: app}
+}
+
+type submitHomeworkRequest struct {
+	Text string `json:"text"`
+}
+
+// SubmitHomework hands in the current user's answer to a homework, replacing any earlier
+// attempt. It takes either a JSON body {"text": "..."} or a multipart form with a "text"
+// field and any number of "files".
+func (h *HomeworkHandler) SubmitHomework(c *gin.Context) {
+	homeworkID, err := uuid.Parse(c.Param("homeworkID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid homework ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var text string
+	var uploads []services.Upload
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		form, err := c.MultipartForm()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid multipart form"})
+			return
+		}
+		text = c.PostForm("text")
+		for _, header := range form.File["files"] {
+			if header.Size > h.App.Config.MaxAttachmentSize {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrAttachmentTooLarge.Error()})
+				return
+			}
+			file, err := header.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+				return
+			}
+			defer file.Close()
+			uploads = append(uploads, uploadFromHeader(header, file))
+		}
+	} else {
+		var req submitHomeworkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+		text = req.Text
+	}
+
+	submission, err := h.App.HomeworkService.Submit(homeworkID, currentUser.ID, text, uploads)
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, submission.ToDTO())
+}
+
+// GetSubmissions lists the submissions for a homework; students only see their own.
+func (h *HomeworkHandler) GetSubmissions(c *gin.Context) {
+	homeworkID, err := uuid.Parse(c.Param("homeworkID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid homework ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	submissions, err := h.App.HomeworkService.GetSubmissions(homeworkID, currentUser.ID)
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]models.HomeworkSubmissionDTO, 0, len(submissions))
+	for _, submission := range submissions {
+		dtos = append(dtos, submission.ToDTO())
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+// GetSubmission returns a submission to its student or the lesson's tutor.
+func (h *HomeworkHandler) GetSubmission(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("submissionID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	submission, err := h.App.HomeworkService.GetSubmission(submissionID, currentUser.ID)
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, submission.ToDTO())
+}
+
+type gradeSubmissionRequest struct {
+	Score    *float64 `json:"score" binding:"required"`
+	Feedback string   `json:"feedback"`
+	// Attempt is the attempt the tutor reviewed; grading fails if the student has since
+	// handed in another one.
+	Attempt int `json:"attempt"`
+}
+
+// GradeSubmission lets the tutor score and comment on a submission.
+func (h *HomeworkHandler) GradeSubmission(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("submissionID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req gradeSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "score is required"})
+		return
+	}
+
+	submission, err := h.App.HomeworkService.Grade(submissionID, currentUser.ID, req.Attempt, *req.Score, req.Feedback)
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, submission.ToDTO())
+}
+
+// DownloadSubmissionFile sends a submitted file to its student or the lesson's tutor.
+func (h *HomeworkHandler) DownloadSubmissionFile(c *gin.Context) {
+	fileID, err := uuid.Parse(c.Param("fileID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	file, contents, err := h.App.HomeworkService.OpenSubmissionFile(fileID, currentUser.ID)
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer contents.Close()
+
+	c.DataFromReader(http.StatusOK, file.Size, file.ContentType, contents, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": file.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// GetHomeworkOverview returns a student's outstanding, submitted and graded homework.
+// It is visible to the student and admins, and to the student's tutors for their own lessons.
+func (h *HomeworkHandler) GetHomeworkOverview(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	overview, err := h.App.HomeworkService.GetStudentOverview(userID, currentUser)
+	if errors.Is(err, services.ErrHomeworkOverviewForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, overview)
+}
+
+// uploadFromHeader describes an uploaded multipart file for the services.
+func uploadFromHeader(header *multipart.FileHeader, file multipart.File) services.Upload {
+	return services.Upload{
+		FileName:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Body:        file,
+	}
+}
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
 	"time"
 	"vibely-backend/src/app"
 	"vibely-backend/src/models"
+	"vibely-backend/src/services"
 )
 
 // LessonHandler will hold references to the application context (App),
@@ -31,6 +33,7 @@ type createLessonRequest struct {
 	Level       string   `json:"level"`   // Added level field
 	StartTime   string   `json:"start_time"`
 	EndTime     string   `json:"end_time"`
+	MaxStudents int      `json:"max_students"` // 0 for no limit
 }
 
 // CreateLesson schedules a new lesson in "scheduled" state.
@@ -87,13 +90,20 @@ func (h *LessonHandler) CreateLesson(c *gin.Context) {
 		Level:       req.Level,   // Assign level field
 		StartTime:   start,
 		EndTime:     end,
+		MaxStudents: req.MaxStudents,
 		// We'll set Status in the service (to "scheduled").
 	}
 
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	// Call service to schedule the lesson
-	scheduledLesson, err := h.App.LessonService.ScheduleLesson(lesson)
+	scheduledLesson, err := h.App.LessonService.ScheduleLesson(lesson, currentUser.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(lessonErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -115,9 +125,47 @@ func (h *LessonHandler) GetLesson(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, lesson.ToDTO())
+	// Notes, homework, attachments and the meeting link are only listed for the lesson's participants.
+	dto := lesson.ToDTO()
+	if currentUser, err := getCurrentUser(c); err == nil && isLessonParticipant(lesson, currentUser.ID) {
+		materials, err := h.App.LessonMaterialService.GetMaterials(lessonID, currentUser.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		dto.Materials = &materials
+		dto.MeetingURL = h.meetingURL(lesson, time.Now())
+	}
+
+	c.JSON(http.StatusOK, dto)
 }
 func (h *LessonHandler) ConfirmLesson(c *gin.Context) {
+	h.transitionLesson(c, h.App.LessonService.ConfirmLesson)
+}
+func (h *LessonHandler) StartLesson(c *gin.Context) {
+	h.transitionLesson(c, h.App.LessonService.StartLesson)
+}
+func (h *LessonHandler) CompleteLesson(c *gin.Context) {
+	h.transitionLesson(c, h.App.LessonService.CompleteLesson)
+}
+func (h *LessonHandler) FailLesson(c *gin.Context) {
+	h.transitionLessonWithReason(c, h.App.LessonService.FailLesson)
+}
+
+// transitionLesson runs a status transition of the lesson in the URL on behalf of the current user.
+func (h *LessonHandler) transitionLesson(c *gin.Context, transition func(lessonID, actorID uuid.UUID) (models.Lesson, error)) {
+	h.transitionLessonWithReason(c, func(lessonID, actorID uuid.UUID, _ string) (models.Lesson, error) {
+		return transition(lessonID, actorID)
+	})
+}
+
+type lessonTransitionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// transitionLessonWithReason is transitionLesson for transitions that accept an optional
+// {"reason": "..."} body.
+func (h *LessonHandler) transitionLessonWithReason(c *gin.Context, transition func(lessonID, actorID uuid.UUID, reason string) (models.Lesson, error)) {
 	lessonIDStr := c.Param("lessonID")
 	lessonID, err := uuid.Parse(lessonIDStr)
 	if err != nil {
@@ -125,111 +173,145 @@ func (h *LessonHandler) ConfirmLesson(c *gin.Context) {
 		return
 	}
 
-	lesson, err := h.App.LessonService.ConfirmLesson(lessonID)
+	currentUser, err := getCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// The body is optional, so a missing or empty one is not an error.
+	var req lessonTransitionRequest
+	_ = c.ShouldBindJSON(&req)
+
+	lesson, err := transition(lessonID, currentUser.ID, req.Reason)
+	if err != nil {
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, lesson.ToDTO())
 }
-func (h *LessonHandler) StartLesson(c *gin.Context) {
-	lessonIDStr := c.Param("lessonID")
-	lessonID, err := uuid.Parse(lessonIDStr)
+
+// GetLessonHistory returns the status transitions of a lesson, oldest first, to its
+// participants and admins.
+func (h *LessonHandler) GetLessonHistory(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
 		return
 	}
 
-	lesson, err := h.App.LessonService.StartLesson(lessonID)
+	currentUser, err := getCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	c.JSON(http.StatusOK, lesson.ToDTO())
-}
-func (h *LessonHandler) CompleteLesson(c *gin.Context) {
-	lessonIDStr := c.Param("lessonID")
-	lessonID, err := uuid.Parse(lessonIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+	history, err := h.App.LessonService.GetStatusHistory(lessonID, currentUser)
+	if errors.Is(err, services.ErrNotLessonParticipant) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		return
 	}
-
-	lesson, err := h.App.LessonService.CompleteLesson(lessonID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusNotFound, gin.H{"error": "lesson not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, lesson.ToDTO())
+	dtos := make([]models.LessonStatusHistoryDTO, 0, len(history))
+	for _, entry := range history {
+		dtos = append(dtos, entry.ToDTO())
+	}
+	c.JSON(http.StatusOK, dtos)
 }
-func (h *LessonHandler) FailLesson(c *gin.Context) {
-	lessonIDStr := c.Param("lessonID")
-	lessonID, err := uuid.Parse(lessonIDStr)
+
+type cancelLessonRequest struct {
+	Reason string `json:"reason" binding:"required"` // one of the models.CancellationReason* values
+	Note   string `json:"note"`
+}
+
+// CancelLesson cancels a lesson. The response includes any late-cancellation fee
+// charged under the tutor's cancellation policy. A student of a lesson with other
+// students only cancels their own seat; the response then holds the lesson and the
+// seat cancellation with its fee.
+func (h *LessonHandler) CancelLesson(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
 		return
 	}
 
-	lesson, err := h.App.LessonService.FailLesson(lessonID)
+	currentUser, err := getCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	c.JSON(http.StatusOK, lesson.ToDTO())
-}
-func (h *LessonHandler) CancelLesson(c *gin.Context) {
-	lessonIDStr := c.Param("lessonID")
-	lessonID, err := uuid.Parse(lessonIDStr)
+	var req cancelLessonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	// A student cancelling a lesson with other students, in a course or not, only gives
+	// up their own seat, which goes to the next student on the waitlist; the lesson takes
+	// place for the others.
+	lesson, err := h.App.LessonService.GetLessonWithParticipants(lessonID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "lesson not found"})
+		return
+	}
+	if len(lesson.Students) > 1 && isLessonStudent(lesson, currentUser.ID) {
+		cancellation, err := h.App.EnrollmentService.CancelSeat(lessonID, currentUser.ID, req.Reason, req.Note)
+		if err != nil {
+			c.JSON(enrollmentErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		lesson, err = h.App.LessonService.GetLessonWithParticipants(lessonID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"lesson":            lesson.ToDTO(),
+			"seat_cancellation": cancellation,
+		})
 		return
 	}
 
-	lesson, err := h.App.LessonService.CancelLesson(lessonID)
+	lesson, err = h.App.LessonService.CancelLesson(lessonID, currentUser.ID, req.Reason, req.Note)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, lesson.ToDTO())
 }
 
-type postponeLessonRequest struct {
-	NewStartTime string `json:"new_start_time"` // RFC3339 format
-	NewEndTime   string `json:"new_end_time"`
+type disputeLessonRequest struct {
+	Reason string `json:"reason" binding:"required"`
 }
 
-// PostponeLesson updates the lesson times and sets status back to "scheduled" (or pending).
-func (h *LessonHandler) PostponeLesson(c *gin.Context) {
-	lessonIDStr := c.Param("lessonID")
-	lessonID, err := uuid.Parse(lessonIDStr)
+// DisputeLesson flags a lesson as disputed so it is not completed automatically.
+func (h *LessonHandler) DisputeLesson(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
 		return
 	}
 
-	var req postponeLessonRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
-		return
-	}
-
-	newStart, err := time.Parse(time.RFC3339, req.NewStartTime)
+	currentUser, err := getCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid new_start_time"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	newEnd, err := time.Parse(time.RFC3339, req.NewEndTime)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid new_end_time"})
+
+	var req disputeLessonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
 		return
 	}
 
-	lesson, err := h.App.LessonService.PostponeLesson(lessonID, newStart, newEnd)
+	lesson, err := h.App.LessonService.DisputeLesson(lessonID, currentUser.ID, req.Reason)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -237,6 +319,7 @@ func (h *LessonHandler) PostponeLesson(c *gin.Context) {
 
 	c.JSON(http.StatusOK, lesson.ToDTO())
 }
+
 func (h *LessonHandler) GetLessonsForUser(c *gin.Context) {
 	userIDStr := c.Param("userID")
 	userID, err := uuid.Parse(userIDStr)
@@ -283,3 +366,127 @@ func (h *LessonHandler) GetTutorsForUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dtos)
 }
+
+// lessonErrorStatus maps scheduling errors from the lesson service to HTTP status codes,
+// falling back to the given status for anything else.
+func lessonErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, services.ErrUsersBlocked), errors.Is(err, services.ErrTrialNotEligible),
+		errors.Is(err, services.ErrNotSeriesTutor), errors.Is(err, services.ErrNotSeriesParticipant),
+		errors.Is(err, services.ErrNotLessonParticipant), errors.Is(err, services.ErrLessonTransitionForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrLessonConflict), errors.Is(err, services.ErrOutsideAvailability),
+		errors.Is(err, services.ErrInvalidLessonTransition), errors.Is(err, services.ErrSlotHeld),
+		errors.Is(err, services.ErrHoldNotActive), errors.Is(err, services.ErrBookingRule):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidLessonTime):
+		return http.StatusBadRequest
+	default:
+		return fallback
+	}
+}
+
+// meetingURL returns the lesson's meeting link if participants may see it at now: while the
+// lesson takes place and from Config.MeetingLinkReveal before it starts.
+func (h *LessonHandler) meetingURL(lesson models.Lesson, now time.Time) string {
+	if lesson.Status != models.LessonStatusConfirmed && lesson.Status != models.LessonStatusInProgress {
+		return ""
+	}
+	if now.Before(lesson.StartTime.Add(-h.App.Config.MeetingLinkReveal)) || now.After(lesson.EndTime) {
+		return ""
+	}
+	return lesson.MeetingURL
+}
+
+// isLessonParticipant reports whether userID is the lesson's tutor or one of its students.
+func isLessonParticipant(lesson models.Lesson, userID uuid.UUID) bool {
+	return lesson.TutorID == userID || isLessonStudent(lesson, userID)
+}
+
+func isLessonStudent(lesson models.Lesson, userID uuid.UUID) bool {
+	for _, student := range lesson.Students {
+		if student.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+type attendanceRecordRequest struct {
+	StudentID string `json:"student_id" binding:"required"`
+	Status    string `json:"status" binding:"required"` // present, late, absent or excused
+	Note      string `json:"note"`
+}
+
+type recordAttendanceRequest struct {
+	Attendance []attendanceRecordRequest `json:"attendance" binding:"required"`
+}
+
+// RecordAttendance lets the tutor record which students attended a lesson.
+func (h *LessonHandler) RecordAttendance(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req recordAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "attendance with student_id and status is required"})
+		return
+	}
+	records := make([]models.LessonStudent, 0, len(req.Attendance))
+	for _, record := range req.Attendance {
+		studentID, err := uuid.Parse(record.StudentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid student_id: " + record.StudentID})
+			return
+		}
+		records = append(records, models.LessonStudent{
+			UserID:         studentID,
+			Attendance:     record.Status,
+			AttendanceNote: record.Note,
+		})
+	}
+
+	lesson, err := h.App.LessonService.RecordAttendance(lessonID, currentUser.ID, records)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, lesson.ToDTO())
+}
+
+// GetStudentStats returns a student's lesson statistics, including attendance. They are
+// visible to the student and admins, and to the student's tutors for their own lessons.
+func (h *LessonHandler) GetStudentStats(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	stats, err := h.App.LessonService.GetStudentStats(userID, currentUser)
+	if errors.Is(err, services.ErrStudentStatsForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/models"
+	"vibely-backend/src/services"
+	"vibely-backend/src/storage"
+)
+
+// LessonMaterialHandler handles lesson notes, homework and attachments.
+type LessonMaterialHandler struct {
+	App *app.Application
+}
+
+// NewLessonMaterialHandler creates a new LessonMaterialHandler.
+func NewLessonMaterialHandler(app *app.Application) *LessonMaterialHandler {
+	return &LessonMaterialHandler{App: app}
+}
+
+// GetMaterials lists the notes, homework and attachments of a lesson for its participants.
+func (h *LessonMaterialHandler) GetMaterials(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	materials, err := h.App.LessonMaterialService.GetMaterials(lessonID, currentUser.ID)
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, materials)
+}
+
+type lessonNoteRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// AddNote lets the tutor write a note on a lesson.
+func (h *LessonMaterialHandler) AddNote(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req lessonNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body is required"})
+		return
+	}
+
+	note, err := h.App.LessonMaterialService.AddNote(lessonID, currentUser.ID, req.Body)
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// UpdateNote lets the tutor rewrite one of their lesson notes.
+func (h *LessonMaterialHandler) UpdateNote(c *gin.Context) {
+	noteID, err := uuid.Parse(c.Param("noteID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid note ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req lessonNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body is required"})
+		return
+	}
+
+	note, err := h.App.LessonMaterialService.UpdateNote(noteID, currentUser.ID, req.Body)
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// DeleteNote lets the tutor remove one of their lesson notes.
+func (h *LessonMaterialHandler) DeleteNote(c *gin.Context) {
+	noteID, err := uuid.Parse(c.Param("noteID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid note ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.LessonMaterialService.DeleteNote(noteID, currentUser.ID); err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Note deleted"})
+}
+
+type homeworkRequest struct {
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description"`
+	DueAt       *time.Time `json:"due_at"`    // optional, RFC 3339
+	MaxScore    float64    `json:"max_score"` // defaults to 100
+}
+
+func (req homeworkRequest) toHomework() models.Homework {
+	return models.Homework{
+		Title:       req.Title,
+		Description: req.Description,
+		DueAt:       req.DueAt,
+		MaxScore:    req.MaxScore,
+	}
+}
+
+// AddHomework lets the tutor assign homework to the students of a lesson.
+func (h *LessonMaterialHandler) AddHomework(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req homeworkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	homework, err := h.App.LessonMaterialService.AddHomework(lessonID, currentUser.ID, req.toHomework())
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, homework)
+}
+
+// UpdateHomework lets the tutor change a homework assignment.
+func (h *LessonMaterialHandler) UpdateHomework(c *gin.Context) {
+	homeworkID, err := uuid.Parse(c.Param("homeworkID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid homework ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req homeworkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	homework, err := h.App.LessonMaterialService.UpdateHomework(homeworkID, currentUser.ID, req.toHomework())
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, homework)
+}
+
+// DeleteHomework lets the tutor remove a homework assignment.
+func (h *LessonMaterialHandler) DeleteHomework(c *gin.Context) {
+	homeworkID, err := uuid.Parse(c.Param("homeworkID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid homework ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.LessonMaterialService.DeleteHomework(homeworkID, currentUser.ID); err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Homework deleted"})
+}
+
+// UploadAttachment lets the tutor attach a file, sent as the multipart form field "file",
+// to a lesson.
+func (h *LessonMaterialHandler) UploadAttachment(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if header.Size > h.App.Config.MaxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrAttachmentTooLarge.Error()})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.App.LessonMaterialService.AddAttachment(lessonID, currentUser.ID, uploadFromHeader(header, file))
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DownloadAttachment sends an attachment's file to a lesson participant.
+func (h *LessonMaterialHandler) DownloadAttachment(c *gin.Context) {
+	attachmentID, err := uuid.Parse(c.Param("attachmentID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	attachment, file, err := h.App.LessonMaterialService.OpenAttachment(attachmentID, currentUser.ID)
+	if err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment lets the tutor remove a lesson attachment.
+func (h *LessonMaterialHandler) DeleteAttachment(c *gin.Context) {
+	attachmentID, err := uuid.Parse(c.Param("attachmentID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.LessonMaterialService.DeleteAttachment(attachmentID, currentUser.ID); err != nil {
+		c.JSON(materialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted"})
+}
+
+// materialErrorStatus picks the HTTP status for an error from the LessonMaterialService
+// or the HomeworkService.
+func materialErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotLessonParticipant), errors.Is(err, services.ErrNotLessonTutor):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrSubmissionResubmitted):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/models"
+	"vibely-backend/src/services"
+)
+
+// LessonSeriesHandler handles endpoints for recurring lesson series.
+type LessonSeriesHandler struct {
+	App *app.Application
+}
+
+// NewLessonSeriesHandler creates a new LessonSeriesHandler.
+func NewLessonSeriesHandler(app *app.Application) *LessonSeriesHandler {
+	return &LessonSeriesHandler{App: app}
+}
+
+type createLessonSeriesRequest struct {
+	TutorID         string   `json:"tutor_id"`
+	StudentIDs      []string `json:"student_ids"`
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	Subject         string   `json:"subject"`
+	Level           string   `json:"level"`
+	StartTime       string   `json:"start_time"` // RFC3339, first occurrence
+	DurationMinutes int      `json:"duration_minutes"`
+	RRule           string   `json:"rrule"`    // e.g. "FREQ=WEEKLY;BYDAY=MO;UNTIL=20260630"
+	Timezone        string   `json:"timezone"` // IANA name, defaults to Europe/Warsaw
+}
+
+// CreateSeries creates a recurring series and books its upcoming occurrences.
+// Occurrences that could not be booked are listed under "conflicts".
+func (h *LessonSeriesHandler) CreateSeries(c *gin.Context) {
+	var req createLessonSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	tutorUUID, err := uuid.Parse(req.TutorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor_id"})
+		return
+	}
+
+	var studentUsers []models.User
+	for _, sid := range req.StudentIDs {
+		studentUUID, err := uuid.Parse(sid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid student_id: " + sid})
+			return
+		}
+		student, err := h.App.UserService.GetUserByID(studentUUID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "student not found: " + sid})
+			return
+		}
+		studentUsers = append(studentUsers, student)
+	}
+
+	start, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_time"})
+		return
+	}
+
+	series := models.LessonSeries{
+		TutorID:         tutorUUID,
+		Students:        studentUsers,
+		Title:           req.Title,
+		Description:     req.Description,
+		Subject:         req.Subject,
+		Level:           req.Level,
+		StartTime:       start,
+		DurationMinutes: req.DurationMinutes,
+		RRule:           req.RRule,
+		Timezone:        req.Timezone,
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := h.App.LessonSeriesService.CreateSeries(series, currentUser)
+	if err != nil {
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result.ToDTO())
+}
+
+// GetSeries retrieves a series with its materialized lessons.
+func (h *LessonSeriesHandler) GetSeries(c *gin.Context) {
+	seriesID, err := uuid.Parse(c.Param("seriesID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid series ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	series, err := h.App.LessonSeriesService.GetSeries(seriesID, currentUser)
+	if errors.Is(err, services.ErrNotSeriesParticipant) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "lesson series not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series.ToDTO())
+}
+
+type updateOccurrenceRequest struct {
+	Scope           string  `json:"scope" binding:"required"` // this, following or all
+	Title           *string `json:"title"`
+	Description     *string `json:"description"`
+	StartTime       *string `json:"start_time"` // RFC3339, new start of the edited occurrence
+	DurationMinutes *int    `json:"duration_minutes"`
+}
+
+// UpdateOccurrence edits one occurrence, this and following occurrences, or the whole series.
+func (h *LessonSeriesHandler) UpdateOccurrence(c *gin.Context) {
+	seriesID, err := uuid.Parse(c.Param("seriesID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid series ID"})
+		return
+	}
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	var req updateOccurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
+		return
+	}
+
+	changes := models.LessonSeriesChanges{
+		Title:           req.Title,
+		Description:     req.Description,
+		DurationMinutes: req.DurationMinutes,
+	}
+	if req.StartTime != nil {
+		start, err := time.Parse(time.RFC3339, *req.StartTime)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_time"})
+			return
+		}
+		changes.StartTime = &start
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := h.App.LessonSeriesService.UpdateOccurrence(seriesID, lessonID, currentUser, req.Scope, changes)
+	if err != nil {
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result.ToDTO())
+}
+
+type cancelOccurrenceRequest struct {
+	Scope string `json:"scope" binding:"required"` // this, following or all
+}
+
+// CancelOccurrence cancels one occurrence, this and following occurrences, or the whole series.
+func (h *LessonSeriesHandler) CancelOccurrence(c *gin.Context) {
+	seriesID, err := uuid.Parse(c.Param("seriesID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid series ID"})
+		return
+	}
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	var req cancelOccurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scope is required"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := h.App.LessonSeriesService.CancelOccurrence(seriesID, lessonID, currentUser, req.Scope)
+	if err != nil {
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result.ToDTO())
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"vibely-backend/src/app"
+	"vibely-backend/src/services"
+)
+
+// MailHandler handles the public endpoints of the mailer: unsubscribe links and bounce
+// reports from the mail provider.
+type MailHandler struct {
+	App *app.Application
+}
+
+// NewMailHandler creates a new MailHandler.
+func NewMailHandler(app *app.Application) *MailHandler {
+	return &MailHandler{App: app}
+}
+
+// Unsubscribe handles POST /api/mail/unsubscribe?token=..., sent by the frontend's
+// unsubscribe page and by mail clients' one-click unsubscribe.
+func (h *MailHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if err := h.App.MailService.Unsubscribe(token); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidUnsubscribeToken) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type bounceRequest struct {
+	Email  string `json:"email" binding:"required"`
+	Kind   string `json:"kind" binding:"required"` // hard, soft or complaint
+	Reason string `json:"reason"`
+}
+
+// ReportBounce records a bounce or complaint reported by the mail provider. The provider
+// authenticates with the configured bounce token as a bearer token; without one, bounce
+// reports are disabled.
+func (h *MailHandler) ReportBounce(c *gin.Context) {
+	expected := h.App.Config.MailBounceToken
+	if expected == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bounce reports are disabled"})
+		return
+	}
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req bounceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email and kind are required"})
+		return
+	}
+
+	if err := h.App.MailService.RecordBounce(req.Email, req.Kind, req.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/services"
+)
+
+// MessageHandler handles conversations and messages.
+type MessageHandler struct {
+	App *app.Application
+}
+
+// NewMessageHandler creates a new MessageHandler.
+func NewMessageHandler(app *app.Application) *MessageHandler {
+	return &MessageHandler{App: app}
+}
+
+// GetConversations lists the current user's conversations with unread counts.
+func (h *MessageHandler) GetConversations(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	conversations, err := h.App.MessageService.GetConversations(currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, conversations)
+}
+
+type startConversationRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// StartConversation opens the direct conversation between the current user and another one.
+func (h *MessageHandler) StartConversation(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req startConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+		return
+	}
+	otherID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	conversation, err := h.App.MessageService.StartDirectConversation(currentUser.ID, otherID)
+	if err != nil {
+		c.JSON(messageErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, conversation)
+}
+
+// GetLessonConversation opens the thread of a lesson for one of its participants.
+func (h *MessageHandler) GetLessonConversation(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	conversation, err := h.App.MessageService.GetLessonConversation(lessonID, currentUser.ID)
+	if err != nil {
+		c.JSON(messageErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, conversation)
+}
+
+// GetMessages returns a page of a conversation's history, newest first. Pass the
+// created_at of the oldest message received as ?before= to get the previous page.
+func (h *MessageHandler) GetMessages(c *gin.Context) {
+	conversationID, err := uuid.Parse(c.Param("conversationID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid conversation ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var before *time.Time
+	if beforeStr := c.Query("before"); beforeStr != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, beforeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "before must be an RFC 3339 time"})
+			return
+		}
+		before = &parsed
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	messages, err := h.App.MessageService.GetMessages(conversationID, currentUser.ID, before, limit)
+	if err != nil {
+		c.JSON(messageErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+type sendMessageRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// SendMessage posts a message from the current user to a conversation.
+func (h *MessageHandler) SendMessage(c *gin.Context) {
+	conversationID, err := uuid.Parse(c.Param("conversationID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid conversation ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req sendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body is required"})
+		return
+	}
+
+	message, err := h.App.MessageService.SendMessage(conversationID, currentUser.ID, req.Body)
+	if err != nil {
+		c.JSON(messageErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// MarkRead marks a conversation as read by the current user.
+func (h *MessageHandler) MarkRead(c *gin.Context) {
+	conversationID, err := uuid.Parse(c.Param("conversationID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid conversation ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.MessageService.MarkRead(conversationID, currentUser.ID); err != nil {
+		c.JSON(messageErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Conversation marked as read"})
+}
+
+// GetUnreadCount returns how many messages the current user has not read.
+func (h *MessageHandler) GetUnreadCount(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	count, err := h.App.MessageService.GetUnreadCount(currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": count})
+}
+
+// messageErrorStatus picks the HTTP status for an error from the MessageService.
+func messageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotConversationMember),
+		errors.Is(err, services.ErrMessagingNotAllowed),
+		errors.Is(err, services.ErrMessagingBlocked):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/models"
+)
+
+// ModerationHandler handles reporting, blocking and the admin moderation queue.
+type ModerationHandler struct {
+	App *app.Application
+}
+
+// NewModerationHandler creates a new ModerationHandler.
+func NewModerationHandler(app *app.Application) *ModerationHandler {
+	return &ModerationHandler{App: app}
+}
+
+type createReportRequest struct {
+	TargetType string `json:"target_type" binding:"required"`
+	TargetID   string `json:"target_id" binding:"required"`
+	Reason     string `json:"reason" binding:"required"`
+	Details    string `json:"details"`
+}
+
+// CreateReport files a report against a user, review or course.
+func (h *ModerationHandler) CreateReport(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req createReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	targetID, err := uuid.Parse(req.TargetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target_id"})
+		return
+	}
+
+	report, err := h.App.Moderation.ReportContent(currentUser.ID, req.TargetType, targetID, req.Reason, req.Details)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, report.ToDTO())
+}
+
+// BlockUser blocks the user given in the URL for the authenticated user.
+func (h *ModerationHandler) BlockUser(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	blockedID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	block, err := h.App.Moderation.BlockUser(currentUser.ID, blockedID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, block)
+}
+
+// UnblockUser removes a block placed by the authenticated user.
+func (h *ModerationHandler) UnblockUser(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	blockedID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.App.Moderation.UnblockUser(currentUser.ID, blockedID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unblocked successfully"})
+}
+
+// GetBlockedUsers lists the users blocked by the authenticated user.
+func (h *ModerationHandler) GetBlockedUsers(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	users, err := h.App.Moderation.GetBlockedUsers(currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]models.StudentDTO, 0, len(users))
+	for _, user := range users {
+		dtos = append(dtos, user.ToStudentDTO())
+	}
+
+	c.JSON(http.StatusOK, dtos)
+}
+
+// GetReports handles GET /api/admin/reports?status=...&page=...&limit=...
+func (h *ModerationHandler) GetReports(c *gin.Context) {
+	status := c.DefaultQuery("status", models.ReportStatusOpen)
+	if status == "all" {
+		status = ""
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	reports, total, err := h.App.Moderation.GetReports(status, page, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]models.ReportDTO, 0, len(reports))
+	for _, report := range reports {
+		dtos = append(dtos, report.ToDTO())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reports": dtos,
+		"page":    page,
+		"limit":   limit,
+		"total":   total,
+	})
+}
+
+type resolveReportRequest struct {
+	Note        string `json:"note"`
+	SuspendUser bool   `json:"suspend_user"`
+}
+
+// ResolveReport closes a report as actioned, optionally suspending the reported user.
+func (h *ModerationHandler) ResolveReport(c *gin.Context) {
+	admin, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	reportID, err := uuid.Parse(c.Param("reportID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	var req resolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
+		return
+	}
+
+	report, err := h.App.Moderation.ResolveReport(reportID, admin.ID, req.Note, req.SuspendUser)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report.ToDTO())
+}
+
+type dismissReportRequest struct {
+	Note string `json:"note"`
+}
+
+// DismissReport closes a report without action.
+func (h *ModerationHandler) DismissReport(c *gin.Context) {
+	admin, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	reportID, err := uuid.Parse(c.Param("reportID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	var req dismissReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
+		return
+	}
+
+	report, err := h.App.Moderation.DismissReport(reportID, admin.ID, req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report.ToDTO())
+}
+
+type suspendUserRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// SuspendUser suspends an account; the JWT middleware rejects it from then on.
+func (h *ModerationHandler) SuspendUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req suspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	user, err := h.App.Moderation.SuspendUser(userID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "User suspended successfully",
+		"user_id":      user.ID,
+		"suspended_at": user.SuspendedAt,
+	})
+}
+
+// UnsuspendUser lifts a suspension.
+func (h *ModerationHandler) UnsuspendUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	user, err := h.App.Moderation.UnsuspendUser(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unsuspended successfully",
+		"user_id": user.ID,
+	})
+}
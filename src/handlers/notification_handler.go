@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/models"
+	"vibely-backend/src/services"
+)
+
+// NotificationHandler handles the notification center and notification preferences.
+type NotificationHandler struct {
+	App *app.Application
+}
+
+// NewNotificationHandler creates a new NotificationHandler.
+func NewNotificationHandler(app *app.Application) *NotificationHandler {
+	return &NotificationHandler{App: app}
+}
+
+// GetNotifications handles GET /api/notifications?unread=true&page=...&limit=...
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	notifications, total, err := h.App.NotificationService.GetNotifications(currentUser.ID, unreadOnly, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	unread, err := h.App.NotificationService.GetUnreadCount(currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if notifications == nil {
+		notifications = []models.Notification{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"unread_count":  unread,
+		"page":          page,
+		"limit":         limit,
+		"total":         total,
+	})
+}
+
+// MarkNotificationRead marks one of the current user's notifications as read.
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	notificationID, err := uuid.Parse(c.Param("notificationID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification ID"})
+		return
+	}
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.NotificationService.MarkRead(notificationID, currentUser.ID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllNotificationsRead marks all of the current user's notifications as read.
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.NotificationService.MarkAllRead(currentUser.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetPreferences returns the current user's notification preferences.
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	preferences, err := h.App.NotificationService.GetPreferences(currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+type notificationPreferencesRequest struct {
+	InAppEnabled    *bool  `json:"in_app_enabled" binding:"required"`
+	EmailEnabled    *bool  `json:"email_enabled" binding:"required"`
+	DiscordEnabled  *bool  `json:"discord_enabled"`   // defaults to true
+	QuietHoursStart string `json:"quiet_hours_start"` // e.g. "22:00", empty for none
+	QuietHoursEnd   string `json:"quiet_hours_end"`   // e.g. "07:00"
+	TimeZone        string `json:"time_zone"`         // IANA name, defaults to UTC
+	Language        string `json:"language"`          // of emails, "pl" or "en"
+}
+
+// UpdatePreferences replaces the current user's notification preferences.
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req notificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "in_app_enabled and email_enabled are required"})
+		return
+	}
+
+	discordEnabled := req.DiscordEnabled == nil || *req.DiscordEnabled
+	preferences, err := h.App.NotificationService.UpdatePreferences(models.NotificationPreferences{
+		UserID:          currentUser.ID,
+		InAppEnabled:    *req.InAppEnabled,
+		EmailEnabled:    *req.EmailEnabled,
+		DiscordEnabled:  discordEnabled,
+		QuietHoursStart: req.QuietHoursStart,
+		QuietHoursEnd:   req.QuietHoursEnd,
+		TimeZone:        req.TimeZone,
+		Language:        req.Language,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/models"
+	"vibely-backend/src/services"
+)
+
+// QuizHandler handles quizzes and students' attempts at them.
+type QuizHandler struct {
+	App *app.Application
+}
+
+// NewQuizHandler creates a new QuizHandler.
+func NewQuizHandler(app *app.Application) *QuizHandler {
+	return &QuizHandler{App: app}
+}
+
+type quizQuestionRequest struct {
+	Type            string   `json:"type" binding:"required"` // multiple_choice, numeric or short_answer
+	Prompt          string   `json:"prompt" binding:"required"`
+	Points          float64  `json:"points"` // defaults to 1
+	Options         []string `json:"options"`
+	CorrectOptions  []int64  `json:"correct_options"`
+	CorrectNumber   *float64 `json:"correct_number"`
+	Tolerance       float64  `json:"tolerance"`
+	AcceptedAnswers []string `json:"accepted_answers"` // leave empty to grade by hand
+}
+
+type quizRequest struct {
+	CourseID         string                `json:"course_id"` // either course_id or lesson_id, when creating
+	LessonID         string                `json:"lesson_id"`
+	Title            string                `json:"title" binding:"required"`
+	Description      string                `json:"description"`
+	TimeLimitMinutes int                   `json:"time_limit_minutes"`
+	MaxAttempts      int                   `json:"max_attempts"`
+	Questions        []quizQuestionRequest `json:"questions" binding:"required"`
+}
+
+func (req quizRequest) toQuiz() (models.Quiz, error) {
+	quiz := models.Quiz{
+		Title:            req.Title,
+		Description:      req.Description,
+		TimeLimitMinutes: req.TimeLimitMinutes,
+		MaxAttempts:      req.MaxAttempts,
+	}
+	if req.CourseID != "" {
+		courseID, err := uuid.Parse(req.CourseID)
+		if err != nil {
+			return models.Quiz{}, errors.New("invalid course_id")
+		}
+		quiz.CourseID = &courseID
+	}
+	if req.LessonID != "" {
+		lessonID, err := uuid.Parse(req.LessonID)
+		if err != nil {
+			return models.Quiz{}, errors.New("invalid lesson_id")
+		}
+		quiz.LessonID = &lessonID
+	}
+	for _, question := range req.Questions {
+		quiz.Questions = append(quiz.Questions, models.QuizQuestion{
+			Type:            question.Type,
+			Prompt:          question.Prompt,
+			Points:          question.Points,
+			Options:         question.Options,
+			CorrectOptions:  question.CorrectOptions,
+			CorrectNumber:   question.CorrectNumber,
+			Tolerance:       question.Tolerance,
+			AcceptedAnswers: question.AcceptedAnswers,
+		})
+	}
+	return quiz, nil
+}
+
+// CreateQuiz lets a tutor add a quiz to one of their courses or lessons.
+func (h *QuizHandler) CreateQuiz(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req quizRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title and questions are required"})
+		return
+	}
+	quiz, err := req.toQuiz()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.App.QuizService.CreateQuiz(quiz, currentUser.ID)
+	if err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetQuiz returns a quiz. Its tutor gets the answers too; students do not.
+func (h *QuizHandler) GetQuiz(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("quizID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quiz ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	quiz, err := h.App.QuizService.GetQuiz(quizID, currentUser.ID)
+	if err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if quiz.TutorID == currentUser.ID {
+		c.JSON(http.StatusOK, quiz)
+		return
+	}
+	c.JSON(http.StatusOK, quiz.ToDTO())
+}
+
+// GetCourseQuizzes lists the quizzes of a course.
+func (h *QuizHandler) GetCourseQuizzes(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("courseID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course ID"})
+		return
+	}
+	h.listQuizzes(c, func(userID uuid.UUID) ([]models.Quiz, error) {
+		return h.App.QuizService.GetCourseQuizzes(courseID, userID)
+	})
+}
+
+// GetLessonQuizzes lists the quizzes of a lesson.
+func (h *QuizHandler) GetLessonQuizzes(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+	h.listQuizzes(c, func(userID uuid.UUID) ([]models.Quiz, error) {
+		return h.App.QuizService.GetLessonQuizzes(lessonID, userID)
+	})
+}
+
+// listQuizzes lists quizzes for the current user, hiding the answers from students.
+func (h *QuizHandler) listQuizzes(c *gin.Context, list func(userID uuid.UUID) ([]models.Quiz, error)) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	quizzes, err := list(currentUser.ID)
+	if err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]interface{}, 0, len(quizzes))
+	for _, quiz := range quizzes {
+		if quiz.TutorID == currentUser.ID {
+			result = append(result, quiz)
+		} else {
+			result = append(result, quiz.ToDTO())
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateQuiz lets the tutor rewrite a quiz nobody has attempted yet.
+func (h *QuizHandler) UpdateQuiz(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("quizID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quiz ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req quizRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title and questions are required"})
+		return
+	}
+	quiz, err := req.toQuiz()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.App.QuizService.UpdateQuiz(quizID, currentUser.ID, quiz)
+	if err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteQuiz lets the tutor remove a quiz nobody has attempted yet.
+func (h *QuizHandler) DeleteQuiz(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("quizID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quiz ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.QuizService.DeleteQuiz(quizID, currentUser.ID); err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quiz deleted"})
+}
+
+// StartAttempt starts, or resumes, the current user's attempt at a quiz.
+func (h *QuizHandler) StartAttempt(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("quizID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quiz ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	attempt, err := h.App.QuizService.StartAttempt(quizID, currentUser.ID)
+	if err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attempt.ToDTO())
+}
+
+// GetAttempts lists the attempts at a quiz; students only see their own.
+func (h *QuizHandler) GetAttempts(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("quizID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quiz ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	attempts, err := h.App.QuizService.GetAttempts(quizID, currentUser.ID)
+	if err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]models.QuizAttemptDTO, 0, len(attempts))
+	for _, attempt := range attempts {
+		dtos = append(dtos, attempt.ToDTO())
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+type quizAnswerRequest struct {
+	QuestionID string   `json:"question_id" binding:"required"`
+	Choices    []int64  `json:"choices"` // option indexes, for multiple-choice questions
+	Number     *float64 `json:"number"`
+	Text       string   `json:"text"`
+}
+
+type submitAttemptRequest struct {
+	Answers []quizAnswerRequest `json:"answers"`
+}
+
+// SubmitAttempt hands in the current user's answers and returns the graded attempt.
+func (h *QuizHandler) SubmitAttempt(c *gin.Context) {
+	attemptID, err := uuid.Parse(c.Param("attemptID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attempt ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req submitAttemptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "answers with question_id are required"})
+		return
+	}
+	answers := make([]models.QuizAnswer, 0, len(req.Answers))
+	for _, answer := range req.Answers {
+		questionID, err := uuid.Parse(answer.QuestionID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid question_id: " + answer.QuestionID})
+			return
+		}
+		answers = append(answers, models.QuizAnswer{
+			QuestionID: questionID,
+			Choices:    answer.Choices,
+			Number:     answer.Number,
+			Text:       answer.Text,
+		})
+	}
+
+	attempt, err := h.App.QuizService.SubmitAttempt(attemptID, currentUser.ID, answers)
+	if err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attempt.ToDTO())
+}
+
+type gradeAnswerRequest struct {
+	Points *float64 `json:"points" binding:"required"`
+}
+
+// GradeAnswer lets the tutor award points for one answer of an attempt.
+func (h *QuizHandler) GradeAnswer(c *gin.Context) {
+	attemptID, err := uuid.Parse(c.Param("attemptID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attempt ID"})
+		return
+	}
+	questionID, err := uuid.Parse(c.Param("questionID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid question ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req gradeAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "points is required"})
+		return
+	}
+
+	attempt, err := h.App.QuizService.GradeAnswer(attemptID, questionID, currentUser.ID, *req.Points)
+	if err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attempt.ToDTO())
+}
+
+// quizErrorStatus picks the HTTP status for an error from the QuizService.
+func quizErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNoQuizAccess):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrQuizAttemptsUsed), errors.Is(err, services.ErrQuizLocked), errors.Is(err, services.ErrQuizTimeUp):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/models"
+	"vibely-backend/src/services"
+)
+
+// RescheduleHandler handles endpoints for lesson reschedule proposals.
+type RescheduleHandler struct {
+	App *app.Application
+}
+
+// NewRescheduleHandler creates a new RescheduleHandler.
+func NewRescheduleHandler(app *app.Application) *RescheduleHandler {
+	return &RescheduleHandler{App: app}
+}
+
+type rescheduleOptionRequest struct {
+	StartTime string `json:"start_time"` // RFC3339
+	EndTime   string `json:"end_time"`
+}
+
+type proposeRescheduleRequest struct {
+	Options []rescheduleOptionRequest `json:"options"`
+	Message string                    `json:"message"`
+
+	// Single-window form, kept for clients of the former postpone endpoint.
+	NewStartTime string `json:"new_start_time"`
+	NewEndTime   string `json:"new_end_time"`
+}
+
+// ProposeReschedule creates a proposal to move the lesson to one of the given time windows.
+// The lesson keeps its time until the other party accepts an option.
+func (h *RescheduleHandler) ProposeReschedule(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req proposeRescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
+		return
+	}
+	if req.NewStartTime != "" || req.NewEndTime != "" {
+		req.Options = append(req.Options, rescheduleOptionRequest{StartTime: req.NewStartTime, EndTime: req.NewEndTime})
+	}
+
+	options, err := parseRescheduleOptions(req.Options)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	proposal, err := h.App.RescheduleService.ProposeReschedule(lessonID, currentUser.ID, options, req.Message)
+	if err != nil {
+		c.JSON(rescheduleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, proposal.ToDTO())
+}
+
+// GetLessonProposals lists the reschedule proposals of a lesson, newest first.
+func (h *RescheduleHandler) GetLessonProposals(c *gin.Context) {
+	lessonID, err := uuid.Parse(c.Param("lessonID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	proposals, err := h.App.RescheduleService.GetProposalsForLesson(lessonID, currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]models.RescheduleProposalDTO, 0, len(proposals))
+	for _, proposal := range proposals {
+		dtos = append(dtos, proposal.ToDTO())
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+type acceptProposalRequest struct {
+	OptionID string `json:"option_id" binding:"required"`
+}
+
+// AcceptProposal moves the lesson to the chosen option of a proposal. For a tutor's
+// proposal in a group lesson, the proposal stays pending until every student accepted.
+func (h *RescheduleHandler) AcceptProposal(c *gin.Context) {
+	proposalID, err := uuid.Parse(c.Param("proposalID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proposal ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req acceptProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "option_id is required"})
+		return
+	}
+	optionID, err := uuid.Parse(req.OptionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid option_id"})
+		return
+	}
+
+	proposal, lesson, err := h.App.RescheduleService.AcceptProposal(proposalID, currentUser.ID, optionID)
+	if err != nil {
+		c.JSON(rescheduleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"proposal": proposal.ToDTO(),
+		"lesson":   lesson.ToDTO(),
+	})
+}
+
+type rejectProposalRequest struct {
+	Note string `json:"note"`
+}
+
+// RejectProposal declines a proposal; the lesson keeps its current time.
+func (h *RescheduleHandler) RejectProposal(c *gin.Context) {
+	proposalID, err := uuid.Parse(c.Param("proposalID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proposal ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// The note is optional, so a missing or empty body is not an error.
+	var req rejectProposalRequest
+	_ = c.ShouldBindJSON(&req)
+
+	proposal, err := h.App.RescheduleService.RejectProposal(proposalID, currentUser.ID, req.Note)
+	if err != nil {
+		c.JSON(rescheduleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, proposal.ToDTO())
+}
+
+type counterProposalRequest struct {
+	Options []rescheduleOptionRequest `json:"options" binding:"required"`
+	Message string                    `json:"message"`
+}
+
+// CounterProposal replaces a proposal with different options for the original proposer to answer.
+func (h *RescheduleHandler) CounterProposal(c *gin.Context) {
+	proposalID, err := uuid.Parse(c.Param("proposalID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proposal ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req counterProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "options are required"})
+		return
+	}
+	options, err := parseRescheduleOptions(req.Options)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	proposal, err := h.App.RescheduleService.CounterProposal(proposalID, currentUser.ID, options, req.Message)
+	if err != nil {
+		c.JSON(rescheduleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, proposal.ToDTO())
+}
+
+// parseRescheduleOptions converts the RFC3339 windows of a request into options.
+func parseRescheduleOptions(reqs []rescheduleOptionRequest) ([]models.RescheduleOption, error) {
+	options := make([]models.RescheduleOption, 0, len(reqs))
+	for _, req := range reqs {
+		start, err := time.Parse(time.RFC3339, req.StartTime)
+		if err != nil {
+			return nil, errors.New("invalid start_time")
+		}
+		end, err := time.Parse(time.RFC3339, req.EndTime)
+		if err != nil {
+			return nil, errors.New("invalid end_time")
+		}
+		options = append(options, models.RescheduleOption{StartTime: start, EndTime: end})
+	}
+	return options, nil
+}
+
+// rescheduleErrorStatus maps reschedule and lesson scheduling errors to HTTP status codes.
+func rescheduleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotProposalCounterpart):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrProposalPending), errors.Is(err, services.ErrProposalNotPending):
+		return http.StatusConflict
+	default:
+		return lessonErrorStatus(err, http.StatusBadRequest)
+	}
+}
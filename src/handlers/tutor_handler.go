@@ -448,8 +448,29 @@ func (h *TutorHandler) GetAvailability(c *gin.Context) {
 	}
 	fmt.Printf("\n")
 
-	// 3. Filter/split availability slots that overlap with lessons
-	finalAvailability := filterAvailabilityWithLessons(filteredSlots, lessons)
+	// 3. Get slots held by other students during checkout. The endpoint is public, but a
+	// signed-in viewer still sees the slots they hold themselves.
+	viewerID := uuid.Nil
+	if token, err := getAccessTokenFromCookie(c); err == nil {
+		if viewer, err := h.App.UserService.GetUserFromAccessToken(token); err == nil {
+			viewerID = viewer.ID
+		}
+	}
+	holds, err := h.App.LessonService.GetHeldSlots(tutorID, startDate, endDate.AddDate(0, 0, 1), viewerID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 4. Get the tutor's booking rules (buffers, notice, horizon, daily cap, granularity)
+	rules, err := h.App.BookingRulesService.GetRules(tutorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 5. Filter/split availability slots that overlap with lessons or holds
+	finalAvailability := filterAvailabilityWithLessons(filteredSlots, lessons, holds, rules, now)
 
 	// Log final availability by day
 	fmt.Printf("FINAL AVAILABILITY AFTER CONSIDERING LESSONS (%d slots):\n", len(finalAvailability))
@@ -482,13 +503,41 @@ func (h *TutorHandler) GetAvailability(c *gin.Context) {
 	})
 }
 
-// filterAvailabilityWithLessons adjusts availability slots by removing or splitting them
-// when they overlap with scheduled lessons
-func filterAvailabilityWithLessons(slots []models.AvailabilitySlot, lessons []models.Lesson) []models.AvailabilitySlot {
-	if len(lessons) == 0 {
-		fmt.Println("No lessons to filter against, returning all slots")
-		return slots
+// lessonsOnDay counts the lessons occupying a slot on the calendar day of t, in t's
+// location, the one the availability slots are built in.
+func lessonsOnDay(lessons []models.Lesson, t time.Time) int {
+	year, month, day := t.Date()
+	count := 0
+	for _, lesson := range lessons {
+		y, m, d := lesson.StartTime.In(t.Location()).Date()
+		if lessonOccupiesSlot(lesson) && y == year && m == month && d == day {
+			count++
+		}
 	}
+	return count
+}
+
+// filterAvailabilityWithLessons adjusts availability slots by removing or splitting them
+// when they overlap with scheduled lessons or with slots held by other students, and
+// applies the tutor's booking rules as of now: the buffer around lessons, the minimum
+// notice, the booking horizon, the daily lesson cap and the slot start granularity
+func filterAvailabilityWithLessons(slots []models.AvailabilitySlot, lessons []models.Lesson, holds []models.SlotHold, rules models.TutorBookingRules, now time.Time) []models.AvailabilitySlot {
+	// Lessons count towards the daily cap; holds don't until booked.
+	booked := lessons
+
+	// A held slot is hidden exactly like one taken by a lesson.
+	for _, hold := range holds {
+		lessons = append(lessons, models.Lesson{
+			ID:        hold.ID,
+			StartTime: hold.StartTime,
+			EndTime:   hold.EndTime,
+			Status:    models.LessonStatusScheduled,
+		})
+	}
+
+	buffer := rules.Buffer()
+	earliestStart := rules.EarliestStart(now)
+	startsBefore, horizonLimited := rules.StartsBefore(now)
 
 	var finalSlots []models.AvailabilitySlot
 
@@ -526,6 +575,13 @@ func filterAvailabilityWithLessons(slots []models.AvailabilitySlot, lessons []mo
 
 		fmt.Printf("Processing slot: %s %s-%s\n", slotDate.Format("2006-01-02"), slot.StartTime, slot.EndTime)
 
+		if horizonLimited && !slotStart.Before(startsBefore) {
+			continue
+		}
+		if rules.MaxLessonsPerDay > 0 && lessonsOnDay(booked, slotStart) >= rules.MaxLessonsPerDay {
+			continue
+		}
+
 		// Start with the full slot
 		availableRanges := []struct {
 			start time.Time
@@ -536,8 +592,7 @@ func filterAvailabilityWithLessons(slots []models.AvailabilitySlot, lessons []mo
 
 		// For each lesson, adjust the available ranges
 		for _, lesson := range lessons {
-			if lesson.Status == models.LessonStatusCancelled {
-				fmt.Printf("- Skipping cancelled lesson: %s\n", lesson.ID)
+			if !lessonOccupiesSlot(lesson) {
 				continue
 			}
 
@@ -547,6 +602,10 @@ func filterAvailabilityWithLessons(slots []models.AvailabilitySlot, lessons []mo
 				lesson.EndTime.Format("2006-01-02 15:04"),
 			)
 
+			// The buffer is kept free around the lesson as well.
+			lessonStart := lesson.StartTime.Add(-buffer)
+			lessonEnd := lesson.EndTime.Add(buffer)
+
 			// Create a new list for ranges after processing this lesson
 			var newRanges []struct {
 				start time.Time
@@ -556,7 +615,7 @@ func filterAvailabilityWithLessons(slots []models.AvailabilitySlot, lessons []mo
 			// Process each existing range against this lesson
 			for _, r := range availableRanges {
 				// No overlap case - keep range as is
-				if lesson.EndTime.Before(r.start) || lesson.StartTime.After(r.end) {
+				if lessonEnd.Before(r.start) || lessonStart.After(r.end) {
 					fmt.Printf("  - No overlap with range %s-%s\n",
 						r.start.Format("15:04"), r.end.Format("15:04"))
 					newRanges = append(newRanges, r)
@@ -568,22 +627,22 @@ func filterAvailabilityWithLessons(slots []models.AvailabilitySlot, lessons []mo
 					r.start.Format("15:04"), r.end.Format("15:04"))
 
 				// Part before lesson
-				if r.start.Before(lesson.StartTime) {
+				if r.start.Before(lessonStart) {
 					newRange := struct {
 						start time.Time
 						end   time.Time
-					}{r.start, lesson.StartTime}
+					}{r.start, lessonStart}
 					fmt.Printf("  - Adding range before lesson: %s-%s\n",
 						newRange.start.Format("15:04"), newRange.end.Format("15:04"))
 					newRanges = append(newRanges, newRange)
 				}
 
 				// Part after lesson
-				if r.end.After(lesson.EndTime) {
+				if r.end.After(lessonEnd) {
 					newRange := struct {
 						start time.Time
 						end   time.Time
-					}{lesson.EndTime, r.end}
+					}{lessonEnd, r.end}
 					fmt.Printf("  - Adding range after lesson: %s-%s\n",
 						newRange.start.Format("15:04"), newRange.end.Format("15:04"))
 					newRanges = append(newRanges, newRange)
@@ -602,6 +661,12 @@ func filterAvailabilityWithLessons(slots []models.AvailabilitySlot, lessons []mo
 
 		// Convert remaining time ranges back to AvailabilitySlot format
 		for _, r := range availableRanges {
+			// Respect the minimum notice and let lessons start only on the tutor's granularity
+			if r.start.Before(earliestStart) {
+				r.start = earliestStart
+			}
+			r.start = rules.AlignStart(r.start)
+
 			// Only add slots that are at least 15 minutes long (to avoid tiny gaps)
 			minDuration := 15 * time.Minute
 			if r.end.Sub(r.start) < minDuration {
@@ -649,3 +714,176 @@ func (h *LessonHandler) GetStudentsForTutor(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dtos)
 }
+
+// GetCancellationPolicy returns the tutor's cancellation policy, or the default one.
+func (h *TutorHandler) GetCancellationPolicy(c *gin.Context) {
+	tutorID, err := uuid.Parse(c.Param("tutorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+		return
+	}
+
+	policy, err := h.App.CancellationPolicyService.GetPolicy(tutorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+type cancellationPolicyRequest struct {
+	FreeCancellationHours      *int     `json:"free_cancellation_hours" binding:"required"`
+	LateCancellationFeePercent *float64 `json:"late_cancellation_fee_percent" binding:"required"`
+}
+
+// UpdateCancellationPolicy sets the tutor's cancellation policy. Only the tutor
+// themselves or an admin may change it.
+func (h *TutorHandler) UpdateCancellationPolicy(c *gin.Context) {
+	tutorID, err := uuid.Parse(c.Param("tutorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	if currentUser.ID != tutorID && currentUser.Role != models.UserRoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you can only change your own cancellation policy"})
+		return
+	}
+
+	var req cancellationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "free_cancellation_hours and late_cancellation_fee_percent are required"})
+		return
+	}
+
+	policy, err := h.App.CancellationPolicyService.UpdatePolicy(tutorID, *req.FreeCancellationHours, *req.LateCancellationFeePercent)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// GetTutorStats returns the tutor's lesson statistics, including trial conversion.
+func (h *TutorHandler) GetTutorStats(c *gin.Context) {
+	tutorID, err := uuid.Parse(c.Param("tutorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+		return
+	}
+
+	stats, err := h.App.LessonService.GetTutorStats(tutorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetTrialEligibility tells the current user whether they may book a trial lesson with the tutor.
+func (h *TutorHandler) GetTrialEligibility(c *gin.Context) {
+	tutorID, err := uuid.Parse(c.Param("tutorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	eligible, err := h.App.LessonService.IsTrialEligible(tutorID, currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tutor_id": tutorID, "eligible": eligible && currentUser.ID != tutorID})
+}
+
+// GetBookingRules returns the tutor's booking rules, or the default ones.
+func (h *TutorHandler) GetBookingRules(c *gin.Context) {
+	tutorID, err := uuid.Parse(c.Param("tutorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+		return
+	}
+
+	rules, err := h.App.BookingRulesService.GetRules(tutorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+type bookingRulesRequest struct {
+	BufferMinutes          *int `json:"buffer_minutes" binding:"required"`
+	MinNoticeHours         *int `json:"min_notice_hours" binding:"required"`
+	MaxDaysAhead           *int `json:"max_days_ahead" binding:"required"`      // 0 for no limit
+	MaxLessonsPerDay       *int `json:"max_lessons_per_day" binding:"required"` // 0 for no limit
+	SlotGranularityMinutes *int `json:"slot_granularity_minutes" binding:"required"`
+}
+
+// UpdateBookingRules sets the tutor's booking rules. Only the tutor themselves or an
+// admin may change them.
+func (h *TutorHandler) UpdateBookingRules(c *gin.Context) {
+	tutorID, err := uuid.Parse(c.Param("tutorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+		return
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	if currentUser.ID != tutorID && currentUser.Role != models.UserRoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you can only change your own booking rules"})
+		return
+	}
+
+	var req bookingRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "buffer_minutes, min_notice_hours, max_days_ahead, max_lessons_per_day and slot_granularity_minutes are required"})
+		return
+	}
+
+	rules, err := h.App.BookingRulesService.UpdateRules(models.TutorBookingRules{
+		TutorID:                tutorID,
+		BufferMinutes:          *req.BufferMinutes,
+		MinNoticeHours:         *req.MinNoticeHours,
+		MaxDaysAhead:           *req.MaxDaysAhead,
+		MaxLessonsPerDay:       *req.MaxLessonsPerDay,
+		SlotGranularityMinutes: *req.SlotGranularityMinutes,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// lessonOccupiesSlot reports whether the lesson still blocks its time slot, matching the
+// statuses the server checks when booking.
+func lessonOccupiesSlot(lesson models.Lesson) bool {
+	for _, status := range models.LessonOccupyingStatuses {
+		if lesson.Status == status {
+			return true
+		}
+	}
+	return false
+}
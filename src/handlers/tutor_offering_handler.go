@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/models"
+)
+
+// TutorOfferingHandler handles the lesson offerings tutors publish.
+type TutorOfferingHandler struct {
+	App *app.Application
+}
+
+// NewTutorOfferingHandler creates a new TutorOfferingHandler.
+func NewTutorOfferingHandler(app *app.Application) *TutorOfferingHandler {
+	return &TutorOfferingHandler{App: app}
+}
+
+type tutorOfferingRequest struct {
+	Title           string   `json:"title" binding:"required"`
+	Description     string   `json:"description"`
+	DurationMinutes int      `json:"duration_minutes" binding:"required"`
+	Price           float64  `json:"price"`        // per student
+	MaxStudents     int      `json:"max_students"` // above 1 for group lessons
+	IsTrial         bool     `json:"is_trial"`     // a first lesson for new students, may be free
+	Subjects        []string `json:"subjects"`
+	Levels          []string `json:"levels"`
+}
+
+func (req tutorOfferingRequest) toOffering(tutorID uuid.UUID) models.TutorOffering {
+	return models.TutorOffering{
+		TutorID:         tutorID,
+		Title:           req.Title,
+		Description:     req.Description,
+		DurationMinutes: req.DurationMinutes,
+		Price:           req.Price,
+		MaxStudents:     req.MaxStudents,
+		IsTrial:         req.IsTrial,
+		Subjects:        req.Subjects,
+		Levels:          req.Levels,
+	}
+}
+
+// GetOfferings lists the tutor's offerings. Archived ones are included with ?archived=true.
+func (h *TutorOfferingHandler) GetOfferings(c *gin.Context) {
+	tutorID, err := uuid.Parse(c.Param("tutorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+		return
+	}
+
+	offerings, err := h.App.TutorOfferingService.GetOfferings(tutorID, c.Query("archived") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, offerings)
+}
+
+// CreateOffering publishes a new offering. Only the tutor themselves or an admin may do it.
+func (h *TutorOfferingHandler) CreateOffering(c *gin.Context) {
+	tutorID, ok := h.authorizeTutor(c)
+	if !ok {
+		return
+	}
+
+	var req tutorOfferingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title and duration_minutes are required"})
+		return
+	}
+
+	offering, err := h.App.TutorOfferingService.CreateOffering(req.toOffering(tutorID))
+	if err != nil {
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, offering)
+}
+
+// UpdateOffering replaces an offering's details. Already booked lessons keep their price.
+func (h *TutorOfferingHandler) UpdateOffering(c *gin.Context) {
+	tutorID, ok := h.authorizeTutor(c)
+	if !ok {
+		return
+	}
+	offeringID, err := uuid.Parse(c.Param("offeringID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offering ID"})
+		return
+	}
+
+	var req tutorOfferingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title and duration_minutes are required"})
+		return
+	}
+
+	offering := req.toOffering(tutorID)
+	offering.ID = offeringID
+	updated, err := h.App.TutorOfferingService.UpdateOffering(offering)
+	if err != nil {
+		c.JSON(lessonErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// ArchiveOffering stops an offering from being booked.
+func (h *TutorOfferingHandler) ArchiveOffering(c *gin.Context) {
+	tutorID, ok := h.authorizeTutor(c)
+	if !ok {
+		return
+	}
+	offeringID, err := uuid.Parse(c.Param("offeringID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offering ID"})
+		return
+	}
+
+	offering, err := h.App.TutorOfferingService.ArchiveOffering(tutorID, offeringID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, offering)
+}
+
+// authorizeTutor parses the tutor ID and checks that the current user is that tutor or an admin.
+func (h *TutorOfferingHandler) authorizeTutor(c *gin.Context) (uuid.UUID, bool) {
+	tutorID, err := uuid.Parse(c.Param("tutorID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tutor ID"})
+		return uuid.Nil, false
+	}
+
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	if currentUser.ID != tutorID && currentUser.Role != models.UserRoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you can only manage your own offerings"})
+		return uuid.Nil, false
+	}
+	return tutorID, true
+}
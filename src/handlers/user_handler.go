@@ -171,6 +171,10 @@ func (h *UserHandler) ExtractJWTMiddleware() gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid token"})
 			return
 		}
+		if user.IsSuspended() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Account suspended"})
+			return
+		}
 
 		c.Set("user", user)
 		c.Next()
@@ -208,6 +212,19 @@ func getAccessTokenFromCookie(c *gin.Context) (string, error) {
 	return cookie, nil
 }
 
+// getCurrentUser returns the authenticated user stored in the context by ExtractJWTMiddleware.
+func getCurrentUser(c *gin.Context) (models.User, error) {
+	userVal, exists := c.Get("user")
+	if !exists {
+		return models.User{}, errors.New("user not found in context")
+	}
+	user, ok := userVal.(models.User)
+	if !ok {
+		return models.User{}, errors.New("failed to parse user")
+	}
+	return user, nil
+}
+
 func getRefreshTokenFromCookie(c *gin.Context) (string, error) {
 	cookie, err := c.Cookie("refreshToken")
 	if err != nil {
@@ -248,6 +265,10 @@ func (h *UserHandler) RefreshTokens(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid token"})
 		return
 	}
+	if user.IsSuspended() {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Account suspended"})
+		return
+	}
 	newRefreshToken, err := h.App.AuthService.GenerateRefreshToken(user)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Failed to generate token"})
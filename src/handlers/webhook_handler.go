@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vibely-backend/src/app"
+	"vibely-backend/src/models"
+	"vibely-backend/src/services"
+)
+
+// WebhookHandler handles the current user's webhook subscriptions and their delivery log.
+type WebhookHandler struct {
+	App *app.Application
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(app *app.Application) *WebhookHandler {
+	return &WebhookHandler{App: app}
+}
+
+type webhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"` // empty for all webhook events
+	Active      *bool    `json:"active"`      // defaults to true
+}
+
+// createdWebhookResponse is a new subscription along with its secret, which is only
+// shown this once.
+type createdWebhookResponse struct {
+	models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// GetWebhooks lists the current user's webhook subscriptions.
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	subscriptions, err := h.App.WebhookService.GetSubscriptions(currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if subscriptions == nil {
+		subscriptions = []models.WebhookSubscription{}
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// CreateWebhook subscribes an endpoint of the current user to webhook events.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	subscription, err := h.App.WebhookService.CreateSubscription(req.toSubscription(currentUser.ID))
+	if err != nil {
+		c.JSON(webhookErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdWebhookResponse{WebhookSubscription: subscription, Secret: subscription.Secret})
+}
+
+// UpdateWebhook replaces one of the current user's webhook subscriptions, keeping its secret.
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return
+	}
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	subscription := req.toSubscription(currentUser.ID)
+	subscription.ID = webhookID
+	updated, err := h.App.WebhookService.UpdateSubscription(subscription)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteWebhook deletes one of the current user's webhook subscriptions.
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return
+	}
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.App.WebhookService.DeleteSubscription(webhookID, currentUser.ID); err != nil {
+		c.JSON(webhookErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries handles GET /api/webhooks/:webhookID/deliveries?page=...&limit=...
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return
+	}
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	deliveries, total, err := h.App.WebhookService.GetDeliveries(webhookID, currentUser.ID, page, limit)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"page":       page,
+		"limit":      limit,
+		"total":      total,
+	})
+}
+
+// RedeliverDelivery sends a logged delivery of one of the current user's webhooks again.
+func (h *WebhookHandler) RedeliverDelivery(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("deliveryID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery ID"})
+		return
+	}
+	currentUser, err := getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	delivery, err := h.App.WebhookService.Redeliver(webhookID, deliveryID, currentUser.ID)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func (r webhookRequest) toSubscription(userID uuid.UUID) models.WebhookSubscription {
+	return models.WebhookSubscription{
+		UserID:      userID,
+		URL:         r.URL,
+		Description: r.Description,
+		EventTypes:  r.EventTypes,
+		Active:      r.Active == nil || *r.Active,
+	}
+}
+
+// webhookErrorStatus maps webhook service errors to HTTP statuses.
+func webhookErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound), errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrWebhookInactive), errors.Is(err, services.ErrTooManyWebhooks):
+		return http.StatusConflict
+	default:
+		return fallback
+	}
+}
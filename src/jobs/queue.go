@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+const (
+	// queueBatchSize caps how many jobs one RunDue call claims.
+	queueBatchSize = 50
+	// queueLockTimeout is how long a claimed job may run before it is considered
+	// abandoned and claimed again.
+	queueLockTimeout = 5 * time.Minute
+	// queueMaxAttempts is how many times a failing job runs before it is given up.
+	queueMaxAttempts = 5
+)
+
+// Handler runs a queued job of one kind, given its JSON payload. A returned error makes
+// the job run again later, so handlers must be safe to repeat.
+type Handler func(payload []byte) error
+
+// QueueRunner executes the jobs of the durable job queue once they are due. Jobs are
+// stored in the database, so they survive restarts, and each due job is claimed by a
+// single runner. Should a runner stop in the middle of a job, the job is run again
+// once its lock times out.
+type QueueRunner struct {
+	repo     repositories.JobRepository
+	handlers map[string]Handler
+}
+
+// NewQueueRunner creates a QueueRunner for the jobs in repo.
+func NewQueueRunner(repo repositories.JobRepository) *QueueRunner {
+	return &QueueRunner{repo: repo, handlers: make(map[string]Handler)}
+}
+
+// Handle sets the handler of the jobs of the given kind. It must be called before the
+// runner is started.
+func (q *QueueRunner) Handle(kind string, handler Handler) {
+	q.handlers[kind] = handler
+}
+
+// RunDue runs the jobs that are due and returns how many succeeded. Failed jobs are
+// retried with a growing delay, up to queueMaxAttempts times.
+func (q *QueueRunner) RunDue() (int, error) {
+	now := time.Now()
+	jobs, err := q.repo.ClaimDueJobs(now, now.Add(queueLockTimeout), queueBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	succeeded := 0
+	for i := range jobs {
+		job := &jobs[i]
+		err := q.run(job)
+		switch {
+		case err == nil:
+			succeeded++
+			err = q.repo.FinishJob(job, models.QueuedJobDone, "")
+		case job.Attempts >= queueMaxAttempts:
+			log.Printf("Queued job %s (%s) failed for good: %v", job.Key, job.Kind, err)
+			err = q.repo.FinishJob(job, models.QueuedJobFailed, err.Error())
+		default:
+			log.Printf("Queued job %s (%s) failed, retrying: %v", job.Key, job.Kind, err)
+			retryAt := time.Now().Add(time.Duration(job.Attempts*job.Attempts) * time.Minute)
+			err = q.repo.RetryJob(job, retryAt, err.Error())
+		}
+		if err != nil {
+			return succeeded, err
+		}
+	}
+	return succeeded, nil
+}
+
+// run executes one job, turning a panic into an error.
+func (q *QueueRunner) run(job *models.QueuedJob) (err error) {
+	handler, ok := q.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for job kind %q", job.Kind)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler([]byte(job.Payload))
+}
+
+// PurgeFinished deletes the jobs that finished more than olderThan ago.
+func (q *QueueRunner) PurgeFinished(olderThan time.Duration) (int64, error) {
+	return q.repo.DeleteFinishedJobs(time.Now().Add(-olderThan))
+}
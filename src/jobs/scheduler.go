@@ -0,0 +1,115 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Job is a unit of periodic background work.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+// Scheduler runs periodic jobs inside the server process.
+// Every run takes a Postgres advisory lock named after the job, so when several
+// replicas are running only one of them executes a given job at a time. Jobs must
+// therefore be idempotent: a skipped run is simply picked up by the next tick.
+type Scheduler struct {
+	db   *gorm.DB
+	jobs []Job
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates a Scheduler using db for its locks.
+func NewScheduler(db *gorm.DB) *Scheduler {
+	return &Scheduler{
+		db:   db,
+		stop: make(chan struct{}),
+	}
+}
+
+// Register adds a job. It must be called before Start.
+func (s *Scheduler) Register(name string, interval time.Duration, run func() error) {
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start launches one goroutine per registered job. Each job runs once right away
+// and then on every tick of its interval.
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+
+			for {
+				s.runLocked(job)
+				select {
+				case <-ticker.C:
+				case <-s.stop:
+					return
+				}
+			}
+		}(job)
+	}
+	log.Printf("Scheduler started with %d jobs", len(s.jobs))
+}
+
+// Stop signals all jobs to stop and waits for running ones to finish.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// runLocked executes job while holding its advisory lock. If another replica holds
+// the lock, the run is skipped.
+func (s *Scheduler) runLocked(job Job) {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		log.Printf("Job %s: failed to get database handle: %v", job.Name, err)
+		return
+	}
+
+	// Session-level advisory locks belong to a connection, so pin one for the
+	// duration of the run.
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		log.Printf("Job %s: failed to acquire connection: %v", job.Name, err)
+		return
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", job.Name).Scan(&acquired); err != nil {
+		log.Printf("Job %s: failed to take lock: %v", job.Name, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", job.Name); err != nil {
+			log.Printf("Job %s: failed to release lock: %v", job.Name, err)
+		}
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job %s: panic: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(); err != nil {
+		log.Printf("Job %s: %v", job.Name, err)
+	}
+}
@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type logTransport struct{}
+
+// NewLogTransport creates a Transport that only writes messages to the server log.
+func NewLogTransport() Transport {
+	return logTransport{}
+}
+
+func (logTransport) Send(msg Message) error {
+	log.Printf("email to %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
+
+type fileTransport struct {
+	from string
+	dir  string
+}
+
+// NewFileTransport creates a Transport writing each message to an .eml file in dir, which
+// mail clients open as they would receive it.
+func NewFileTransport(from, dir string) (Transport, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &fileTransport{from: from, dir: dir}, nil
+}
+
+func (t *fileTransport) Send(msg Message) error {
+	data, err := msg.Bytes(t.from)
+	if err != nil {
+		return err
+	}
+	name := time.Now().UTC().Format("20060102-150405.000000000")
+	if msg.ID != "" {
+		name += "-" + msg.ID
+	}
+	return os.WriteFile(filepath.Join(t.dir, name+".eml"), data, 0o644)
+}
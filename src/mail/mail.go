@@ -0,0 +1,65 @@
+// Package mail renders the platform's emails from templates and hands them to a
+// transport: an SMTP server in production, the log or a directory of .eml files during
+// development.
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+)
+
+// Transport names accepted by NewTransport.
+const (
+	TransportSMTP = "smtp"
+	TransportFile = "file"
+	TransportLog  = "log"
+)
+
+// Transport delivers rendered messages.
+type Transport interface {
+	Send(msg Message) error
+}
+
+// SMTPSettings configure the SMTP transport.
+type SMTPSettings struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// NewTransport returns the transport with the given name, sending from the given address.
+// The file transport writes messages to dir.
+func NewTransport(name, from string, smtp SMTPSettings, dir string) (Transport, error) {
+	switch name {
+	case TransportSMTP:
+		return NewSMTPTransport(from, smtp)
+	case TransportFile:
+		return NewFileTransport(from, dir)
+	case TransportLog:
+		return NewLogTransport(), nil
+	}
+	return nil, fmt.Errorf("unknown mail transport %q", name)
+}
+
+// IsRejected tells whether the mail server refused the recipient's address, because the
+// mailbox doesn't exist or can't be delivered to (SMTP replies 550, 551 and 553). Other
+// permanent failures, such as a refused login, are not the address's fault.
+func IsRejected(err error) bool {
+	var smtpErr *textproto.Error
+	if !errors.As(err, &smtpErr) {
+		return false
+	}
+	switch smtpErr.Code {
+	case 550, 551, 553:
+		return true
+	}
+	return false
+}
+
+// IsTransient tells whether the mail server asked to try a send again later (SMTP 4xx replies).
+func IsTransient(err error) bool {
+	var smtpErr *textproto.Error
+	return errors.As(err, &smtpErr) && smtpErr.Code >= 400 && smtpErr.Code < 500
+}
@@ -0,0 +1,55 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+)
+
+type smtpTransport struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPTransport creates a Transport sending through an SMTP server. The connection is
+// upgraded with STARTTLS when the server offers it; credentials are only sent over TLS
+// or to localhost.
+func NewSMTPTransport(from string, settings SMTPSettings) (Transport, error) {
+	if settings.Host == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+	if _, err := netmail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	port := settings.Port
+	if port == 0 {
+		port = 587
+	}
+
+	var auth smtp.Auth
+	if settings.Username != "" {
+		auth = smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)
+	}
+	return &smtpTransport{
+		addr: net.JoinHostPort(settings.Host, strconv.Itoa(port)),
+		auth: auth,
+		from: from,
+	}, nil
+}
+
+func (t *smtpTransport) Send(msg Message) error {
+	data, err := msg.Bytes(t.from)
+	if err != nil {
+		return err
+	}
+	sender, _ := netmail.ParseAddress(t.from)
+	recipient, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(t.addr, t.auth, sender.Address, []string{recipient.Address}, data)
+}
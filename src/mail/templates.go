@@ -0,0 +1,131 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Languages emails are written in.
+const (
+	LanguagePolish  = "pl"
+	LanguageEnglish = "en"
+)
+
+// Languages lists the supported languages.
+var Languages = []string{LanguagePolish, LanguageEnglish}
+
+// IsLanguage tells whether emails can be written in the given language.
+func IsLanguage(language string) bool {
+	return slices.Contains(Languages, language)
+}
+
+// Template names
+const (
+	// TemplateNotification carries a notification from the notification center, with a
+	// NotificationData.
+	TemplateNotification = "notification"
+)
+
+// NotificationData is the content of a TemplateNotification email.
+type NotificationData struct {
+	Title string
+	Body  string
+}
+
+// TemplateData is what templates are executed with.
+type TemplateData struct {
+	// Content is the template's own data, e.g. a NotificationData.
+	Content interface{}
+	// AppURL links to the web app.
+	AppURL string
+	// UnsubscribeURL is the page turning off the recipient's email notifications, empty
+	// for emails that must be sent regardless.
+	UnsubscribeURL string
+}
+
+// templatesFS holds templates/<language>/<name>.txt and <name>.html for every email,
+// along with each language's layout.txt and layout.html. A text template defines the
+// "subject" and "text" templates, an HTML one the "content" of the "html" layout.
+//
+//go:embed templates
+var templatesFS embed.FS
+
+// Templates renders the emails of every language.
+type Templates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// LoadTemplates parses the embedded templates. Every email must exist in all Languages.
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+	var names []string
+	for _, language := range Languages {
+		files, err := fs.Glob(templatesFS, path.Join("templates", language, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			name := strings.TrimSuffix(path.Base(file), ".txt")
+			if name != "layout" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	for _, language := range Languages {
+		dir := path.Join("templates", language)
+		for _, name := range names {
+			text, err := texttemplate.ParseFS(templatesFS, path.Join(dir, "layout.txt"), path.Join(dir, name+".txt"))
+			if err != nil {
+				return nil, fmt.Errorf("email %s (%s): %w", name, language, err)
+			}
+			html, err := htmltemplate.ParseFS(templatesFS, path.Join(dir, "layout.html"), path.Join(dir, name+".html"))
+			if err != nil {
+				return nil, fmt.Errorf("email %s (%s): %w", name, language, err)
+			}
+			t.text[key(language, name)] = text
+			t.html[key(language, name)] = html
+		}
+	}
+	return t, nil
+}
+
+// Render renders an email in the given language, returning its subject, text and HTML body.
+func (t *Templates) Render(name, language string, data TemplateData) (subject, text, html string, err error) {
+	textTemplate, ok := t.text[key(language, name)]
+	if !ok {
+		return "", "", "", fmt.Errorf("no %s email in language %q", name, language)
+	}
+	var buf bytes.Buffer
+	if err := textTemplate.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", "", err
+	}
+	subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err := textTemplate.ExecuteTemplate(&buf, "text", data); err != nil {
+		return "", "", "", err
+	}
+	text = strings.TrimSpace(buf.String()) + "\n"
+
+	buf.Reset()
+	if err := t.html[key(language, name)].ExecuteTemplate(&buf, "html", data); err != nil {
+		return "", "", "", err
+	}
+	return subject, text, buf.String(), nil
+}
+
+func key(language, name string) string {
+	return language + "/" + name
+}
@@ -0,0 +1,33 @@
+package meeting
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// DefaultJitsiURL is the public Jitsi Meet server.
+const DefaultJitsiURL = "https://meet.jit.si"
+
+type jitsiProvider struct {
+	baseURL string
+}
+
+// NewJitsiProvider creates a Provider for the Jitsi Meet server at baseURL, or the public
+// server when baseURL is empty. Jitsi creates rooms when someone first joins them, so the
+// provider only generates room names that can't be guessed.
+func NewJitsiProvider(baseURL string) Provider {
+	if baseURL == "" {
+		baseURL = DefaultJitsiURL
+	}
+	return &jitsiProvider{baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (p *jitsiProvider) CreateMeeting(req Request) (Meeting, error) {
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return Meeting{}, err
+	}
+	room := "vibely-" + hex.EncodeToString(secret)
+	return Meeting{ID: room, URL: p.baseURL + "/" + room}, nil
+}
@@ -0,0 +1,52 @@
+// Package meeting creates the online meeting rooms lessons are held in. Providers are
+// pluggable; rooms are generated by Jitsi for now.
+package meeting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Provider names accepted by NewProvider.
+const (
+	ProviderJitsi = "jitsi"
+	// ProviderNone disables meeting links.
+	ProviderNone = "none"
+)
+
+// Request describes the lesson a meeting is created for.
+type Request struct {
+	LessonID  uuid.UUID
+	Title     string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// Meeting is a created meeting room.
+type Meeting struct {
+	// ID identifies the meeting at its provider.
+	ID string
+	// URL is the link participants join the meeting with.
+	URL string
+}
+
+// Provider creates meeting rooms.
+type Provider interface {
+	// CreateMeeting creates a new meeting room. Every call returns a different room, so a
+	// lesson moved to another time gets a link its old participants' invites don't open.
+	CreateMeeting(req Request) (Meeting, error)
+}
+
+// NewProvider returns the provider with the given name, configured with its base URL. It
+// returns nil for ProviderNone.
+func NewProvider(name, baseURL string) (Provider, error) {
+	switch name {
+	case ProviderJitsi:
+		return NewJitsiProvider(baseURL), nil
+	case ProviderNone:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown meeting provider %q", name)
+}
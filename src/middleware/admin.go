@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vibely-backend/src/models"
+)
+
+// RequireAdmin only lets through users with the admin role.
+// It must run after the JWT middleware, which stores the user in the context.
+func RequireAdmin(c *gin.Context) {
+	userVal, exists := c.Get("user")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return
+	}
+
+	user, ok := userVal.(models.User)
+	if !ok || user.Role != models.UserRoleAdmin {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Forbidden"})
+		return
+	}
+
+	c.Next()
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attendance constants, recorded per student by the tutor. An empty attendance means
+// it has not been recorded yet.
+const (
+	AttendancePresent = "present"
+	AttendanceLate    = "late"
+	AttendanceAbsent  = "absent"
+	AttendanceExcused = "excused"
+)
+
+// IsValidAttendance reports whether s is one of the attendance constants.
+func IsValidAttendance(s string) bool {
+	switch s {
+	case AttendancePresent, AttendanceLate, AttendanceAbsent, AttendanceExcused:
+		return true
+	}
+	return false
+}
+
+// LessonStudent is the lesson_students row linking a student to a lesson. Besides the
+// enrollment itself it holds the student's attendance, since in a group lesson the
+// lesson status alone does not tell who showed up.
+type LessonStudent struct {
+	LessonID uuid.UUID `gorm:"type:uuid;primaryKey" json:"lesson_id"`
+	UserID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+
+	Attendance           string     `gorm:"type:varchar(20);not null;default:''" json:"attendance"`
+	AttendanceNote       string     `json:"attendance_note,omitempty"`
+	AttendanceRecordedAt *time.Time `json:"attendance_recorded_at,omitempty"`
+}
+
+// TableName keeps the join table name GORM uses for Lesson.Students.
+func (LessonStudent) TableName() string {
+	return "lesson_students"
+}
+
+// AttendanceDTO is a student's attendance as returned via API.
+type AttendanceDTO struct {
+	StudentID  uuid.UUID  `json:"student_id"`
+	Status     string     `json:"status"` // empty until recorded
+	Note       string     `json:"note,omitempty"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+}
+
+// ToDTO converts a LessonStudent to an AttendanceDTO.
+func (ls LessonStudent) ToDTO() AttendanceDTO {
+	return AttendanceDTO{
+		StudentID:  ls.UserID,
+		Status:     ls.Attendance,
+		Note:       ls.AttendanceNote,
+		RecordedAt: ls.AttendanceRecordedAt,
+	}
+}
+
+// AttendanceCount is the number of lessons a student has with a given attendance.
+type AttendanceCount struct {
+	UserID     uuid.UUID
+	Attendance string
+	Lessons    int
+}
+
+// AttendanceSummary counts a student's lessons by attendance.
+type AttendanceSummary struct {
+	Present    int `json:"present"`
+	Late       int `json:"late"`
+	Absent     int `json:"absent"`
+	Excused    int `json:"excused"`
+	Unrecorded int `json:"unrecorded"`
+}
+
+// Add counts lessons with the given attendance.
+func (s *AttendanceSummary) Add(attendance string, lessons int) {
+	switch attendance {
+	case AttendancePresent:
+		s.Present += lessons
+	case AttendanceLate:
+		s.Late += lessons
+	case AttendanceAbsent:
+		s.Absent += lessons
+	case AttendanceExcused:
+		s.Excused += lessons
+	default:
+		s.Unrecorded += lessons
+	}
+}
+
+// Attended is the number of lessons the student showed up to, late or not.
+func (s AttendanceSummary) Attended() int {
+	return s.Present + s.Late
+}
+
+// Rate is the share of lessons with recorded attendance that the student showed up to.
+// Excused absences do not count against it.
+func (s AttendanceSummary) Rate() float64 {
+	counted := s.Present + s.Late + s.Absent
+	if counted == 0 {
+		return 0
+	}
+	return float64(s.Attended()) / float64(counted)
+}
+
+// StudentStats summarizes a student's lessons.
+type StudentStats struct {
+	StudentID      uuid.UUID         `json:"student_id"`
+	Lessons        int               `json:"lessons"` // lessons that took place
+	Attendance     AttendanceSummary `json:"attendance"`
+	AttendanceRate float64           `json:"attendance_rate"`
+}
+
+// CourseProgress summarizes how far a course and each of its students have got.
+type CourseProgress struct {
+	CourseID         uuid.UUID               `json:"course_id"`
+	TotalLessons     int                     `json:"total_lessons"`
+	CompletedLessons int                     `json:"completed_lessons"`
+	Students         []StudentCourseProgress `json:"students"`
+}
+
+// StudentCourseProgress is a student's attendance in the completed lessons of a course.
+// Progress is the share of all the course's lessons the student attended.
+type StudentCourseProgress struct {
+	Student        StudentDTO        `json:"student"`
+	Attendance     AttendanceSummary `json:"attendance"`
+	AttendanceRate float64           `json:"attendance_rate"`
+	Progress       float64           `json:"progress"`
+}
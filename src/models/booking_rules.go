@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Defaults used for tutors who have not set booking rules: lessons can be booked back to
+// back, at any notice and any time ahead, starting on a quarter hour.
+const (
+	DefaultBufferMinutes          = 0
+	DefaultMinNoticeHours         = 0
+	DefaultMaxDaysAhead           = 0
+	DefaultMaxLessonsPerDay       = 0
+	DefaultSlotGranularityMinutes = 15
+)
+
+// TutorBookingRules limit when students may book a tutor. They are honored both by the
+// availability shown to students and when a student books or holds a slot.
+type TutorBookingRules struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	TutorID uuid.UUID `json:"tutor_id" gorm:"type:uuid;not null;uniqueIndex"`
+
+	// Free time kept before and after every lesson.
+	BufferMinutes int `json:"buffer_minutes" gorm:"not null"`
+	// How long before its start a lesson must be booked.
+	MinNoticeHours int `json:"min_notice_hours" gorm:"not null"`
+	// How many days ahead, counting from today, lessons may be booked; zero means no limit.
+	MaxDaysAhead int `json:"max_days_ahead" gorm:"not null"`
+	// How many lessons the tutor takes per day; zero means no limit.
+	MaxLessonsPerDay int `json:"max_lessons_per_day" gorm:"not null"`
+	// Lessons start on multiples of this many minutes after midnight.
+	SlotGranularityMinutes int `json:"slot_granularity_minutes" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// DefaultBookingRules returns the rules applied to a tutor without any.
+func DefaultBookingRules(tutorID uuid.UUID) TutorBookingRules {
+	return TutorBookingRules{
+		TutorID:                tutorID,
+		BufferMinutes:          DefaultBufferMinutes,
+		MinNoticeHours:         DefaultMinNoticeHours,
+		MaxDaysAhead:           DefaultMaxDaysAhead,
+		MaxLessonsPerDay:       DefaultMaxLessonsPerDay,
+		SlotGranularityMinutes: DefaultSlotGranularityMinutes,
+	}
+}
+
+// Buffer returns the free time kept around lessons.
+func (r *TutorBookingRules) Buffer() time.Duration {
+	return time.Duration(r.BufferMinutes) * time.Minute
+}
+
+// EarliestStart returns the earliest time a lesson booked at now may start.
+func (r *TutorBookingRules) EarliestStart(now time.Time) time.Time {
+	return now.Add(time.Duration(r.MinNoticeHours) * time.Hour)
+}
+
+// StartsBefore returns the time lessons booked at now must start before, and false if
+// there is no limit.
+func (r *TutorBookingRules) StartsBefore(now time.Time) (time.Time, bool) {
+	if r.MaxDaysAhead <= 0 {
+		return time.Time{}, false
+	}
+	return time.Date(now.Year(), now.Month(), now.Day()+r.MaxDaysAhead+1, 0, 0, 0, 0, now.Location()), true
+}
+
+// AlignStart rounds t up to the next allowed lesson start.
+func (r *TutorBookingRules) AlignStart(t time.Time) time.Time {
+	step := time.Duration(r.SlotGranularityMinutes) * time.Minute
+	if step <= 0 {
+		return t
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if rest := t.Sub(midnight) % step; rest != 0 {
+		return t.Add(step - rest)
+	}
+	return t
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cancellation reason constants
+const (
+	CancellationReasonScheduleConflict = "schedule_conflict"
+	CancellationReasonIllness          = "illness"
+	CancellationReasonEmergency        = "emergency"
+	CancellationReasonNoLongerNeeded   = "no_longer_needed"
+	CancellationReasonTechnicalIssues  = "technical_issues"
+	CancellationReasonOther            = "other"
+)
+
+// Defaults used for tutors who have not set a cancellation policy: cancelling is free.
+const (
+	DefaultFreeCancellationHours      = 24
+	DefaultLateCancellationFeePercent = 0
+)
+
+// TutorCancellationPolicy defines what a student pays for cancelling a lesson late.
+// Cancelling is free until FreeCancellationHours before the lesson starts; after that
+// LateCancellationFeePercent of the lesson price is charged.
+type TutorCancellationPolicy struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	TutorID uuid.UUID `json:"tutor_id" gorm:"type:uuid;not null;uniqueIndex"`
+
+	FreeCancellationHours      int     `json:"free_cancellation_hours" gorm:"not null"`
+	LateCancellationFeePercent float64 `json:"late_cancellation_fee_percent" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// DefaultCancellationPolicy returns the policy applied to a tutor without one.
+func DefaultCancellationPolicy(tutorID uuid.UUID) TutorCancellationPolicy {
+	return TutorCancellationPolicy{
+		TutorID:                    tutorID,
+		FreeCancellationHours:      DefaultFreeCancellationHours,
+		LateCancellationFeePercent: DefaultLateCancellationFeePercent,
+	}
+}
+
+// LateCancellationFee returns the fee for cancelling a lesson priced at price and
+// starting at start, when cancelled at cancelledAt. The fee is rounded to cents.
+func (p *TutorCancellationPolicy) LateCancellationFee(price float64, start, cancelledAt time.Time) float64 {
+	freeUntil := start.Add(-time.Duration(p.FreeCancellationHours) * time.Hour)
+	if cancelledAt.Before(freeUntil) || price <= 0 || p.LateCancellationFeePercent <= 0 {
+		return 0
+	}
+	fee := price * p.LateCancellationFeePercent / 100
+	return float64(int64(fee*100+0.5)) / 100
+}
+
+// SeatCancellation records a student cancelling their own seat in a lesson that goes on
+// for its other students, and the late cancellation fee they were charged for it.
+type SeatCancellation struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	LessonID  uuid.UUID `json:"lesson_id" gorm:"type:uuid;not null;index"`
+	StudentID uuid.UUID `json:"student_id" gorm:"type:uuid;not null;index"`
+
+	Reason          string  `json:"reason" gorm:"type:varchar(40);not null"`
+	Note            string  `json:"note,omitempty" gorm:"type:text"`
+	CancellationFee float64 `json:"cancellation_fee"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
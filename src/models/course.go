@@ -19,6 +19,9 @@ type Course struct {
 	Level       string    `json:"level"`   // Exposed in the DTO, useful for filtering
 	Lessons     []Lesson  `gorm:"foreignKey:CourseID" json:"lessons"`
 	Students    []User    `gorm:"many2many:course_students" json:"students"`
+	// MaxStudents caps the number of enrolled students; zero means unlimited.
+	// Students enrolling in a full course are put on its waitlist.
+	MaxStudents int `json:"max_students" gorm:"not null;default:0"`
 
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
@@ -36,8 +39,12 @@ type CourseDTO struct {
 	Tutor       TutorDTO     `json:"tutor"`
 	Students    []StudentDTO `json:"students"`
 	Lessons     []LessonDTO  `json:"lessons"`
+	MaxStudents int          `json:"max_students"`
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
+
+	// QuizResults are only filled in for the course's tutor.
+	QuizResults []QuizResult `json:"quiz_results,omitempty"`
 }
 
 // ToDTO converts a Course model to a CourseDTO.
@@ -65,6 +72,7 @@ func (c *Course) ToDTO() CourseDTO {
 		Tutor:       tutorDTO,
 		Students:    studentDTOs,
 		Lessons:     lessonDTOs,
+		MaxStudents: c.MaxStudents,
 		CreatedAt:   c.CreatedAt,
 		UpdatedAt:   c.UpdatedAt,
 	}
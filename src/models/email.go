@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outbox email states
+const (
+	OutboxEmailPending = "pending"
+	OutboxEmailSent    = "sent"
+	// OutboxEmailFailed emails could not be sent after retrying.
+	OutboxEmailFailed = "failed"
+	// OutboxEmailBounced emails were rejected by the recipient's mail server.
+	OutboxEmailBounced = "bounced"
+	// OutboxEmailSuppressed emails were not sent because their address bounced before.
+	OutboxEmailSuppressed = "suppressed"
+)
+
+// Bounce kinds. Hard bounces and complaints stop all further email to the address.
+const (
+	EmailBounceHard      = "hard"
+	EmailBounceSoft      = "soft"
+	EmailBounceComplaint = "complaint"
+)
+
+// OutboxEmail is a rendered email waiting to be sent, or the record of one that was.
+// Emails are stored before they are sent, so sends survive restarts.
+type OutboxEmail struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	// UserID is the recipient, if the email went to a user of the platform.
+	UserID   *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	To       string     `gorm:"not null;index" json:"to"`
+	Template string     `gorm:"type:varchar(50);not null" json:"template"`
+	Language string     `gorm:"type:varchar(5);not null" json:"language"`
+	Subject  string     `gorm:"not null" json:"subject"`
+	TextBody string     `gorm:"type:text" json:"-"`
+	HTMLBody string     `gorm:"type:text" json:"-"`
+	// UnsubscribeURL is sent in the List-Unsubscribe headers of emails users may opt out of.
+	UnsubscribeURL string `json:"-"`
+
+	Status    string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	Attempts  int        `gorm:"not null;default:0" json:"attempts"`
+	LastError string     `gorm:"type:text" json:"last_error,omitempty"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EmailBounce records an email rejected by the recipient's mail server, or reported as
+// spam, whether learned while sending or reported later by the mail provider.
+type EmailBounce struct {
+	ID      uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	Address string    `gorm:"not null;index" json:"address"`
+	Kind    string    `gorm:"type:varchar(20);not null" json:"kind"`
+	Reason  string    `gorm:"type:text" json:"reason"`
+	// EmailID is the outbox email that bounced, when known.
+	EmailID   *uuid.UUID `gorm:"type:uuid" json:"email_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// EmailUnsubscribeToken lets a user turn off email notifications from a link in an email,
+// without signing in.
+type EmailUnsubscribeToken struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Token     string    `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time
+}
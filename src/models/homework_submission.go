@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Homework submission status constants
+const (
+	SubmissionStatusSubmitted = "submitted" // waiting for the tutor to grade it
+	SubmissionStatusGraded    = "graded"
+)
+
+// HomeworkSubmission is a student's answer to a homework assignment. A student has at most
+// one submission per assignment; resubmitting replaces its text and files, bumps Attempt
+// and clears any earlier grade.
+type HomeworkSubmission struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	HomeworkID uuid.UUID `json:"homework_id" gorm:"type:uuid;not null;uniqueIndex:idx_submission_homework_student"`
+	StudentID  uuid.UUID `json:"student_id" gorm:"type:uuid;not null;uniqueIndex:idx_submission_homework_student;index"`
+	Student    User      `gorm:"foreignKey:StudentID" json:"-"`
+
+	Text  string           `json:"text" gorm:"type:text"`
+	Files []SubmissionFile `gorm:"foreignKey:SubmissionID" json:"files"`
+
+	Status      string    `json:"status" gorm:"type:varchar(20);not null"`
+	Attempt     int       `json:"attempt" gorm:"not null;default:1"`
+	SubmittedAt time.Time `json:"submitted_at" gorm:"not null"`
+	// Late is set when the latest attempt came in after the homework was due.
+	Late bool `json:"late" gorm:"not null;default:false"`
+
+	// Grade, set by the tutor. Score is out of the homework's MaxScore.
+	Score      *float64   `json:"score,omitempty"`
+	Feedback   string     `json:"feedback,omitempty" gorm:"type:text"`
+	GradedByID *uuid.UUID `json:"graded_by_id,omitempty" gorm:"type:uuid"`
+	GradedAt   *time.Time `json:"graded_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// SubmissionFile is a file attached to a homework submission. The file itself lives in
+// storage under StorageKey.
+type SubmissionFile struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	SubmissionID uuid.UUID `json:"submission_id" gorm:"type:uuid;not null;index"`
+
+	FileName    string `json:"file_name" gorm:"not null"`
+	ContentType string `json:"content_type" gorm:"not null"`
+	Size        int64  `json:"size" gorm:"not null"`
+	StorageKey  string `json:"-" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// HomeworkSubmissionDTO is the shape returned via API.
+type HomeworkSubmissionDTO struct {
+	HomeworkSubmission
+	Student StudentDTO `json:"student"`
+}
+
+// ToDTO converts a HomeworkSubmission to a HomeworkSubmissionDTO.
+func (s HomeworkSubmission) ToDTO() HomeworkSubmissionDTO {
+	if s.Files == nil {
+		s.Files = []SubmissionFile{}
+	}
+	return HomeworkSubmissionDTO{HomeworkSubmission: s, Student: s.Student.ToStudentDTO()}
+}
+
+// StudentHomework is one homework assignment as seen by a student, with their submission.
+type StudentHomework struct {
+	Homework   Homework            `json:"homework"`
+	Submission *HomeworkSubmission `json:"submission,omitempty"`
+	// Overdue is set for homework not submitted yet although it is past due.
+	Overdue bool `json:"overdue"`
+}
+
+// HomeworkOverview sorts a student's homework into what still needs doing, what waits
+// for grading and what has been graded.
+type HomeworkOverview struct {
+	StudentID   uuid.UUID         `json:"student_id"`
+	Outstanding []StudentHomework `json:"outstanding"`
+	Submitted   []StudentHomework `json:"submitted"`
+	Graded      []StudentHomework `json:"graded"`
+}
@@ -14,8 +14,18 @@ const (
 	LessonStatusDone       = "done"
 	LessonStatusFailed     = "failed"
 	LessonStatusCancelled  = "cancelled"
+	LessonStatusExpired    = "expired" // never confirmed before the confirmation deadline
 )
 
+// LessonOccupyingStatuses lists the statuses in which a lesson occupies its time slot
+// for the tutor and its students.
+var LessonOccupyingStatuses = []string{
+	LessonStatusScheduled,
+	LessonStatusConfirmed,
+	LessonStatusInProgress,
+	LessonStatusDone,
+}
+
 // Lesson model
 //   - Single Tutor (TutorID / Tutor field)
 //   - Many Students (Students field via a pivot table)
@@ -27,6 +37,11 @@ type Lesson struct {
 	Tutor   User      `gorm:"foreignKey:TutorID"`
 
 	Students []User `gorm:"many2many:lesson_students" json:"students"`
+	// MaxStudents caps the number of students of a group lesson; zero means unlimited.
+	// Students joining a full lesson are put on its waitlist.
+	MaxStudents int `json:"max_students" gorm:"not null;default:0"`
+	// Attendance holds the lesson_students rows, with each student's attendance.
+	Attendance []LessonStudent `gorm:"foreignKey:LessonID" json:"attendance,omitempty"`
 
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
@@ -36,12 +51,43 @@ type Lesson struct {
 	EndTime     time.Time `json:"end_time"`
 	Status      string    `json:"status"`
 
+	// Price each student pays, snapshotted at booking time; when zero the tutor's
+	// current price applies.
+	Price float64 `json:"price"`
+	// OfferingID is the tutor offering the lesson was booked against, if any.
+	OfferingID *uuid.UUID `json:"offering_id,omitempty" gorm:"type:uuid;index"`
+	// IsTrial marks a student's trial lesson with the tutor; its price is final even when zero.
+	IsTrial bool `json:"is_trial" gorm:"not null;default:false"`
+
+	// Cancellation details, set when the lesson is cancelled. CancellationFee is what
+	// the student owes under the tutor's cancellation policy.
+	CancelledByID      *uuid.UUID `json:"cancelled_by_id,omitempty" gorm:"type:uuid"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	CancellationReason string     `json:"cancellation_reason,omitempty" gorm:"type:varchar(40)"`
+	CancellationNote   string     `json:"cancellation_note,omitempty" gorm:"type:text"`
+	CancellationFee    float64    `json:"cancellation_fee"`
+
+	// A disputed lesson is not completed automatically after EndTime.
+	DisputedAt    *time.Time `json:"disputed_at,omitempty"`
+	DisputeReason string     `json:"dispute_reason,omitempty"`
+
+	// MeetingURL is the online meeting the lesson is held in, created when the lesson is
+	// confirmed and replaced when it is moved. It is only shown to participants shortly
+	// before the lesson starts, see LessonDTO.MeetingURL.
+	MeetingURL string `json:"-"`
+
 	// Optional association with a Course.
 	// CourseID is a pointer so it can be nil when there is no associated course.
 	CourseID *uuid.UUID `json:"course_id,omitempty"`
 	// Course is also optional and omitted from JSON if nil.
 	Course *Course `gorm:"foreignKey:CourseID" json:"course,omitempty"`
 
+	// Optional association with a recurring LessonSeries.
+	// OccurrenceStart is the occurrence this lesson was materialized for; it stays
+	// fixed when only this lesson is moved, so the occurrence is never re-created.
+	SeriesID        *uuid.UUID `json:"series_id,omitempty" gorm:"index"`
+	OccurrenceStart *time.Time `json:"occurrence_start,omitempty"`
+
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -64,12 +110,31 @@ type LessonDTO struct {
 	StartTime   time.Time    `json:"start_time"`
 	EndTime     time.Time    `json:"end_time"`
 	Status      string       `json:"status"`
+	Price       float64      `json:"price"`
+	OfferingID  *uuid.UUID   `json:"offering_id,omitempty"`
+	IsTrial     bool         `json:"is_trial"`
+	DisputedAt  *time.Time   `json:"disputed_at,omitempty"`
 	Tutor       TutorDTO     `json:"tutor"`
 	Students    []StudentDTO `json:"students"`
+	MaxStudents int          `json:"max_students"`
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
 
-	Course *CourseSummaryDTO `json:"course,omitempty"`
+	Attendance []AttendanceDTO `json:"attendance,omitempty"`
+	// Materials are only listed for the lesson's participants.
+	Materials *LessonMaterials `json:"materials,omitempty"`
+	// MeetingURL is only revealed to the lesson's participants, from shortly before the
+	// lesson starts until it ends.
+	MeetingURL string `json:"meeting_url,omitempty"`
+
+	Course   *CourseSummaryDTO `json:"course,omitempty"`
+	SeriesID *uuid.UUID        `json:"series_id,omitempty"`
+
+	CancelledByID      *uuid.UUID `json:"cancelled_by_id,omitempty"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	CancellationReason string     `json:"cancellation_reason,omitempty"`
+	CancellationNote   string     `json:"cancellation_note,omitempty"`
+	CancellationFee    float64    `json:"cancellation_fee"`
 }
 
 // ToDTO converts a Lesson model to LessonDTO.
@@ -84,6 +149,11 @@ func (l Lesson) ToDTO() LessonDTO {
 		students = append(students, s.ToStudentDTO())
 	}
 
+	var attendance []AttendanceDTO
+	for _, a := range l.Attendance {
+		attendance = append(attendance, a.ToDTO())
+	}
+
 	// Prepare CourseSummaryDTO only if CourseID is set, non-nil, and Course data is available.
 	var courseSummary *CourseSummaryDTO
 	if l.CourseID != nil && *l.CourseID != uuid.Nil && l.Course != nil && l.Course.ID != uuid.Nil && l.Course.Name != "" {
@@ -102,10 +172,23 @@ func (l Lesson) ToDTO() LessonDTO {
 		StartTime:   l.StartTime,
 		EndTime:     l.EndTime,
 		Status:      l.Status,
+		Price:       l.Price,
+		OfferingID:  l.OfferingID,
+		IsTrial:     l.IsTrial,
+		DisputedAt:  l.DisputedAt,
 		Tutor:       tutorDTO,
 		Students:    students,
+		MaxStudents: l.MaxStudents,
 		CreatedAt:   l.CreatedAt,
 		UpdatedAt:   l.UpdatedAt,
+		Attendance:  attendance,
 		Course:      courseSummary,
+		SeriesID:    l.SeriesID,
+
+		CancelledByID:      l.CancelledByID,
+		CancelledAt:        l.CancelledAt,
+		CancellationReason: l.CancellationReason,
+		CancellationNote:   l.CancellationNote,
+		CancellationFee:    l.CancellationFee,
 	}
 }
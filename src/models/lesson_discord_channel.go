@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LessonDiscordChannels are the private Discord channels of a confirmed lesson.
+type LessonDiscordChannels struct {
+	LessonID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"lesson_id"`
+	TextChannelID  string    `gorm:"not null" json:"text_channel_id"`
+	VoiceChannelID string    `gorm:"not null" json:"voice_channel_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
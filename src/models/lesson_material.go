@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LessonNote is what the tutor wrote down about a lesson, such as what was covered.
+type LessonNote struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	LessonID uuid.UUID `json:"lesson_id" gorm:"type:uuid;not null;index"`
+	AuthorID uuid.UUID `json:"author_id" gorm:"type:uuid;not null"`
+
+	Body string `json:"body" gorm:"type:text;not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Homework is an assignment the tutor gave the students of a lesson. Students hand it in
+// as a HomeworkSubmission.
+type Homework struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	LessonID uuid.UUID `json:"lesson_id" gorm:"type:uuid;not null;index"`
+
+	Title       string     `json:"title" gorm:"not null"`
+	Description string     `json:"description" gorm:"type:text"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	// MaxScore is the best score a submission can be graded with.
+	MaxScore float64 `json:"max_score" gorm:"not null;default:100"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// LessonAttachment is a file shared with the participants of a lesson, such as a
+// worksheet. The file itself lives in storage under StorageKey.
+type LessonAttachment struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	LessonID   uuid.UUID `json:"lesson_id" gorm:"type:uuid;not null;index"`
+	UploaderID uuid.UUID `json:"uploader_id" gorm:"type:uuid;not null"`
+
+	FileName    string `json:"file_name" gorm:"not null"`
+	ContentType string `json:"content_type" gorm:"not null"`
+	Size        int64  `json:"size" gorm:"not null"`
+	StorageKey  string `json:"-" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// LessonMaterials groups everything shared with the participants of a lesson.
+type LessonMaterials struct {
+	Notes       []LessonNote       `json:"notes"`
+	Homework    []Homework         `json:"homework"`
+	Attachments []LessonAttachment `json:"attachments"`
+}
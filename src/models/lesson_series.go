@@ -0,0 +1,153 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lesson series status constants
+const (
+	LessonSeriesStatusActive    = "active"
+	LessonSeriesStatusEnded     = "ended"
+	LessonSeriesStatusCancelled = "cancelled"
+)
+
+// Scopes for editing or cancelling an occurrence of a series
+const (
+	SeriesScopeThis      = "this"
+	SeriesScopeFollowing = "following"
+	SeriesScopeAll       = "all"
+)
+
+// LessonSeries is a recurring lesson, e.g. "every Monday at 16:00 until June".
+// Individual Lesson rows are materialized from it ahead of time up to MaterializedUntil.
+type LessonSeries struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	TutorID uuid.UUID `json:"tutor_id" gorm:"index;not null"`
+	Tutor   User      `gorm:"foreignKey:TutorID"`
+
+	Students []User `gorm:"many2many:lesson_series_students" json:"students"`
+
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Subject     string `json:"subject"`
+	Level       string `json:"level"`
+
+	// StartTime is the first occurrence (DTSTART); its wall-clock time in Timezone
+	// is kept for every occurrence.
+	StartTime       time.Time `json:"start_time" gorm:"not null"`
+	DurationMinutes int       `json:"duration_minutes" gorm:"not null"`
+	RRule           string    `json:"rrule" gorm:"not null"`
+	Timezone        string    `json:"timezone" gorm:"not null;default:'Europe/Warsaw'"`
+
+	MaterializedUntil time.Time `json:"materialized_until"`
+	Status            string    `json:"status" gorm:"type:varchar(20);not null;default:'active';index"`
+
+	Lessons []Lesson `gorm:"foreignKey:SeriesID" json:"lessons"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// LessonSeriesDTO is the shape of a series returned via API.
+type LessonSeriesDTO struct {
+	ID                uuid.UUID    `json:"id"`
+	Title             string       `json:"title"`
+	Description       string       `json:"description"`
+	Subject           string       `json:"subject"`
+	Level             string       `json:"level"`
+	StartTime         time.Time    `json:"start_time"`
+	DurationMinutes   int          `json:"duration_minutes"`
+	RRule             string       `json:"rrule"`
+	Timezone          string       `json:"timezone"`
+	MaterializedUntil time.Time    `json:"materialized_until"`
+	Status            string       `json:"status"`
+	Tutor             TutorDTO     `json:"tutor"`
+	Students          []StudentDTO `json:"students"`
+	Lessons           []LessonDTO  `json:"lessons"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
+
+// ToDTO converts a LessonSeries model to a LessonSeriesDTO.
+func (s *LessonSeries) ToDTO() LessonSeriesDTO {
+	var students []StudentDTO
+	for _, student := range s.Students {
+		students = append(students, student.ToStudentDTO())
+	}
+
+	var lessons []LessonDTO
+	for _, lesson := range s.Lessons {
+		lessons = append(lessons, lesson.ToDTO())
+	}
+
+	return LessonSeriesDTO{
+		ID:                s.ID,
+		Title:             s.Title,
+		Description:       s.Description,
+		Subject:           s.Subject,
+		Level:             s.Level,
+		StartTime:         s.StartTime,
+		DurationMinutes:   s.DurationMinutes,
+		RRule:             s.RRule,
+		Timezone:          s.Timezone,
+		MaterializedUntil: s.MaterializedUntil,
+		Status:            s.Status,
+		Tutor:             s.Tutor.ToTutorDTO(),
+		Students:          students,
+		Lessons:           lessons,
+		CreatedAt:         s.CreatedAt,
+		UpdatedAt:         s.UpdatedAt,
+	}
+}
+
+// LessonSeriesChanges holds the optional fields of an edit applied to one or more
+// occurrences of a series. Nil fields are left unchanged.
+type LessonSeriesChanges struct {
+	Title           *string
+	Description     *string
+	StartTime       *time.Time
+	DurationMinutes *int
+}
+
+// SeriesOccurrenceConflict describes an occurrence of a series that could not be
+// booked or moved, e.g. because it clashes with another lesson.
+type SeriesOccurrenceConflict struct {
+	LessonID  *uuid.UUID `json:"lesson_id,omitempty"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   time.Time  `json:"end_time"`
+	Reason    string     `json:"reason"`
+}
+
+// LessonSeriesResult is returned by operations that create or move occurrences.
+type LessonSeriesResult struct {
+	Series    LessonSeries
+	Lessons   []Lesson
+	Conflicts []SeriesOccurrenceConflict
+}
+
+// LessonSeriesResultDTO is the API shape of a LessonSeriesResult.
+type LessonSeriesResultDTO struct {
+	Series    LessonSeriesDTO            `json:"series"`
+	Lessons   []LessonDTO                `json:"lessons"`
+	Conflicts []SeriesOccurrenceConflict `json:"conflicts"`
+}
+
+// ToDTO converts a LessonSeriesResult to a LessonSeriesResultDTO.
+func (r *LessonSeriesResult) ToDTO() LessonSeriesResultDTO {
+	lessons := make([]LessonDTO, 0, len(r.Lessons))
+	for _, lesson := range r.Lessons {
+		lessons = append(lessons, lesson.ToDTO())
+	}
+	conflicts := r.Conflicts
+	if conflicts == nil {
+		conflicts = []SeriesOccurrenceConflict{}
+	}
+	return LessonSeriesResultDTO{
+		Series:    r.Series.ToDTO(),
+		Lessons:   lessons,
+		Conflicts: conflicts,
+	}
+}
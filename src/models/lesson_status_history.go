@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LessonStatusHistory records a single status transition of a lesson.
+type LessonStatusHistory struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	LessonID uuid.UUID `json:"lesson_id" gorm:"type:uuid;not null;index"`
+
+	// FromStatus is empty for the entry recorded when the lesson is created.
+	FromStatus string `json:"from_status" gorm:"type:varchar(20)"`
+	ToStatus   string `json:"to_status" gorm:"type:varchar(20);not null"`
+
+	// ActorID is nil for transitions made by the system, e.g. the background scheduler.
+	ActorID *uuid.UUID `json:"actor_id,omitempty" gorm:"type:uuid"`
+	Reason  string     `json:"reason" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName keeps the history in a singular "lesson_status_history" table.
+func (LessonStatusHistory) TableName() string {
+	return "lesson_status_history"
+}
+
+// LessonStatusHistoryDTO is the shape of a history entry returned via API.
+type LessonStatusHistoryDTO struct {
+	ID         uuid.UUID  `json:"id"`
+	FromStatus string     `json:"from_status"`
+	ToStatus   string     `json:"to_status"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty"`
+	Reason     string     `json:"reason"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ToDTO converts a LessonStatusHistory model to a LessonStatusHistoryDTO.
+func (h *LessonStatusHistory) ToDTO() LessonStatusHistoryDTO {
+	return LessonStatusHistoryDTO{
+		ID:         h.ID,
+		FromStatus: h.FromStatus,
+		ToStatus:   h.ToStatus,
+		ActorID:    h.ActorID,
+		Reason:     h.Reason,
+		CreatedAt:  h.CreatedAt,
+	}
+}
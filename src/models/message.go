@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Conversation kind constants
+const (
+	ConversationKindDirect = "direct" // between two users
+	ConversationKindLesson = "lesson" // the thread of a lesson, open to its participants
+)
+
+// Conversation is a message thread: either direct messages between two users, or the
+// chat of one lesson. A direct conversation stores its pair with UserAID < UserBID, so
+// each pair of users has at most one.
+type Conversation struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	Kind string `json:"kind" gorm:"type:varchar(20);not null"`
+
+	// Set for direct conversations.
+	UserAID *uuid.UUID `json:"user_a_id,omitempty" gorm:"type:uuid;index"`
+	UserBID *uuid.UUID `json:"user_b_id,omitempty" gorm:"type:uuid;index"`
+	// Set for lesson threads.
+	LessonID *uuid.UUID `json:"lesson_id,omitempty" gorm:"type:uuid"`
+
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Message is a text message in a conversation.
+type Message struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	ConversationID uuid.UUID `json:"conversation_id" gorm:"type:uuid;not null;index:idx_message_conversation_created"`
+	SenderID       uuid.UUID `json:"sender_id" gorm:"type:uuid;not null"`
+
+	Body string `json:"body" gorm:"type:text;not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_message_conversation_created"`
+}
+
+// ConversationRead records up to when a user has read a conversation. Messages sent
+// after LastReadAt by someone else are unread for that user.
+type ConversationRead struct {
+	ConversationID uuid.UUID `json:"conversation_id" gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	LastReadAt     time.Time `json:"last_read_at" gorm:"not null"`
+}
+
+// MessageDTO is a message as returned via API. ReadBy lists the other participants who
+// have read it.
+type MessageDTO struct {
+	ID             uuid.UUID   `json:"id"`
+	ConversationID uuid.UUID   `json:"conversation_id"`
+	SenderID       uuid.UUID   `json:"sender_id"`
+	Body           string      `json:"body"`
+	CreatedAt      time.Time   `json:"created_at"`
+	ReadBy         []uuid.UUID `json:"read_by"`
+}
+
+// ToDTO converts a Message to a MessageDTO, using the conversation's read receipts.
+func (m Message) ToDTO(receipts []ConversationRead) MessageDTO {
+	readBy := []uuid.UUID{}
+	for _, receipt := range receipts {
+		if receipt.UserID != m.SenderID && !receipt.LastReadAt.Before(m.CreatedAt) {
+			readBy = append(readBy, receipt.UserID)
+		}
+	}
+	return MessageDTO{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		SenderID:       m.SenderID,
+		Body:           m.Body,
+		CreatedAt:      m.CreatedAt,
+		ReadBy:         readBy,
+	}
+}
+
+// ConversationSummary is a conversation in a user's inbox.
+type ConversationSummary struct {
+	Conversation
+	// OtherUser is the other party of a direct conversation.
+	OtherUser   *StudentDTO `json:"other_user,omitempty"`
+	LastMessage *MessageDTO `json:"last_message,omitempty"`
+	UnreadCount int64       `json:"unread_count"`
+}
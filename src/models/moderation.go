@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Report target types
+const (
+	ReportTargetUser   = "user"
+	ReportTargetReview = "review"
+	ReportTargetCourse = "course"
+)
+
+// Report reasons
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonFakeReview    = "fake_review"
+	ReportReasonInappropriate = "inappropriate_content"
+	ReportReasonScam          = "scam"
+	ReportReasonOther         = "other"
+)
+
+// Report status constants
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// Report is a complaint filed by a user against another user, a review or a course.
+// Open reports form the moderation queue handled by admins.
+type Report struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	ReporterID uuid.UUID `json:"reporter_id" gorm:"index;not null"`
+	Reporter   User      `gorm:"foreignKey:ReporterID" json:"-"`
+
+	TargetType string    `json:"target_type" gorm:"type:varchar(20);not null;index:idx_report_target"`
+	TargetID   uuid.UUID `json:"target_id" gorm:"type:uuid;not null;index:idx_report_target"`
+	Reason     string    `json:"reason" gorm:"type:varchar(40);not null"`
+	Details    string    `json:"details" gorm:"type:text"`
+
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:'open';index"`
+	ResolutionNote string     `json:"resolution_note" gorm:"type:text"`
+	ResolvedByID   *uuid.UUID `json:"resolved_by_id,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ReportDTO is the shape of a report returned via API.
+type ReportDTO struct {
+	ID             uuid.UUID  `json:"id"`
+	Reporter       StudentDTO `json:"reporter"`
+	TargetType     string     `json:"target_type"`
+	TargetID       uuid.UUID  `json:"target_id"`
+	Reason         string     `json:"reason"`
+	Details        string     `json:"details"`
+	Status         string     `json:"status"`
+	ResolutionNote string     `json:"resolution_note"`
+	ResolvedByID   *uuid.UUID `json:"resolved_by_id,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ToDTO converts a Report model to a ReportDTO.
+func (r *Report) ToDTO() ReportDTO {
+	return ReportDTO{
+		ID:             r.ID,
+		Reporter:       r.Reporter.ToStudentDTO(),
+		TargetType:     r.TargetType,
+		TargetID:       r.TargetID,
+		Reason:         r.Reason,
+		Details:        r.Details,
+		Status:         r.Status,
+		ResolutionNote: r.ResolutionNote,
+		ResolvedByID:   r.ResolvedByID,
+		ResolvedAt:     r.ResolvedAt,
+		CreatedAt:      r.CreatedAt,
+	}
+}
+
+// UserBlock records that one user has blocked another.
+// A block works in both directions: neither party can book or enroll with the other.
+type UserBlock struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	BlockerID uuid.UUID `json:"blocker_id" gorm:"not null;uniqueIndex:idx_user_block_pair"`
+	BlockedID uuid.UUID `json:"blocked_id" gorm:"not null;uniqueIndex:idx_user_block_pair;index"`
+	Blocked   User      `gorm:"foreignKey:BlockedID" json:"-"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
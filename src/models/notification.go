@@ -0,0 +1,115 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types
+const (
+	NotificationTypeLessonScheduled = "lesson_scheduled"
+	NotificationTypeLessonConfirmed = "lesson_confirmed"
+	NotificationTypeLessonCancelled = "lesson_cancelled"
+	NotificationTypeLessonPostponed = "lesson_postponed"
+	NotificationTypeLessonExpired   = "lesson_expired"
+	NotificationTypeLessonReminder  = "lesson_reminder"
+	NotificationTypeEnrollment      = "enrollment"
+	NotificationTypeGeneral         = "general"
+)
+
+// Email delivery states of a notification. Notifications not sent by email have none.
+const (
+	NotificationEmailPending = "pending"
+	NotificationEmailSent    = "sent"
+	NotificationEmailFailed  = "failed"
+)
+
+// Notification tells a user about something that happened, in the app's notification
+// center and, depending on their preferences, by email and Discord.
+type Notification struct {
+	ID       uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index:idx_notifications_user_created,priority:1" json:"user_id"`
+	Type     string     `gorm:"type:varchar(30);not null" json:"type"`
+	Title    string     `gorm:"not null" json:"title"`
+	Body     string     `gorm:"type:text" json:"body"`
+	LessonID *uuid.UUID `gorm:"type:uuid" json:"lesson_id,omitempty"`
+	CourseID *uuid.UUID `gorm:"type:uuid" json:"course_id,omitempty"`
+	ReadAt   *time.Time `json:"read_at,omitempty"`
+
+	// InApp is false for notifications only sent by email, which the center doesn't list.
+	InApp bool `gorm:"not null" json:"-"`
+	// EmailStatus is empty when the notification isn't sent by email. Pending emails are
+	// sent once EmailDueAt passes, which is delayed until the user's quiet hours end.
+	EmailStatus string     `gorm:"type:varchar(20);index" json:"-"`
+	EmailDueAt  *time.Time `json:"-"`
+
+	CreatedAt time.Time `gorm:"index:idx_notifications_user_created,priority:2" json:"created_at"`
+}
+
+// NotificationPreferences are a user's delivery channels and quiet hours. Users without
+// saved preferences get DefaultNotificationPreferences.
+type NotificationPreferences struct {
+	UserID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	InAppEnabled bool      `gorm:"not null" json:"in_app_enabled"`
+	EmailEnabled bool      `gorm:"not null" json:"email_enabled"`
+	// DiscordEnabled sends notifications as direct messages from the platform's Discord bot.
+	DiscordEnabled bool `gorm:"not null;default:true" json:"discord_enabled"`
+	// QuietHoursStart and QuietHoursEnd ("15:04", in TimeZone) delay emails and direct
+	// messages sent between them until they end. The range may wrap past midnight; empty
+	// means no quiet hours.
+	QuietHoursStart string `gorm:"type:varchar(5)" json:"quiet_hours_start"`
+	QuietHoursEnd   string `gorm:"type:varchar(5)" json:"quiet_hours_end"`
+	TimeZone        string `gorm:"type:varchar(64);not null;default:'UTC'" json:"time_zone"`
+	// Language of the emails, "pl" or "en"; empty for the platform's default.
+	Language  string    `gorm:"type:varchar(5)" json:"language"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DefaultNotificationPreferences returns the preferences of a user who never changed them.
+func DefaultNotificationPreferences(userID uuid.UUID) NotificationPreferences {
+	return NotificationPreferences{UserID: userID, InAppEnabled: true, EmailEnabled: true, DiscordEnabled: true, TimeZone: "UTC"}
+}
+
+// Location returns the preferences' time zone, falling back to UTC.
+func (p NotificationPreferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// QuietUntil returns when the quiet hours containing t end, or false when t is outside
+// the quiet hours.
+func (p NotificationPreferences) QuietUntil(t time.Time) (time.Time, bool) {
+	if p.QuietHoursStart == "" || p.QuietHoursEnd == "" {
+		return time.Time{}, false
+	}
+	start, errStart := time.Parse("15:04", p.QuietHoursStart)
+	end, errEnd := time.Parse("15:04", p.QuietHoursEnd)
+	if errStart != nil || errEnd != nil {
+		return time.Time{}, false
+	}
+
+	local := t.In(p.Location())
+	minutes := local.Hour()*60 + local.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+
+	var quiet bool
+	if startMinutes <= endMinutes {
+		quiet = minutes >= startMinutes && minutes < endMinutes
+	} else {
+		quiet = minutes >= startMinutes || minutes < endMinutes
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, local.Location())
+	if minutes >= endMinutes {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Queued job states
+const (
+	QueuedJobPending   = "pending"
+	QueuedJobRunning   = "running"
+	QueuedJobDone      = "done"
+	QueuedJobFailed    = "failed"
+	QueuedJobCancelled = "cancelled"
+)
+
+// QueuedJob is a one-off piece of background work due at RunAt, such as a lesson
+// reminder. Jobs are stored so that they survive restarts; Key identifies a job so that
+// scheduling it again replaces it instead of adding a duplicate.
+type QueuedJob struct {
+	ID      uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	Kind    string    `gorm:"type:varchar(50);not null" json:"kind"`
+	Key     string    `gorm:"not null;uniqueIndex" json:"key"`
+	Payload string    `gorm:"type:jsonb;not null" json:"payload"`
+	RunAt   time.Time `gorm:"not null;index" json:"run_at"`
+	Status  string    `gorm:"type:varchar(20);not null;index" json:"status"`
+
+	Attempts  int    `gorm:"not null;default:0" json:"attempts"`
+	LastError string `gorm:"type:text" json:"last_error,omitempty"`
+	// LockedUntil is when a running job is considered abandoned, e.g. because the server
+	// running it stopped, and may be picked up again.
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
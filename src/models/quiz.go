@@ -0,0 +1,205 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Quiz question type constants
+const (
+	QuestionTypeMultipleChoice = "multiple_choice"
+	QuestionTypeNumeric        = "numeric"
+	QuestionTypeShortAnswer    = "short_answer"
+)
+
+// Quiz attempt status constants
+const (
+	QuizAttemptStatusInProgress  = "in_progress"
+	QuizAttemptStatusNeedsReview = "needs_review" // submitted, some answers wait for the tutor
+	QuizAttemptStatusGraded      = "graded"
+	QuizAttemptStatusExpired     = "expired" // not submitted within the time limit
+)
+
+// Quiz is a test a tutor sets for the students of a course or of a lesson, such as an
+// entry test or a progress check. Exactly one of CourseID and LessonID is set.
+type Quiz struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	TutorID  uuid.UUID  `json:"tutor_id" gorm:"type:uuid;not null;index"`
+	CourseID *uuid.UUID `json:"course_id,omitempty" gorm:"type:uuid;index"`
+	LessonID *uuid.UUID `json:"lesson_id,omitempty" gorm:"type:uuid;index"`
+
+	Title       string `json:"title" gorm:"not null"`
+	Description string `json:"description" gorm:"type:text"`
+	// TimeLimitMinutes bounds each attempt; zero means no limit.
+	TimeLimitMinutes int `json:"time_limit_minutes" gorm:"not null;default:0"`
+	// MaxAttempts caps the attempts per student; zero means unlimited.
+	MaxAttempts int `json:"max_attempts" gorm:"not null;default:0"`
+
+	Questions []QuizQuestion `gorm:"foreignKey:QuizID" json:"questions"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// MaxScore is the sum of the points of the quiz's questions.
+func (q Quiz) MaxScore() float64 {
+	var total float64
+	for _, question := range q.Questions {
+		total += question.Points
+	}
+	return total
+}
+
+// QuizQuestion is one question of a quiz. Which answer fields are used depends on Type:
+//   - multiple_choice: Options, with the indexes of the right ones in CorrectOptions
+//   - numeric: CorrectNumber, accepting answers within Tolerance of it
+//   - short_answer: AcceptedAnswers, compared ignoring case and extra spaces; with none
+//     the tutor grades the answer by hand
+type QuizQuestion struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	QuizID   uuid.UUID `json:"quiz_id" gorm:"type:uuid;not null;index"`
+	Position int       `json:"position" gorm:"not null"`
+	Type     string    `json:"type" gorm:"type:varchar(20);not null"`
+	Prompt   string    `json:"prompt" gorm:"type:text;not null"`
+	Points   float64   `json:"points" gorm:"not null;default:1"`
+
+	Options         pq.StringArray `json:"options,omitempty" gorm:"type:text[]"`
+	CorrectOptions  pq.Int64Array  `json:"correct_options,omitempty" gorm:"type:integer[]"`
+	CorrectNumber   *float64       `json:"correct_number,omitempty"`
+	Tolerance       float64        `json:"tolerance,omitempty"`
+	AcceptedAnswers pq.StringArray `json:"accepted_answers,omitempty" gorm:"type:text[]"`
+}
+
+// QuizQuestionDTO is a question as shown to students taking the quiz, without its answers.
+type QuizQuestionDTO struct {
+	ID       uuid.UUID `json:"id"`
+	Position int       `json:"position"`
+	Type     string    `json:"type"`
+	Prompt   string    `json:"prompt"`
+	Points   float64   `json:"points"`
+	Options  []string  `json:"options,omitempty"`
+	// MultipleAnswers tells a multiple-choice question has more than one right option.
+	MultipleAnswers bool `json:"multiple_answers,omitempty"`
+}
+
+// QuizDTO is a quiz as shown to students, without its answers.
+type QuizDTO struct {
+	ID               uuid.UUID         `json:"id"`
+	TutorID          uuid.UUID         `json:"tutor_id"`
+	CourseID         *uuid.UUID        `json:"course_id,omitempty"`
+	LessonID         *uuid.UUID        `json:"lesson_id,omitempty"`
+	Title            string            `json:"title"`
+	Description      string            `json:"description"`
+	TimeLimitMinutes int               `json:"time_limit_minutes"`
+	MaxAttempts      int               `json:"max_attempts"`
+	MaxScore         float64           `json:"max_score"`
+	Questions        []QuizQuestionDTO `json:"questions"`
+	CreatedAt        time.Time         `json:"created_at"`
+}
+
+// ToDTO converts a Quiz to the QuizDTO shown to students.
+func (q Quiz) ToDTO() QuizDTO {
+	questions := make([]QuizQuestionDTO, 0, len(q.Questions))
+	for _, question := range q.Questions {
+		questions = append(questions, QuizQuestionDTO{
+			ID:              question.ID,
+			Position:        question.Position,
+			Type:            question.Type,
+			Prompt:          question.Prompt,
+			Points:          question.Points,
+			Options:         question.Options,
+			MultipleAnswers: len(question.CorrectOptions) > 1,
+		})
+	}
+	return QuizDTO{
+		ID:               q.ID,
+		TutorID:          q.TutorID,
+		CourseID:         q.CourseID,
+		LessonID:         q.LessonID,
+		Title:            q.Title,
+		Description:      q.Description,
+		TimeLimitMinutes: q.TimeLimitMinutes,
+		MaxAttempts:      q.MaxAttempts,
+		MaxScore:         q.MaxScore(),
+		Questions:        questions,
+		CreatedAt:        q.CreatedAt,
+	}
+}
+
+// QuizAttempt is one go of a student at a quiz. Score counts the points awarded so far;
+// it is final once the attempt is graded.
+type QuizAttempt struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	QuizID    uuid.UUID `json:"quiz_id" gorm:"type:uuid;not null;index"`
+	StudentID uuid.UUID `json:"student_id" gorm:"type:uuid;not null;index"`
+	Student   User      `gorm:"foreignKey:StudentID" json:"-"`
+
+	Status      string     `json:"status" gorm:"type:varchar(20);not null"`
+	StartedAt   time.Time  `json:"started_at" gorm:"not null"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+
+	Score    float64 `json:"score" gorm:"not null;default:0"`
+	MaxScore float64 `json:"max_score" gorm:"not null;default:0"`
+
+	Answers []QuizAnswer `gorm:"foreignKey:AttemptID" json:"answers"`
+}
+
+// IsFinished reports whether the attempt can no longer be answered.
+func (a QuizAttempt) IsFinished() bool {
+	return a.Status != QuizAttemptStatusInProgress
+}
+
+// QuizAnswer is a student's answer to one question within an attempt. Points is nil
+// while the answer waits for the tutor to grade it.
+type QuizAnswer struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	AttemptID  uuid.UUID `json:"attempt_id" gorm:"type:uuid;not null;index"`
+	QuestionID uuid.UUID `json:"question_id" gorm:"type:uuid;not null"`
+
+	Choices pq.Int64Array `json:"choices,omitempty" gorm:"type:integer[]"`
+	Number  *float64      `json:"number,omitempty"`
+	Text    string        `json:"text,omitempty" gorm:"type:text"`
+
+	Correct *bool    `json:"correct,omitempty"`
+	Points  *float64 `json:"points,omitempty"`
+}
+
+// QuizAttemptDTO is the shape returned via API.
+type QuizAttemptDTO struct {
+	QuizAttempt
+	Student StudentDTO `json:"student"`
+}
+
+// ToDTO converts a QuizAttempt to a QuizAttemptDTO.
+func (a QuizAttempt) ToDTO() QuizAttemptDTO {
+	if a.Answers == nil {
+		a.Answers = []QuizAnswer{}
+	}
+	return QuizAttemptDTO{QuizAttempt: a, Student: a.Student.ToStudentDTO()}
+}
+
+// StudentQuizScore is a student's best result on a quiz.
+type StudentQuizScore struct {
+	StudentID uuid.UUID `json:"student_id"`
+	Attempts  int       `json:"attempts"`
+	BestScore float64   `json:"best_score"`
+}
+
+// QuizResult aggregates the finished attempts at a quiz for its tutor. AverageScore
+// averages each student's best attempt.
+type QuizResult struct {
+	QuizID       uuid.UUID          `json:"quiz_id"`
+	Title        string             `json:"title"`
+	MaxScore     float64            `json:"max_score"`
+	Attempts     int                `json:"attempts"`
+	Students     int                `json:"students"`
+	AverageScore float64            `json:"average_score"`
+	Scores       []StudentQuizScore `json:"scores"`
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reschedule proposal status constants
+const (
+	RescheduleStatusPending   = "pending"
+	RescheduleStatusAccepted  = "accepted"
+	RescheduleStatusRejected  = "rejected"
+	RescheduleStatusCountered = "countered" // replaced by a counter-proposal
+	RescheduleStatusExpired   = "expired"
+)
+
+// RescheduleProposal is a request by one lesson participant to move the lesson to one of
+// several alternative time windows. The lesson only moves once the counterpart accepts
+// one of the options.
+type RescheduleProposal struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	LessonID     uuid.UUID `json:"lesson_id" gorm:"type:uuid;not null;index"`
+	ProposedByID uuid.UUID `json:"proposed_by_id" gorm:"type:uuid;not null"`
+	// CounterOfID links a counter-proposal to the proposal it replaced.
+	CounterOfID *uuid.UUID `json:"counter_of_id,omitempty" gorm:"type:uuid"`
+
+	Message string             `json:"message" gorm:"type:text"`
+	Options []RescheduleOption `gorm:"foreignKey:ProposalID" json:"options"`
+	// Acceptances are the students who accepted a tutor's proposal for a group lesson,
+	// which only moves once all of them agree on the same option.
+	Acceptances []RescheduleAcceptance `gorm:"foreignKey:ProposalID" json:"acceptances"`
+
+	Status    string    `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+
+	RespondedByID    *uuid.UUID `json:"responded_by_id,omitempty" gorm:"type:uuid"`
+	RespondedAt      *time.Time `json:"responded_at,omitempty"`
+	ResponseNote     string     `json:"response_note" gorm:"type:text"`
+	AcceptedOptionID *uuid.UUID `json:"accepted_option_id,omitempty" gorm:"type:uuid"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// RescheduleOption is one alternative time window of a RescheduleProposal.
+type RescheduleOption struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	ProposalID uuid.UUID `json:"proposal_id" gorm:"type:uuid;not null;index"`
+	StartTime  time.Time `json:"start_time" gorm:"not null"`
+	EndTime    time.Time `json:"end_time" gorm:"not null"`
+}
+
+// RescheduleAcceptance records which option one student accepted of a tutor's proposal
+// for a group lesson.
+type RescheduleAcceptance struct {
+	ProposalID uuid.UUID `json:"proposal_id" gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	OptionID   uuid.UUID `json:"option_id" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// RescheduleAcceptanceDTO is the shape of an acceptance returned via API.
+type RescheduleAcceptanceDTO struct {
+	UserID   uuid.UUID `json:"user_id"`
+	OptionID uuid.UUID `json:"option_id"`
+}
+
+// RescheduleOptionDTO is the shape of an option returned via API.
+type RescheduleOptionDTO struct {
+	ID        uuid.UUID `json:"id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// RescheduleProposalDTO is the shape of a proposal returned via API.
+type RescheduleProposalDTO struct {
+	ID               uuid.UUID                 `json:"id"`
+	LessonID         uuid.UUID                 `json:"lesson_id"`
+	ProposedByID     uuid.UUID                 `json:"proposed_by_id"`
+	CounterOfID      *uuid.UUID                `json:"counter_of_id,omitempty"`
+	Message          string                    `json:"message"`
+	Options          []RescheduleOptionDTO     `json:"options"`
+	Acceptances      []RescheduleAcceptanceDTO `json:"acceptances"`
+	Status           string                    `json:"status"`
+	ExpiresAt        time.Time                 `json:"expires_at"`
+	RespondedByID    *uuid.UUID                `json:"responded_by_id,omitempty"`
+	RespondedAt      *time.Time                `json:"responded_at,omitempty"`
+	ResponseNote     string                    `json:"response_note"`
+	AcceptedOptionID *uuid.UUID                `json:"accepted_option_id,omitempty"`
+	CreatedAt        time.Time                 `json:"created_at"`
+}
+
+// ToDTO converts a RescheduleProposal model to a RescheduleProposalDTO.
+func (p *RescheduleProposal) ToDTO() RescheduleProposalDTO {
+	options := make([]RescheduleOptionDTO, 0, len(p.Options))
+	for _, option := range p.Options {
+		options = append(options, RescheduleOptionDTO{
+			ID:        option.ID,
+			StartTime: option.StartTime,
+			EndTime:   option.EndTime,
+		})
+	}
+
+	acceptances := make([]RescheduleAcceptanceDTO, 0, len(p.Acceptances))
+	for _, acceptance := range p.Acceptances {
+		acceptances = append(acceptances, RescheduleAcceptanceDTO{
+			UserID:   acceptance.UserID,
+			OptionID: acceptance.OptionID,
+		})
+	}
+
+	return RescheduleProposalDTO{
+		ID:               p.ID,
+		LessonID:         p.LessonID,
+		ProposedByID:     p.ProposedByID,
+		CounterOfID:      p.CounterOfID,
+		Message:          p.Message,
+		Options:          options,
+		Acceptances:      acceptances,
+		Status:           p.Status,
+		ExpiresAt:        p.ExpiresAt,
+		RespondedByID:    p.RespondedByID,
+		RespondedAt:      p.RespondedAt,
+		ResponseNote:     p.ResponseNote,
+		AcceptedOptionID: p.AcceptedOptionID,
+		CreatedAt:        p.CreatedAt,
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Slot hold status constants
+const (
+	SlotHoldStatusActive    = "active"
+	SlotHoldStatusConfirmed = "confirmed" // turned into a lesson
+	SlotHoldStatusReleased  = "released"
+	SlotHoldStatusExpired   = "expired"
+)
+
+// SlotHold is a short-lived reservation of a tutor's time slot while a student goes
+// through checkout. While active it hides the slot from other users; confirming it
+// books the lesson it describes.
+type SlotHold struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	TutorID   uuid.UUID `json:"tutor_id" gorm:"type:uuid;not null;index"`
+	StudentID uuid.UUID `json:"student_id" gorm:"type:uuid;not null;index"`
+
+	StartTime time.Time `json:"start_time" gorm:"not null"`
+	EndTime   time.Time `json:"end_time" gorm:"not null"`
+
+	// The lesson that confirming the hold books.
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Subject     string     `json:"subject"`
+	Level       string     `json:"level"`
+	Price       float64    `json:"price"`
+	OfferingID  *uuid.UUID `json:"offering_id,omitempty" gorm:"type:uuid"`
+	MaxStudents int        `json:"max_students"`
+	IsTrial     bool       `json:"is_trial"`
+
+	Status    string     `json:"status" gorm:"type:varchar(20);not null;default:'active';index"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	LessonID  *uuid.UUID `json:"lesson_id,omitempty" gorm:"type:uuid"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// IsActive reports whether the hold still reserves its slot at the given time.
+func (h *SlotHold) IsActive(now time.Time) bool {
+	return h.Status == SlotHoldStatusActive && now.Before(h.ExpiresAt)
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// TutorOffering is a kind of lesson a tutor publishes, such as "30 min consultation" or
+// "90 min group class", with its own length, price and subjects. Lessons booked against
+// an offering keep a snapshot of its price.
+type TutorOffering struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	TutorID uuid.UUID `json:"tutor_id" gorm:"type:uuid;not null;index"`
+
+	Title           string `json:"title" gorm:"not null"`
+	Description     string `json:"description" gorm:"type:text"`
+	DurationMinutes int    `json:"duration_minutes" gorm:"not null"`
+	// Price each student pays for one lesson.
+	Price float64 `json:"price" gorm:"not null"`
+	// MaxStudents above 1 makes this a group offering; zero or one is a 1:1 lesson.
+	MaxStudents int `json:"max_students" gorm:"not null;default:1"`
+	// IsTrial marks a free or discounted first lesson, bookable once per student and only
+	// by students who have had no lesson with the tutor yet.
+	IsTrial bool `json:"is_trial" gorm:"not null;default:false"`
+
+	// Subjects and levels the offering is for; empty means any the tutor teaches.
+	Subjects pq.StringArray `json:"subjects" gorm:"type:text[]"`
+	Levels   pq.StringArray `json:"levels" gorm:"type:text[]"`
+
+	// Archived offerings can no longer be booked but stay linked to their lessons.
+	Active bool `json:"active" gorm:"not null;default:true"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// IsGroup reports whether the offering is for group lessons.
+func (o *TutorOffering) IsGroup() bool {
+	return o.MaxStudents > 1
+}
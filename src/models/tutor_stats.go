@@ -0,0 +1,31 @@
+package models
+
+import "github.com/google/uuid"
+
+// TutorStats summarizes a tutor's lessons and how their trial lessons convert into paid ones.
+type TutorStats struct {
+	TutorID          uuid.UUID `json:"tutor_id"`
+	CompletedLessons int       `json:"completed_lessons"`
+	Students         int       `json:"students"`
+
+	// Trial lessons that are booked or took place, and of those the completed ones.
+	TrialLessons          int `json:"trial_lessons"`
+	CompletedTrialLessons int `json:"completed_trial_lessons"`
+	// Students who completed a trial, and of those the ones who booked a paid lesson after it.
+	TrialStudents     int `json:"trial_students"`
+	ConvertedStudents int `json:"converted_students"`
+	// ConvertedStudents over TrialStudents.
+	TrialConversionRate float64 `json:"trial_conversion_rate"`
+
+	// Cancelled lessons, by who cancelled them (the system cancels without an actor) and
+	// by cancellation reason.
+	CancelledLessons      int            `json:"cancelled_lessons"`
+	CancelledByTutor      int            `json:"cancelled_by_tutor"`
+	CancelledByStudents   int            `json:"cancelled_by_students"`
+	CancelledBySystem     int            `json:"cancelled_by_system"`
+	CancellationsByReason map[string]int `json:"cancellations_by_reason"`
+	// Seats students cancelled in group lessons that went on for the others.
+	SeatCancellations int `json:"seat_cancellations"`
+	// Late cancellation fees charged to students, for lessons and seats.
+	CancellationFees float64 `json:"cancellation_fees"`
+}
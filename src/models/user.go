@@ -13,6 +13,7 @@ import (
 const (
 	UserRoleStudent = "student"
 	UserRoleTutor   = "tutor"
+	UserRoleAdmin   = "admin"
 )
 
 // User model
@@ -37,6 +38,15 @@ type User struct {
 	Price    float64        `json:"price"`
 	Levels   pq.StringArray `json:"levels" gorm:"type:text[]"`
 	Subjects pq.StringArray `json:"subjects" gorm:"type:text[]"`
+
+	// Moderation: a suspended account is rejected by the auth middleware.
+	SuspendedAt      *time.Time `json:"suspended_at,omitempty"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+}
+
+// IsSuspended reports whether the account has been suspended by a moderator.
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
 }
 
 // RetrieveAvatarURL constructs the URL to the user's Discord avatar.
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WaitlistEntry is a student's place in the queue for a full group lesson or course.
+// Exactly one of LessonID and CourseID is set. Entries are served first come, first served.
+type WaitlistEntry struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+
+	LessonID *uuid.UUID `json:"lesson_id,omitempty" gorm:"type:uuid;index"`
+	CourseID *uuid.UUID `json:"course_id,omitempty" gorm:"type:uuid;index"`
+
+	StudentID uuid.UUID `json:"student_id" gorm:"type:uuid;not null"`
+	Student   User      `gorm:"foreignKey:StudentID" json:"student"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// WaitlistEntryDTO is the shape returned via API. Position starts at 1.
+type WaitlistEntryDTO struct {
+	ID        uuid.UUID  `json:"id"`
+	LessonID  *uuid.UUID `json:"lesson_id,omitempty"`
+	CourseID  *uuid.UUID `json:"course_id,omitempty"`
+	Student   StudentDTO `json:"student"`
+	Position  int        `json:"position"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ToDTO converts a WaitlistEntry to a WaitlistEntryDTO at the given position.
+func (e WaitlistEntry) ToDTO(position int) WaitlistEntryDTO {
+	return WaitlistEntryDTO{
+		ID:        e.ID,
+		LessonID:  e.LessonID,
+		CourseID:  e.CourseID,
+		Student:   e.Student.ToStudentDTO(),
+		Position:  position,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// HasFreeSeat reports whether a group with the given capacity has room for one more
+// student. A capacity of zero means the group is unlimited.
+func HasFreeSeat(maxStudents, enrolled int) bool {
+	return maxStudents == 0 || enrolled < maxStudents
+}
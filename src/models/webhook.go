@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Webhook delivery states. A pending delivery is waiting for its first attempt or a retry.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription sends the lesson and course events of its user's account, e.g. a
+// partner school's, to an endpoint of theirs.
+type WebhookSubscription struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	URL         string    `gorm:"not null" json:"url"`
+	Description string    `json:"description"`
+	// EventTypes filters the events sent; empty means all webhook events.
+	EventTypes pq.StringArray `gorm:"type:text[]" json:"event_types"`
+	Active     bool           `gorm:"not null;default:true" json:"active"`
+	// Secret signs the payloads. It is only shown when the subscription is created.
+	Secret string `gorm:"not null" json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Wants tells whether the subscription receives events of the given type.
+func (s WebhookSubscription) Wants(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent to a subscription, with the outcome of its last
+// attempt. Deliveries form the subscription's delivery log.
+type WebhookDelivery struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null;index:idx_webhook_deliveries_subscription_created,priority:1" json:"subscription_id"`
+	// EventID is shared by all deliveries of an event, including redeliveries, so
+	// receivers can drop duplicates.
+	EventID   uuid.UUID `gorm:"type:uuid;not null" json:"event_id"`
+	EventType string    `gorm:"type:varchar(50);not null" json:"event_type"`
+	// Payload is the JSON body sent.
+	Payload string `gorm:"type:jsonb;not null" json:"payload"`
+	// RedeliveryOf is the delivery this one was manually resent from.
+	RedeliveryOf *uuid.UUID `gorm:"type:uuid" json:"redelivery_of,omitempty"`
+
+	Status         string     `gorm:"type:varchar(20);not null" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	ResponseBody   string     `gorm:"type:text" json:"response_body,omitempty"`
+	Error          string     `gorm:"type:text" json:"error,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"index:idx_webhook_deliveries_subscription_created,priority:2" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
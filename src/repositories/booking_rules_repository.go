@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// BookingRulesRepository defines the methods to interact with tutor booking rules.
+type BookingRulesRepository interface {
+	GetRulesByTutorID(tutorID uuid.UUID) (models.TutorBookingRules, error)
+	UpsertRules(rules *models.TutorBookingRules) error
+}
+
+type bookingRulesRepository struct {
+	db *gorm.DB
+}
+
+// NewBookingRulesRepository creates a new instance of BookingRulesRepository.
+func NewBookingRulesRepository(db *gorm.DB) BookingRulesRepository {
+	return &bookingRulesRepository{db: db}
+}
+
+// GetRulesByTutorID retrieves a tutor's rules, or gorm.ErrRecordNotFound if none are set.
+func (r *bookingRulesRepository) GetRulesByTutorID(tutorID uuid.UUID) (models.TutorBookingRules, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var rules models.TutorBookingRules
+	if err := r.db.WithContext(ctx).
+		Where("tutor_id = ?", tutorID).
+		First(&rules).Error; err != nil {
+		return models.TutorBookingRules{}, err
+	}
+	return rules, nil
+}
+
+// UpsertRules creates the tutor's rules or replaces the existing ones.
+func (r *bookingRulesRepository) UpsertRules(rules *models.TutorBookingRules) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tutor_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"buffer_minutes", "min_notice_hours", "max_days_ahead",
+				"max_lessons_per_day", "slot_granularity_minutes", "updated_at",
+			}),
+		}).
+		Create(rules).Error
+}
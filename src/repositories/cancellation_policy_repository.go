@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// CancellationPolicyRepository defines the methods to interact with tutor cancellation policies.
+type CancellationPolicyRepository interface {
+	GetPolicyByTutorID(tutorID uuid.UUID) (models.TutorCancellationPolicy, error)
+	UpsertPolicy(policy *models.TutorCancellationPolicy) error
+}
+
+type cancellationPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewCancellationPolicyRepository creates a new instance of CancellationPolicyRepository.
+func NewCancellationPolicyRepository(db *gorm.DB) CancellationPolicyRepository {
+	return &cancellationPolicyRepository{db: db}
+}
+
+// GetPolicyByTutorID retrieves a tutor's policy, or gorm.ErrRecordNotFound if none is set.
+func (r *cancellationPolicyRepository) GetPolicyByTutorID(tutorID uuid.UUID) (models.TutorCancellationPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var policy models.TutorCancellationPolicy
+	if err := r.db.WithContext(ctx).
+		Where("tutor_id = ?", tutorID).
+		First(&policy).Error; err != nil {
+		return models.TutorCancellationPolicy{}, err
+	}
+	return policy, nil
+}
+
+// UpsertPolicy creates the tutor's policy or replaces the existing one.
+func (r *cancellationPolicyRepository) UpsertPolicy(policy *models.TutorCancellationPolicy) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tutor_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"free_cancellation_hours", "late_cancellation_fee_percent", "updated_at"}),
+		}).
+		Create(policy).Error
+}
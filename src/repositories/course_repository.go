@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,7 +18,8 @@ type CourseRepository interface {
 	UpdateCourse(course *models.Course) error
 	GetCourses(subject, level string, page, limit int) ([]models.Course, error)
 	GetCoursesForUser(userID uuid.UUID) ([]models.Course, error)
-	EnrollStudent(courseID uuid.UUID, student models.User) error
+	CountCourseLessons(courseID uuid.UUID) (total, completed int64, err error)
+	GetCourseAttendanceCounts(courseID uuid.UUID) ([]models.AttendanceCount, error)
 }
 
 type courseRepository struct {
@@ -105,23 +105,36 @@ func (r *courseRepository) GetCoursesForUser(userID uuid.UUID) ([]models.Course,
 		Find(&courses).Error
 	return courses, err
 }
-func (r *courseRepository) EnrollStudent(courseID uuid.UUID, student models.User) error {
+
+// CountCourseLessons counts the course's lessons that are still going to take place or
+// took place, and of those the completed ones.
+func (r *courseRepository) CountCourseLessons(courseID uuid.UUID) (total, completed int64, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	var course models.Course
-	if err := r.db.WithContext(ctx).Preload("Students").First(&course, "id = ?", courseID).Error; err != nil {
-		return err
+	if err = r.db.WithContext(ctx).Model(&models.Lesson{}).
+		Where("course_id = ? AND status IN ?", courseID, models.LessonOccupyingStatuses).
+		Count(&total).Error; err != nil {
+		return 0, 0, err
 	}
+	err = r.db.WithContext(ctx).Model(&models.Lesson{}).
+		Where("course_id = ? AND status = ?", courseID, models.LessonStatusDone).
+		Count(&completed).Error
+	return total, completed, err
+}
 
-	// Check if the student is already enrolled.
-	for _, s := range course.Students {
-		if s.ID == student.ID {
-			return errors.New("student already enrolled")
-		}
-	}
+// GetCourseAttendanceCounts counts, per student, the course's completed lessons by attendance.
+func (r *courseRepository) GetCourseAttendanceCounts(courseID uuid.UUID) ([]models.AttendanceCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	// Append the student and update the record.
-	course.Students = append(course.Students, student)
-	return r.db.WithContext(ctx).Save(&course).Error
+	var counts []models.AttendanceCount
+	err := r.db.WithContext(ctx).
+		Table("lesson_students").
+		Select("lesson_students.user_id, lesson_students.attendance, COUNT(*) AS lessons").
+		Joins("JOIN lessons ON lessons.id = lesson_students.lesson_id").
+		Where("lessons.course_id = ? AND lessons.status = ?", courseID, models.LessonStatusDone).
+		Group("lesson_students.user_id, lesson_students.attendance").
+		Scan(&counts).Error
+	return counts, err
 }
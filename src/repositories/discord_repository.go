@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"vibely-backend/src/models"
+)
+
+// DiscordRepository defines the methods to interact with the Discord channels of lessons.
+type DiscordRepository interface {
+	GetLessonChannels(lessonID uuid.UUID) (models.LessonDiscordChannels, error)
+	CreateLessonChannels(channels *models.LessonDiscordChannels) error
+	DeleteLessonChannels(lessonID uuid.UUID) error
+}
+
+type discordRepository struct {
+	db *gorm.DB
+}
+
+// NewDiscordRepository creates a new instance of DiscordRepository.
+func NewDiscordRepository(db *gorm.DB) DiscordRepository {
+	return &discordRepository{db: db}
+}
+
+func (r *discordRepository) GetLessonChannels(lessonID uuid.UUID) (models.LessonDiscordChannels, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var channels models.LessonDiscordChannels
+	err := r.db.WithContext(ctx).Where("lesson_id = ?", lessonID).First(&channels).Error
+	return channels, err
+}
+
+func (r *discordRepository) CreateLessonChannels(channels *models.LessonDiscordChannels) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(channels).Error
+}
+
+func (r *discordRepository) DeleteLessonChannels(lessonID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Where("lesson_id = ?", lessonID).Delete(&models.LessonDiscordChannels{}).Error
+}
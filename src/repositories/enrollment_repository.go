@@ -0,0 +1,234 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// EnrollmentRepository defines the methods to manage seats in group lessons and courses
+// and their waitlists. Seats are taken and freed under a row lock on the lesson or
+// course, so the Lock methods must run inside Transaction.
+type EnrollmentRepository interface {
+	Transaction(fn func(repo EnrollmentRepository) error) error
+
+	// Student conflicts, checked under the same locks as lesson bookings
+	LockParticipants(userIDs []uuid.UUID) error
+	GetOverlappingLessons(userIDs []uuid.UUID, start, end time.Time, excludeLessonID uuid.UUID) ([]models.Lesson, error)
+
+	// Lessons
+	LockLesson(lessonID uuid.UUID) (models.Lesson, error)
+	AddLessonStudent(lessonID, studentID uuid.UUID) error
+	RemoveLessonStudent(lessonID, studentID uuid.UUID) (bool, error)
+	GetLessonWaitlist(lessonID uuid.UUID) ([]models.WaitlistEntry, error)
+	CreateSeatCancellation(cancellation *models.SeatCancellation) error
+
+	// Courses
+	LockCourse(courseID uuid.UUID) (models.Course, error)
+	GetUpcomingCourseLessons(courseID uuid.UUID, from time.Time) ([]models.Lesson, error)
+	AddCourseStudent(courseID, studentID uuid.UUID, from time.Time) error
+	RemoveCourseStudent(courseID, studentID uuid.UUID, from time.Time) (bool, error)
+	GetCourseWaitlist(courseID uuid.UUID) ([]models.WaitlistEntry, error)
+
+	// Waitlist entries
+	CreateWaitlistEntry(entry *models.WaitlistEntry) error
+	DeleteWaitlistEntry(entryID uuid.UUID) error
+}
+
+type enrollmentRepository struct {
+	db *gorm.DB
+}
+
+// NewEnrollmentRepository creates a new instance of EnrollmentRepository.
+func NewEnrollmentRepository(db *gorm.DB) EnrollmentRepository {
+	return &enrollmentRepository{db: db}
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *enrollmentRepository) Transaction(fn func(repo EnrollmentRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&enrollmentRepository{db: tx})
+	})
+}
+
+// LockParticipants takes the advisory locks lesson bookings take on their participants,
+// so that a student's seats and bookings are serialized. It must run inside Transaction.
+func (r *enrollmentRepository) LockParticipants(userIDs []uuid.UUID) error {
+	return lockParticipants(r.db, userIDs)
+}
+
+// GetOverlappingLessons returns lessons occupying any part of [start, end) in which
+// one of the given users takes part, either as the tutor or as a student.
+func (r *enrollmentRepository) GetOverlappingLessons(userIDs []uuid.UUID, start, end time.Time, excludeLessonID uuid.UUID) ([]models.Lesson, error) {
+	return getOverlappingLessons(r.db, userIDs, start, end, excludeLessonID)
+}
+
+// LockLesson locks the lesson row until the end of the transaction and returns the
+// lesson with its tutor and students.
+func (r *enrollmentRepository) LockLesson(lessonID uuid.UUID) (models.Lesson, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&models.Lesson{}, "id = ?", lessonID).Error; err != nil {
+		return models.Lesson{}, err
+	}
+
+	var lesson models.Lesson
+	if err := r.db.WithContext(ctx).
+		Preload("Tutor").
+		Preload("Students").
+		First(&lesson, "id = ?", lessonID).Error; err != nil {
+		return models.Lesson{}, err
+	}
+	return lesson, nil
+}
+
+// AddLessonStudent gives the student a seat in the lesson.
+func (r *enrollmentRepository) AddLessonStudent(lessonID, studentID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).
+		Exec("INSERT INTO lesson_students (lesson_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING", lessonID, studentID).
+		Error
+}
+
+// RemoveLessonStudent frees the student's seat in the lesson. It reports whether the
+// student was enrolled.
+func (r *enrollmentRepository) RemoveLessonStudent(lessonID, studentID uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Exec("DELETE FROM lesson_students WHERE lesson_id = ? AND user_id = ?", lessonID, studentID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetLessonWaitlist returns the lesson's waitlist in order, with students preloaded.
+func (r *enrollmentRepository) GetLessonWaitlist(lessonID uuid.UUID) ([]models.WaitlistEntry, error) {
+	return r.getWaitlist("lesson_id = ?", lessonID)
+}
+
+// CreateSeatCancellation records a student cancelling their seat in a lesson.
+func (r *enrollmentRepository) CreateSeatCancellation(cancellation *models.SeatCancellation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(cancellation).Error
+}
+
+// LockCourse locks the course row until the end of the transaction and returns the
+// course with its students.
+func (r *enrollmentRepository) LockCourse(courseID uuid.UUID) (models.Course, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&models.Course{}, "id = ?", courseID).Error; err != nil {
+		return models.Course{}, err
+	}
+
+	var course models.Course
+	if err := r.db.WithContext(ctx).
+		Preload("Students").
+		First(&course, "id = ?", courseID).Error; err != nil {
+		return models.Course{}, err
+	}
+	return course, nil
+}
+
+// GetUpcomingCourseLessons returns the course's lessons starting after from that are
+// still going to take place, the ones AddCourseStudent enrolls students in.
+func (r *enrollmentRepository) GetUpcomingCourseLessons(courseID uuid.UUID, from time.Time) ([]models.Lesson, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var lessons []models.Lesson
+	err := r.db.WithContext(ctx).
+		Where("course_id = ? AND start_time > ? AND status IN ?",
+			courseID, from, []string{models.LessonStatusScheduled, models.LessonStatusConfirmed}).
+		Order("start_time ASC").
+		Find(&lessons).Error
+	return lessons, err
+}
+
+// AddCourseStudent enrolls the student in the course and in its lessons starting after from.
+func (r *enrollmentRepository) AddCourseStudent(courseID, studentID uuid.UUID, from time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).
+		Exec("INSERT INTO course_students (course_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING", courseID, studentID).
+		Error; err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).
+		Exec(`INSERT INTO lesson_students (lesson_id, user_id)
+			SELECT id, ? FROM lessons WHERE course_id = ? AND start_time > ? AND status IN ?
+			ON CONFLICT DO NOTHING`,
+			studentID, courseID, from, []string{models.LessonStatusScheduled, models.LessonStatusConfirmed}).
+		Error
+}
+
+// RemoveCourseStudent unenrolls the student from the course and from its lessons starting
+// after from; past lessons keep the student. It reports whether the student was enrolled.
+func (r *enrollmentRepository) RemoveCourseStudent(courseID, studentID uuid.UUID, from time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Exec("DELETE FROM course_students WHERE course_id = ? AND user_id = ?", courseID, studentID)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	err := r.db.WithContext(ctx).
+		Exec(`DELETE FROM lesson_students WHERE user_id = ?
+			AND lesson_id IN (SELECT id FROM lessons WHERE course_id = ? AND start_time > ?)`,
+			studentID, courseID, from).
+		Error
+	return true, err
+}
+
+// GetCourseWaitlist returns the course's waitlist in order, with students preloaded.
+func (r *enrollmentRepository) GetCourseWaitlist(courseID uuid.UUID) ([]models.WaitlistEntry, error) {
+	return r.getWaitlist("course_id = ?", courseID)
+}
+
+func (r *enrollmentRepository) getWaitlist(query string, id uuid.UUID) ([]models.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var entries []models.WaitlistEntry
+	err := r.db.WithContext(ctx).
+		Preload("Student").
+		Where(query, id).
+		Order("created_at ASC, id ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// CreateWaitlistEntry appends a student to a waitlist.
+func (r *enrollmentRepository) CreateWaitlistEntry(entry *models.WaitlistEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Omit("Student").Create(entry).Error
+}
+
+// DeleteWaitlistEntry removes an entry from its waitlist.
+func (r *enrollmentRepository) DeleteWaitlistEntry(entryID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Delete(&models.WaitlistEntry{}, "id = ?", entryID).Error
+}
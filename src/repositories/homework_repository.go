@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// HomeworkRepository defines the methods to interact with homework submissions and
+// their files. Resubmissions lock the existing submission, so LockSubmission must run
+// inside Transaction.
+type HomeworkRepository interface {
+	Transaction(fn func(repo HomeworkRepository) error) error
+
+	LockSubmission(homeworkID, studentID uuid.UUID) (models.HomeworkSubmission, error)
+	CreateSubmission(submission *models.HomeworkSubmission) error
+	UpdateSubmission(submission *models.HomeworkSubmission) error
+	GradeSubmission(submission *models.HomeworkSubmission) error
+	ReplaceSubmissionFiles(submissionID uuid.UUID, files []models.SubmissionFile) error
+
+	GetSubmissionByID(submissionID uuid.UUID) (models.HomeworkSubmission, error)
+	GetSubmissionsByHomeworkID(homeworkID uuid.UUID) ([]models.HomeworkSubmission, error)
+	GetSubmissionFileByID(fileID uuid.UUID) (models.SubmissionFile, error)
+
+	// Student overview
+	GetHomeworkForStudent(studentID, tutorID uuid.UUID) ([]models.Homework, error)
+	GetSubmissionsByStudentID(studentID uuid.UUID) ([]models.HomeworkSubmission, error)
+}
+
+type homeworkRepository struct {
+	db *gorm.DB
+}
+
+// NewHomeworkRepository creates a new instance of HomeworkRepository.
+func NewHomeworkRepository(db *gorm.DB) HomeworkRepository {
+	return &homeworkRepository{db: db}
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *homeworkRepository) Transaction(fn func(repo HomeworkRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&homeworkRepository{db: tx})
+	})
+}
+
+// LockSubmission locks the student's submission for the homework until the end of the
+// transaction and returns it with its files.
+func (r *homeworkRepository) LockSubmission(homeworkID, studentID uuid.UUID) (models.HomeworkSubmission, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var submission models.HomeworkSubmission
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&submission, "homework_id = ? AND student_id = ?", homeworkID, studentID).Error; err != nil {
+		return models.HomeworkSubmission{}, err
+	}
+	if err := r.db.WithContext(ctx).
+		Where("submission_id = ?", submission.ID).
+		Find(&submission.Files).Error; err != nil {
+		return models.HomeworkSubmission{}, err
+	}
+	return submission, nil
+}
+
+// CreateSubmission inserts a new submission together with its files.
+func (r *homeworkRepository) CreateSubmission(submission *models.HomeworkSubmission) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Omit("Student").Create(submission).Error
+}
+
+// UpdateSubmission saves changes to the submission row; its files are left untouched.
+func (r *homeworkRepository) UpdateSubmission(submission *models.HomeworkSubmission) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(submission).Error
+}
+
+// GradeSubmission saves the grade of the submission, leaving what the student handed in
+// untouched.
+func (r *homeworkRepository) GradeSubmission(submission *models.HomeworkSubmission) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).
+		Model(submission).
+		Select("Status", "Score", "Feedback", "GradedByID", "GradedAt", "UpdatedAt").
+		Updates(submission).Error
+}
+
+// ReplaceSubmissionFiles swaps the file records of a submission for files.
+func (r *homeworkRepository) ReplaceSubmissionFiles(submissionID uuid.UUID, files []models.SubmissionFile) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).
+		Delete(&models.SubmissionFile{}, "submission_id = ?", submissionID).Error; err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	for i := range files {
+		files[i].SubmissionID = submissionID
+	}
+	return r.db.WithContext(ctx).Create(&files).Error
+}
+
+// GetSubmissionByID retrieves a submission with its student and files.
+func (r *homeworkRepository) GetSubmissionByID(submissionID uuid.UUID) (models.HomeworkSubmission, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var submission models.HomeworkSubmission
+	if err := r.db.WithContext(ctx).
+		Preload("Student").
+		Preload("Files").
+		First(&submission, "id = ?", submissionID).Error; err != nil {
+		return models.HomeworkSubmission{}, err
+	}
+	return submission, nil
+}
+
+// GetSubmissionsByHomeworkID lists the submissions for a homework, oldest first.
+func (r *homeworkRepository) GetSubmissionsByHomeworkID(homeworkID uuid.UUID) ([]models.HomeworkSubmission, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var submissions []models.HomeworkSubmission
+	err := r.db.WithContext(ctx).
+		Preload("Student").
+		Preload("Files").
+		Where("homework_id = ?", homeworkID).
+		Order("submitted_at ASC").
+		Find(&submissions).Error
+	return submissions, err
+}
+
+// GetSubmissionFileByID retrieves a submission file record by its ID.
+func (r *homeworkRepository) GetSubmissionFileByID(fileID uuid.UUID) (models.SubmissionFile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var file models.SubmissionFile
+	if err := r.db.WithContext(ctx).First(&file, "id = ?", fileID).Error; err != nil {
+		return models.SubmissionFile{}, err
+	}
+	return file, nil
+}
+
+// GetHomeworkForStudent lists the homework given in lessons the student attends, skipping
+// lessons that did not take place, and only lessons of tutorID unless it is uuid.Nil.
+// Homework due soonest comes first.
+func (r *homeworkRepository) GetHomeworkForStudent(studentID, tutorID uuid.UUID) ([]models.Homework, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := r.db.WithContext(ctx).
+		Joins("JOIN lessons ON lessons.id = homeworks.lesson_id").
+		Joins("JOIN lesson_students ON lesson_students.lesson_id = lessons.id").
+		Where("lesson_students.user_id = ? AND lessons.status IN ?", studentID, models.LessonOccupyingStatuses)
+	if tutorID != uuid.Nil {
+		query = query.Where("lessons.tutor_id = ?", tutorID)
+	}
+
+	var homework []models.Homework
+	err := query.
+		Order("homeworks.due_at ASC NULLS LAST, homeworks.created_at ASC").
+		Find(&homework).Error
+	return homework, err
+}
+
+// GetSubmissionsByStudentID lists every submission of the student, with files.
+func (r *homeworkRepository) GetSubmissionsByStudentID(studentID uuid.UUID) ([]models.HomeworkSubmission, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var submissions []models.HomeworkSubmission
+	err := r.db.WithContext(ctx).
+		Preload("Files").
+		Where("student_id = ?", studentID).
+		Find(&submissions).Error
+	return submissions, err
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// JobRepository defines the methods to interact with the durable job queue.
+type JobRepository interface {
+	// Enqueue schedules the job, replacing the job with the same key if there is one.
+	Enqueue(job *models.QueuedJob) error
+	// CancelJobs cancels the pending jobs whose key starts with keyPrefix.
+	CancelJobs(keyPrefix string) (int64, error)
+
+	// ClaimDueJobs marks up to limit due jobs, and running jobs whose lock expired, as
+	// running until lockedUntil, counting an attempt, and returns them.
+	ClaimDueJobs(now, lockedUntil time.Time, limit int) ([]models.QueuedJob, error)
+	FinishJob(job *models.QueuedJob, status, lastError string) error
+	RetryJob(job *models.QueuedJob, runAt time.Time, lastError string) error
+	DeleteFinishedJobs(before time.Time) (int64, error)
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRepository creates a new instance of JobRepository.
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Enqueue(job *models.QueuedJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	job.Status = models.QueuedJobPending
+	job.Attempts = 0
+	job.LastError = ""
+	job.LockedUntil = nil
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"kind", "payload", "run_at", "status", "attempts", "last_error", "locked_until", "updated_at",
+			}),
+		}).
+		Create(job).Error
+}
+
+func (r *jobRepository) CancelJobs(keyPrefix string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).Model(&models.QueuedJob{}).
+		Where("starts_with(key, ?) AND status = ?", keyPrefix, models.QueuedJobPending).
+		Update("status", models.QueuedJobCancelled)
+	return result.RowsAffected, result.Error
+}
+
+func (r *jobRepository) ClaimDueJobs(now, lockedUntil time.Time, limit int) ([]models.QueuedJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var jobs []models.QueuedJob
+	// SKIP LOCKED lets concurrent runners claim disjoint batches.
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE queued_jobs
+		SET status = ?, attempts = attempts + 1, locked_until = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM queued_jobs
+			WHERE (status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)
+			ORDER BY run_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.QueuedJobRunning, lockedUntil, now,
+		models.QueuedJobPending, now, models.QueuedJobRunning, now,
+		limit,
+	).Scan(&jobs).Error
+	return jobs, err
+}
+
+// FinishJob moves a running job to a final status.
+func (r *jobRepository) FinishJob(job *models.QueuedJob, status, lastError string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A job rescheduled while it ran is pending again and must be left alone.
+	return r.db.WithContext(ctx).Model(&models.QueuedJob{}).
+		Where("id = ? AND status = ? AND attempts = ?", job.ID, models.QueuedJobRunning, job.Attempts).
+		Updates(map[string]interface{}{"status": status, "last_error": lastError, "locked_until": nil}).Error
+}
+
+// RetryJob puts a running job that failed back in the queue, due at runAt.
+func (r *jobRepository) RetryJob(job *models.QueuedJob, runAt time.Time, lastError string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Model(&models.QueuedJob{}).
+		Where("id = ? AND status = ? AND attempts = ?", job.ID, models.QueuedJobRunning, job.Attempts).
+		Updates(map[string]interface{}{
+			"status":       models.QueuedJobPending,
+			"run_at":       runAt,
+			"last_error":   lastError,
+			"locked_until": nil,
+		}).Error
+}
+
+// DeleteFinishedJobs deletes the done, failed and cancelled jobs last updated before before.
+func (r *jobRepository) DeleteFinishedJobs(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Where("status IN ? AND updated_at < ?",
+			[]string{models.QueuedJobDone, models.QueuedJobFailed, models.QueuedJobCancelled}, before).
+		Delete(&models.QueuedJob{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"vibely-backend/src/models"
+)
+
+// LessonMaterialRepository defines the methods to interact with lesson notes, homework
+// and attachment records. Attachment files themselves are kept in storage.
+type LessonMaterialRepository interface {
+	GetLessonMaterials(lessonID uuid.UUID) (models.LessonMaterials, error)
+
+	// Notes
+	CreateNote(note *models.LessonNote) error
+	GetNoteByID(noteID uuid.UUID) (models.LessonNote, error)
+	UpdateNote(note *models.LessonNote) error
+	DeleteNote(noteID uuid.UUID) error
+
+	// Homework
+	CreateHomework(homework *models.Homework) error
+	GetHomeworkByID(homeworkID uuid.UUID) (models.Homework, error)
+	UpdateHomework(homework *models.Homework) error
+	DeleteHomework(homeworkID uuid.UUID) error
+	CountSubmissions(homeworkID uuid.UUID) (int64, error)
+
+	// Attachments
+	CreateAttachment(attachment *models.LessonAttachment) error
+	GetAttachmentByID(attachmentID uuid.UUID) (models.LessonAttachment, error)
+	DeleteAttachment(attachmentID uuid.UUID) error
+}
+
+type lessonMaterialRepository struct {
+	db *gorm.DB
+}
+
+// NewLessonMaterialRepository creates a new instance of LessonMaterialRepository.
+func NewLessonMaterialRepository(db *gorm.DB) LessonMaterialRepository {
+	return &lessonMaterialRepository{db: db}
+}
+
+// GetLessonMaterials retrieves the notes, homework and attachments of a lesson, oldest first.
+func (r *lessonMaterialRepository) GetLessonMaterials(lessonID uuid.UUID) (models.LessonMaterials, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	materials := models.LessonMaterials{
+		Notes:       []models.LessonNote{},
+		Homework:    []models.Homework{},
+		Attachments: []models.LessonAttachment{},
+	}
+	db := r.db.WithContext(ctx)
+	if err := db.Where("lesson_id = ?", lessonID).Order("created_at ASC").Find(&materials.Notes).Error; err != nil {
+		return models.LessonMaterials{}, err
+	}
+	if err := db.Where("lesson_id = ?", lessonID).Order("created_at ASC").Find(&materials.Homework).Error; err != nil {
+		return models.LessonMaterials{}, err
+	}
+	if err := db.Where("lesson_id = ?", lessonID).Order("created_at ASC").Find(&materials.Attachments).Error; err != nil {
+		return models.LessonMaterials{}, err
+	}
+	return materials, nil
+}
+
+// CreateNote inserts a new lesson note.
+func (r *lessonMaterialRepository) CreateNote(note *models.LessonNote) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(note).Error
+}
+
+// GetNoteByID retrieves a lesson note by its ID.
+func (r *lessonMaterialRepository) GetNoteByID(noteID uuid.UUID) (models.LessonNote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var note models.LessonNote
+	if err := r.db.WithContext(ctx).First(&note, "id = ?", noteID).Error; err != nil {
+		return models.LessonNote{}, err
+	}
+	return note, nil
+}
+
+// UpdateNote saves changes to an existing lesson note.
+func (r *lessonMaterialRepository) UpdateNote(note *models.LessonNote) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Save(note).Error
+}
+
+// DeleteNote removes a lesson note.
+func (r *lessonMaterialRepository) DeleteNote(noteID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Delete(&models.LessonNote{}, "id = ?", noteID).Error
+}
+
+// CreateHomework inserts a new homework assignment.
+func (r *lessonMaterialRepository) CreateHomework(homework *models.Homework) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(homework).Error
+}
+
+// GetHomeworkByID retrieves a homework assignment by its ID.
+func (r *lessonMaterialRepository) GetHomeworkByID(homeworkID uuid.UUID) (models.Homework, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var homework models.Homework
+	if err := r.db.WithContext(ctx).First(&homework, "id = ?", homeworkID).Error; err != nil {
+		return models.Homework{}, err
+	}
+	return homework, nil
+}
+
+// UpdateHomework saves changes to an existing homework assignment.
+func (r *lessonMaterialRepository) UpdateHomework(homework *models.Homework) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Save(homework).Error
+}
+
+// DeleteHomework removes a homework assignment.
+func (r *lessonMaterialRepository) DeleteHomework(homeworkID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Delete(&models.Homework{}, "id = ?", homeworkID).Error
+}
+
+// CountSubmissions counts the students who handed in the homework.
+func (r *lessonMaterialRepository) CountSubmissions(homeworkID uuid.UUID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.HomeworkSubmission{}).
+		Where("homework_id = ?", homeworkID).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateAttachment inserts the record of a stored attachment.
+func (r *lessonMaterialRepository) CreateAttachment(attachment *models.LessonAttachment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+// GetAttachmentByID retrieves an attachment record by its ID.
+func (r *lessonMaterialRepository) GetAttachmentByID(attachmentID uuid.UUID) (models.LessonAttachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var attachment models.LessonAttachment
+	if err := r.db.WithContext(ctx).First(&attachment, "id = ?", attachmentID).Error; err != nil {
+		return models.LessonAttachment{}, err
+	}
+	return attachment, nil
+}
+
+// DeleteAttachment removes an attachment record.
+func (r *lessonMaterialRepository) DeleteAttachment(attachmentID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Delete(&models.LessonAttachment{}, "id = ?", attachmentID).Error
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,8 +19,36 @@ type LessonRepository interface {
 	GetLessonsForUser(userID uuid.UUID) ([]models.Lesson, error)
 	GetTutorsForUser(userID uuid.UUID) ([]models.User, error)
 	GetStudentsForTutor(tutorID uuid.UUID) ([]models.User, error)
-	EnrollStudent(lessonID uuid.UUID, student models.User) error
+	HasLessonsWithTutor(tutorID, studentID uuid.UUID) (bool, error)
+	GetTutorStats(tutorID uuid.UUID) (models.TutorStats, error)
 	GetLessonsByTutorIDAndDateRange(tutorID uuid.UUID, startDate, endDate time.Time) ([]models.Lesson, error)
+	GetOverlappingLessons(userIDs []uuid.UUID, start, end time.Time, excludeLessonID uuid.UUID) ([]models.Lesson, error)
+	CountTutorLessonsBetween(tutorID uuid.UUID, start, end time.Time, excludeLessonID uuid.UUID) (int64, error)
+	LockParticipants(userIDs []uuid.UUID) error
+	Transaction(fn func(repo LessonRepository) error) error
+
+	// Queries used by the time-driven status transitions
+	GetLessonsStartingBefore(status string, before time.Time) ([]models.Lesson, error)
+	GetUndisputedLessonsEndingBefore(status string, before time.Time) ([]models.Lesson, error)
+	UpdateLessonStatusIf(lessonID uuid.UUID, fromStatus, toStatus string) (bool, error)
+
+	// Meeting link
+	UpdateMeetingURL(lessonID uuid.UUID, meetingURL string) error
+
+	// Status history
+	CreateStatusHistory(entry *models.LessonStatusHistory) error
+	GetStatusHistory(lessonID uuid.UUID) ([]models.LessonStatusHistory, error)
+
+	// Attendance
+	UpdateAttendance(lessonID uuid.UUID, records []models.LessonStudent) error
+	GetStudentAttendanceCounts(studentID, tutorID uuid.UUID) ([]models.AttendanceCount, error)
+
+	// Slot holds
+	CreateSlotHold(hold *models.SlotHold) error
+	GetSlotHoldByID(holdID uuid.UUID) (models.SlotHold, error)
+	GetActiveSlotHolds(tutorID uuid.UUID, start, end time.Time, excludeStudentIDs []uuid.UUID) ([]models.SlotHold, error)
+	UpdateSlotHoldIfStatus(hold *models.SlotHold, fromStatus string) (bool, error)
+	ExpireSlotHolds(now time.Time) (int64, error)
 }
 
 type lessonRepository struct {
@@ -55,7 +84,8 @@ func (r *lessonRepository) GetLessonByID(lessonID uuid.UUID) (models.Lesson, err
 	return lesson, nil
 }
 
-// GetLessonWithParticipants preloads the Tutor and Students associations.
+// GetLessonWithParticipants preloads the Tutor and Students associations and the
+// students' attendance.
 func (r *lessonRepository) GetLessonWithParticipants(lessonID uuid.UUID) (models.Lesson, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -64,6 +94,7 @@ func (r *lessonRepository) GetLessonWithParticipants(lessonID uuid.UUID) (models
 	if err := r.db.WithContext(ctx).
 		Preload("Tutor").
 		Preload("Students").
+		Preload("Attendance").
 		First(&lesson, "id = ?", lessonID).Error; err != nil {
 		return models.Lesson{}, err
 	}
@@ -84,6 +115,7 @@ func (r *lessonRepository) GetLessonsForUser(userID uuid.UUID) ([]models.Lesson,
 	err := r.db.
 		Preload("Tutor").
 		Preload("Students").
+		Preload("Attendance").
 		Where("tutor_id = ?", userID).
 		Or("id IN (SELECT lesson_id FROM lesson_students WHERE user_id = ?)", userID).
 		Find(&lessons).Error
@@ -113,26 +145,6 @@ func (r *lessonRepository) GetTutorsForUser(userID uuid.UUID) ([]models.User, er
 	}
 	return tutors, nil
 }
-func (r *lessonRepository) EnrollStudent(lessonID uuid.UUID, student models.User) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	// Assuming Lesson model has a Students association.
-	var lesson models.Lesson
-	if err := r.db.WithContext(ctx).Preload("Students").First(&lesson, "id = ?", lessonID).Error; err != nil {
-		return err
-	}
-
-	// Check if the student is already enrolled (optional).
-	for _, s := range lesson.Students {
-		if s.ID == student.ID {
-			return nil // Already enrolled; nothing to do.
-		}
-	}
-
-	// Append student to lesson's Students association.
-	return r.db.WithContext(ctx).Model(&lesson).Association("Students").Append(&student)
-}
 func (r *lessonRepository) GetLessonsByTutorIDAndDateRange(tutorID uuid.UUID, startDate, endDate time.Time) ([]models.Lesson, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -188,6 +200,368 @@ func (r *lessonRepository) GetStudentsForTutor(tutorID uuid.UUID) ([]models.User
 	return students, nil
 }
 
+// HasLessonsWithTutor reports whether the student has a lesson with the tutor that is
+// booked or took place; cancelled, expired and failed lessons don't count.
+func (r *lessonRepository) HasLessonsWithTutor(tutorID, studentID uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Lesson{}).
+		Where("tutor_id = ?", tutorID).
+		Where("id IN (SELECT lesson_id FROM lesson_students WHERE user_id = ?)", studentID).
+		Where("status IN ?", models.LessonOccupyingStatuses).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetTutorStats counts the tutor's completed lessons, students and trial lessons, and the
+// students who booked a paid lesson after completing a trial.
+func (r *lessonRepository) GetTutorStats(tutorID uuid.UUID) (models.TutorStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats := models.TutorStats{TutorID: tutorID, CancellationsByReason: map[string]int{}}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT
+			COUNT(*) FILTER (WHERE status = @done) AS completed_lessons,
+			COUNT(*) FILTER (WHERE is_trial AND status IN @occupying) AS trial_lessons,
+			COUNT(*) FILTER (WHERE is_trial AND status = @done) AS completed_trial_lessons,
+			COUNT(*) FILTER (WHERE status = @cancelled) AS cancelled_lessons,
+			COUNT(*) FILTER (WHERE status = @cancelled AND cancelled_by_id = @tutor) AS cancelled_by_tutor,
+			COUNT(*) FILTER (WHERE status = @cancelled AND cancelled_by_id <> @tutor) AS cancelled_by_students,
+			COUNT(*) FILTER (WHERE status = @cancelled AND cancelled_by_id IS NULL) AS cancelled_by_system,
+			COALESCE(SUM(cancellation_fee) FILTER (WHERE status = @cancelled), 0) AS cancellation_fees
+		FROM lessons WHERE tutor_id = @tutor`,
+		map[string]interface{}{
+			"tutor":     tutorID,
+			"done":      models.LessonStatusDone,
+			"cancelled": models.LessonStatusCancelled,
+			"occupying": models.LessonOccupyingStatuses,
+		}).
+		Row().
+		Scan(&stats.CompletedLessons, &stats.TrialLessons, &stats.CompletedTrialLessons,
+			&stats.CancelledLessons, &stats.CancelledByTutor, &stats.CancelledByStudents, &stats.CancelledBySystem,
+			&stats.CancellationFees)
+	if err != nil {
+		return models.TutorStats{}, err
+	}
+
+	var reasons []struct {
+		Reason string
+		Count  int
+	}
+	err = r.db.WithContext(ctx).
+		Model(&models.Lesson{}).
+		Select("cancellation_reason AS reason, COUNT(*) AS count").
+		Where("tutor_id = ? AND status = ?", tutorID, models.LessonStatusCancelled).
+		Group("cancellation_reason").
+		Scan(&reasons).Error
+	if err != nil {
+		return models.TutorStats{}, err
+	}
+	for _, reason := range reasons {
+		stats.CancellationsByReason[reason.Reason] = reason.Count
+	}
+
+	var seatFees float64
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(*), COALESCE(SUM(sc.cancellation_fee), 0)
+		FROM seat_cancellations sc JOIN lessons l ON l.id = sc.lesson_id
+		WHERE l.tutor_id = ?`,
+		tutorID).
+		Row().
+		Scan(&stats.SeatCancellations, &seatFees)
+	if err != nil {
+		return models.TutorStats{}, err
+	}
+	stats.CancellationFees += seatFees
+
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(DISTINCT ls.user_id)
+		FROM lesson_students ls JOIN lessons l ON l.id = ls.lesson_id
+		WHERE l.tutor_id = ? AND l.status IN ?`,
+		tutorID, models.LessonOccupyingStatuses).
+		Row().
+		Scan(&stats.Students)
+	if err != nil {
+		return models.TutorStats{}, err
+	}
+
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT
+			COUNT(DISTINCT trial.user_id),
+			COUNT(DISTINCT trial.user_id) FILTER (WHERE EXISTS (
+				SELECT 1 FROM lesson_students paid JOIN lessons pl ON pl.id = paid.lesson_id
+				WHERE paid.user_id = trial.user_id AND pl.tutor_id = tl.tutor_id
+				AND NOT pl.is_trial AND pl.status IN ? AND pl.start_time > tl.start_time
+			))
+		FROM lesson_students trial JOIN lessons tl ON tl.id = trial.lesson_id
+		WHERE tl.tutor_id = ? AND tl.is_trial AND tl.status = ?`,
+		models.LessonOccupyingStatuses, tutorID, models.LessonStatusDone).
+		Row().
+		Scan(&stats.TrialStudents, &stats.ConvertedStudents)
+	if err != nil {
+		return models.TutorStats{}, err
+	}
+	return stats, nil
+}
+
+// GetOverlappingLessons returns lessons occupying any part of [start, end) in which
+// one of the given users takes part, either as the tutor or as a student.
+func (r *lessonRepository) GetOverlappingLessons(userIDs []uuid.UUID, start, end time.Time, excludeLessonID uuid.UUID) ([]models.Lesson, error) {
+	return getOverlappingLessons(r.db, userIDs, start, end, excludeLessonID)
+}
+
+func getOverlappingLessons(db *gorm.DB, userIDs []uuid.UUID, start, end time.Time, excludeLessonID uuid.UUID) ([]models.Lesson, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var lessons []models.Lesson
+	err := db.WithContext(ctx).
+		Where("tutor_id IN ? OR id IN (SELECT lesson_id FROM lesson_students WHERE user_id IN ?)", userIDs, userIDs).
+		Where("status IN ?", models.LessonOccupyingStatuses).
+		Where("start_time < ? AND end_time > ?", end, start).
+		Where("id <> ?", excludeLessonID).
+		Find(&lessons).Error
+	return lessons, err
+}
+
+// CountTutorLessonsBetween counts the tutor's lessons occupying their slot that start in [start, end).
+func (r *lessonRepository) CountTutorLessonsBetween(tutorID uuid.UUID, start, end time.Time, excludeLessonID uuid.UUID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Lesson{}).
+		Where("tutor_id = ?", tutorID).
+		Where("status IN ?", models.LessonOccupyingStatuses).
+		Where("start_time >= ? AND start_time < ?", start, end).
+		Where("id <> ?", excludeLessonID).
+		Count(&count).Error
+	return count, err
+}
+
+// LockParticipants takes transaction-scoped advisory locks on the given users so that
+// concurrent bookings involving any of them are serialized. It must run inside Transaction.
+func (r *lessonRepository) LockParticipants(userIDs []uuid.UUID) error {
+	return lockParticipants(r.db, userIDs)
+}
+
+func lockParticipants(db *gorm.DB, userIDs []uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Lock in a stable order to avoid deadlocks between overlapping bookings.
+	ids := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		ids = append(ids, id.String())
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if err := db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", id).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *lessonRepository) Transaction(fn func(repo LessonRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&lessonRepository{db: tx})
+	})
+}
+
+// GetLessonsStartingBefore returns lessons in the given status whose StartTime is before the given time.
+func (r *lessonRepository) GetLessonsStartingBefore(status string, before time.Time) ([]models.Lesson, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var lessons []models.Lesson
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND start_time <= ?", status, before).
+		Order("start_time ASC").
+		Find(&lessons).Error
+	return lessons, err
+}
+
+// GetUndisputedLessonsEndingBefore returns undisputed lessons in the given status whose EndTime is before the given time.
+func (r *lessonRepository) GetUndisputedLessonsEndingBefore(status string, before time.Time) ([]models.Lesson, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var lessons []models.Lesson
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND end_time <= ? AND disputed_at IS NULL", status, before).
+		Order("end_time ASC").
+		Find(&lessons).Error
+	return lessons, err
+}
+
+// UpdateLessonStatusIf moves a lesson from fromStatus to toStatus only if it is still in
+// fromStatus. It reports whether the lesson was updated, which makes the change idempotent.
+func (r *lessonRepository) UpdateLessonStatusIf(lessonID uuid.UUID, fromStatus, toStatus string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Lesson{}).
+		Where("id = ? AND status = ?", lessonID, fromStatus).
+		Updates(map[string]interface{}{"status": toStatus, "updated_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateMeetingURL sets the lesson's meeting link without touching its other columns.
+func (r *lessonRepository) UpdateMeetingURL(lessonID uuid.UUID, meetingURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).
+		Model(&models.Lesson{}).
+		Where("id = ?", lessonID).
+		Updates(map[string]interface{}{"meeting_url": meetingURL, "updated_at": time.Now()}).Error
+}
+
+// CreateStatusHistory records a lesson status transition.
+func (r *lessonRepository) CreateStatusHistory(entry *models.LessonStatusHistory) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// GetStatusHistory returns the status transitions of a lesson, oldest first.
+func (r *lessonRepository) GetStatusHistory(lessonID uuid.UUID) ([]models.LessonStatusHistory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var history []models.LessonStatusHistory
+	err := r.db.WithContext(ctx).
+		Where("lesson_id = ?", lessonID).
+		Order("created_at ASC").
+		Find(&history).Error
+	return history, err
+}
+
+// UpdateAttendance records the attendance of the lesson's students.
+func (r *lessonRepository) UpdateAttendance(lessonID uuid.UUID, records []models.LessonStudent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, record := range records {
+			if err := tx.Model(&models.LessonStudent{}).
+				Where("lesson_id = ? AND user_id = ?", lessonID, record.UserID).
+				Updates(map[string]interface{}{
+					"attendance":             record.Attendance,
+					"attendance_note":        record.AttendanceNote,
+					"attendance_recorded_at": record.AttendanceRecordedAt,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetStudentAttendanceCounts counts the student's lessons that took place by attendance,
+// only counting lessons of tutorID unless it is uuid.Nil.
+func (r *lessonRepository) GetStudentAttendanceCounts(studentID, tutorID uuid.UUID) ([]models.AttendanceCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := r.db.WithContext(ctx).
+		Table("lesson_students").
+		Select("lesson_students.user_id, lesson_students.attendance, COUNT(*) AS lessons").
+		Joins("JOIN lessons ON lessons.id = lesson_students.lesson_id").
+		Where("lesson_students.user_id = ?", studentID).
+		Where("lessons.status IN ?", []string{models.LessonStatusInProgress, models.LessonStatusDone})
+	if tutorID != uuid.Nil {
+		query = query.Where("lessons.tutor_id = ?", tutorID)
+	}
+
+	var counts []models.AttendanceCount
+	err := query.
+		Group("lesson_students.user_id, lesson_students.attendance").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// CreateSlotHold inserts a new SlotHold.
+func (r *lessonRepository) CreateSlotHold(hold *models.SlotHold) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(hold).Error
+}
+
+// GetSlotHoldByID retrieves a SlotHold.
+func (r *lessonRepository) GetSlotHoldByID(holdID uuid.UUID) (models.SlotHold, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var hold models.SlotHold
+	if err := r.db.WithContext(ctx).First(&hold, "id = ?", holdID).Error; err != nil {
+		return models.SlotHold{}, err
+	}
+	return hold, nil
+}
+
+// GetActiveSlotHolds returns the tutor's unexpired active holds overlapping [start, end),
+// ignoring holds placed by any of excludeStudentIDs.
+func (r *lessonRepository) GetActiveSlotHolds(tutorID uuid.UUID, start, end time.Time, excludeStudentIDs []uuid.UUID) ([]models.SlotHold, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := r.db.WithContext(ctx).
+		Where("tutor_id = ? AND status = ? AND expires_at > ?", tutorID, models.SlotHoldStatusActive, time.Now()).
+		Where("start_time < ? AND end_time > ?", end, start)
+	if len(excludeStudentIDs) > 0 {
+		query = query.Where("student_id NOT IN ?", excludeStudentIDs)
+	}
+
+	var holds []models.SlotHold
+	err := query.Order("start_time ASC").Find(&holds).Error
+	return holds, err
+}
+
+// UpdateSlotHoldIfStatus saves the hold's status and lesson only if it is still in
+// fromStatus, and reports whether it was updated.
+func (r *lessonRepository) UpdateSlotHoldIfStatus(hold *models.SlotHold, fromStatus string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Model(&models.SlotHold{}).
+		Where("id = ? AND status = ?", hold.ID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":     hold.Status,
+			"lesson_id":  hold.LessonID,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ExpireSlotHolds marks every active hold past its ExpiresAt as expired and returns how
+// many were expired.
+func (r *lessonRepository) ExpireSlotHolds(now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Model(&models.SlotHold{}).
+		Where("status = ? AND expires_at <= ?", models.SlotHoldStatusActive, now).
+		Updates(map[string]interface{}{
+			"status":     models.SlotHoldStatusExpired,
+			"updated_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
 type lessonSearchParams struct {
 }
 
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// LessonSeriesRepository defines the methods to interact with recurring lesson series.
+type LessonSeriesRepository interface {
+	CreateSeries(series *models.LessonSeries) error
+	GetSeriesByID(seriesID uuid.UUID) (models.LessonSeries, error)
+	GetSeriesWithLessons(seriesID uuid.UUID) (models.LessonSeries, error)
+	UpdateSeries(series *models.LessonSeries) error
+	DeleteSeries(seriesID uuid.UUID) error
+	GetSeriesLessons(seriesID uuid.UUID) ([]models.Lesson, error)
+	GetSeriesDueForMaterialization(horizon time.Time) ([]models.LessonSeries, error)
+}
+
+type lessonSeriesRepository struct {
+	db *gorm.DB
+}
+
+// NewLessonSeriesRepository creates a new instance of LessonSeriesRepository.
+func NewLessonSeriesRepository(db *gorm.DB) LessonSeriesRepository {
+	return &lessonSeriesRepository{db: db}
+}
+
+// CreateSeries inserts the series along with its lesson_series_students pivot rows.
+func (r *lessonSeriesRepository) CreateSeries(series *models.LessonSeries) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Omit("Tutor", "Lessons").Create(series).Error
+}
+
+// GetSeriesByID retrieves a series with its tutor and students.
+func (r *lessonSeriesRepository) GetSeriesByID(seriesID uuid.UUID) (models.LessonSeries, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var series models.LessonSeries
+	if err := r.db.WithContext(ctx).
+		Preload("Tutor").
+		Preload("Students").
+		First(&series, "id = ?", seriesID).Error; err != nil {
+		return models.LessonSeries{}, err
+	}
+	return series, nil
+}
+
+// GetSeriesWithLessons retrieves a series with its participants and materialized lessons.
+func (r *lessonSeriesRepository) GetSeriesWithLessons(seriesID uuid.UUID) (models.LessonSeries, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var series models.LessonSeries
+	if err := r.db.WithContext(ctx).
+		Preload("Tutor").
+		Preload("Students").
+		Preload("Lessons", func(db *gorm.DB) *gorm.DB {
+			return db.Order("start_time ASC")
+		}).
+		Preload("Lessons.Tutor").
+		Preload("Lessons.Students").
+		First(&series, "id = ?", seriesID).Error; err != nil {
+		return models.LessonSeries{}, err
+	}
+	return series, nil
+}
+
+// DeleteSeries deletes the series together with its lessons, their students and status
+// history. It is meant for series that failed to be created, whose lessons have no
+// history of their own yet.
+func (r *lessonSeriesRepository) DeleteSeries(seriesID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		lessonIDs := tx.Model(&models.Lesson{}).Select("id").Where("series_id = ?", seriesID)
+		if err := tx.Where("lesson_id IN (?)", lessonIDs).Delete(&models.LessonStatusHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM lesson_students WHERE lesson_id IN (?)", lessonIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("series_id = ?", seriesID).Delete(&models.Lesson{}).Error; err != nil {
+			return err
+		}
+		return tx.Select("Students").Delete(&models.LessonSeries{ID: seriesID}).Error
+	})
+}
+
+// UpdateSeries saves the series row without touching its associations.
+func (r *lessonSeriesRepository) UpdateSeries(series *models.LessonSeries) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(series).Error
+}
+
+// GetSeriesLessons returns every lesson materialized for the series, oldest first.
+func (r *lessonSeriesRepository) GetSeriesLessons(seriesID uuid.UUID) ([]models.Lesson, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var lessons []models.Lesson
+	err := r.db.WithContext(ctx).
+		Preload("Students").
+		Where("series_id = ?", seriesID).
+		Order("start_time ASC").
+		Find(&lessons).Error
+	return lessons, err
+}
+
+// GetSeriesDueForMaterialization returns active series not yet materialized up to horizon.
+func (r *lessonSeriesRepository) GetSeriesDueForMaterialization(horizon time.Time) ([]models.LessonSeries, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var series []models.LessonSeries
+	err := r.db.WithContext(ctx).
+		Preload("Students").
+		Where("status = ? AND materialized_until < ?", models.LessonSeriesStatusActive, horizon).
+		Find(&series).Error
+	return series, err
+}
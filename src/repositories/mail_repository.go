@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// MailRepository defines the methods to interact with the email outbox, bounces and
+// unsubscribe tokens.
+type MailRepository interface {
+	// Outbox
+	CreateOutboxEmail(email *models.OutboxEmail) error
+	GetOutboxEmailByID(emailID uuid.UUID) (models.OutboxEmail, error)
+	UpdateOutboxEmail(email *models.OutboxEmail) error
+	DeleteOutboxEmailsBefore(before time.Time) (int64, error)
+
+	// Bounces
+	CreateBounce(bounce *models.EmailBounce) error
+	// IsSuppressed tells whether the address hard bounced or complained before.
+	IsSuppressed(address string) (bool, error)
+
+	// Unsubscribe tokens
+	// GetOrCreateUnsubscribeToken returns the user's token, storing newToken if they have none yet.
+	GetOrCreateUnsubscribeToken(userID uuid.UUID, newToken string) (string, error)
+	GetUserIDByUnsubscribeToken(token string) (uuid.UUID, error)
+}
+
+type mailRepository struct {
+	db *gorm.DB
+}
+
+// NewMailRepository creates a new instance of MailRepository.
+func NewMailRepository(db *gorm.DB) MailRepository {
+	return &mailRepository{db: db}
+}
+
+func (r *mailRepository) CreateOutboxEmail(email *models.OutboxEmail) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(email).Error
+}
+
+func (r *mailRepository) GetOutboxEmailByID(emailID uuid.UUID) (models.OutboxEmail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var email models.OutboxEmail
+	err := r.db.WithContext(ctx).Where("id = ?", emailID).First(&email).Error
+	return email, err
+}
+
+func (r *mailRepository) UpdateOutboxEmail(email *models.OutboxEmail) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Save(email).Error
+}
+
+// DeleteOutboxEmailsBefore deletes the emails no longer pending that were created before before.
+func (r *mailRepository) DeleteOutboxEmailsBefore(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Where("status <> ? AND created_at < ?", models.OutboxEmailPending, before).
+		Delete(&models.OutboxEmail{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *mailRepository) CreateBounce(bounce *models.EmailBounce) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(bounce).Error
+}
+
+func (r *mailRepository) IsSuppressed(address string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.EmailBounce{}).
+		Where("lower(address) = lower(?) AND kind IN ?", address,
+			[]string{models.EmailBounceHard, models.EmailBounceComplaint}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *mailRepository) GetOrCreateUnsubscribeToken(userID uuid.UUID, newToken string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Concurrent calls may race to create the token; the first one wins.
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.EmailUnsubscribeToken{UserID: userID, Token: newToken}).Error; err != nil {
+		return "", err
+	}
+	var token models.EmailUnsubscribeToken
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&token).Error
+	return token.Token, err
+}
+
+func (r *mailRepository) GetUserIDByUnsubscribeToken(token string) (uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var unsubscribe models.EmailUnsubscribeToken
+	err := r.db.WithContext(ctx).Where("token = ?", token).First(&unsubscribe).Error
+	return unsubscribe.UserID, err
+}
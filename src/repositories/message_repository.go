@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// UnreadCount is the number of unread messages in one conversation.
+type UnreadCount struct {
+	ConversationID uuid.UUID
+	Count          int64
+}
+
+// MessageRepository defines the methods to interact with conversations, messages and
+// read receipts.
+type MessageRepository interface {
+	// Conversations
+	GetOrCreateDirectConversation(userA, userB uuid.UUID) (models.Conversation, error)
+	GetOrCreateLessonConversation(lessonID uuid.UUID) (models.Conversation, error)
+	GetConversationByID(conversationID uuid.UUID) (models.Conversation, error)
+	GetConversationsForUser(userID uuid.UUID) ([]models.Conversation, error)
+	UsersShareContext(userA, userB uuid.UUID) (bool, error)
+
+	// Messages
+	CreateMessage(message *models.Message) error
+	GetMessages(conversationID uuid.UUID, before *time.Time, limit int, hiddenSenders []uuid.UUID) ([]models.Message, error)
+	GetLastMessages(conversationIDs []uuid.UUID) ([]models.Message, error)
+
+	// Read receipts
+	GetReadReceipts(conversationID uuid.UUID) ([]models.ConversationRead, error)
+	MarkRead(conversationID, userID uuid.UUID, at time.Time) error
+	CountUnread(userID uuid.UUID, conversationIDs []uuid.UUID) ([]UnreadCount, error)
+}
+
+type messageRepository struct {
+	db *gorm.DB
+}
+
+// NewMessageRepository creates a new instance of MessageRepository.
+func NewMessageRepository(db *gorm.DB) MessageRepository {
+	return &messageRepository{db: db}
+}
+
+// GetOrCreateDirectConversation returns the direct conversation between two users,
+// creating it the first time. The pair is stored in a stable order.
+func (r *messageRepository) GetOrCreateDirectConversation(userA, userB uuid.UUID) (models.Conversation, error) {
+	if userB.String() < userA.String() {
+		userA, userB = userB, userA
+	}
+	return r.getOrCreate(
+		models.Conversation{Kind: models.ConversationKindDirect, UserAID: &userA, UserBID: &userB},
+		"kind = ? AND user_a_id = ? AND user_b_id = ?", models.ConversationKindDirect, userA, userB,
+	)
+}
+
+// GetOrCreateLessonConversation returns the thread of a lesson, creating it the first time.
+func (r *messageRepository) GetOrCreateLessonConversation(lessonID uuid.UUID) (models.Conversation, error) {
+	return r.getOrCreate(
+		models.Conversation{Kind: models.ConversationKindLesson, LessonID: &lessonID},
+		"kind = ? AND lesson_id = ?", models.ConversationKindLesson, lessonID,
+	)
+}
+
+// getOrCreate inserts conversation unless one matching the query exists, relying on the
+// unique indexes so that concurrent calls end up with the same conversation.
+func (r *messageRepository) getOrCreate(conversation models.Conversation, query string, args ...interface{}) (models.Conversation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&conversation).Error; err != nil {
+		return models.Conversation{}, err
+	}
+
+	var existing models.Conversation
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&existing).Error; err != nil {
+		return models.Conversation{}, err
+	}
+	return existing, nil
+}
+
+// GetConversationByID retrieves a conversation by its ID.
+func (r *messageRepository) GetConversationByID(conversationID uuid.UUID) (models.Conversation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var conversation models.Conversation
+	if err := r.db.WithContext(ctx).First(&conversation, "id = ?", conversationID).Error; err != nil {
+		return models.Conversation{}, err
+	}
+	return conversation, nil
+}
+
+// GetConversationsForUser lists the user's direct conversations and the threads of the
+// lessons they take part in, most recently active first. Conversations without messages
+// are left out.
+func (r *messageRepository) GetConversationsForUser(userID uuid.UUID) ([]models.Conversation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var conversations []models.Conversation
+	err := r.db.WithContext(ctx).
+		Where("last_message_at IS NOT NULL").
+		Where(`(kind = ? AND (user_a_id = ? OR user_b_id = ?)) OR (kind = ? AND lesson_id IN (
+			SELECT id FROM lessons WHERE tutor_id = ?
+			UNION SELECT lesson_id FROM lesson_students WHERE user_id = ?))`,
+			models.ConversationKindDirect, userID, userID,
+			models.ConversationKindLesson, userID, userID).
+		Order("last_message_at DESC").
+		Find(&conversations).Error
+	return conversations, err
+}
+
+// UsersShareContext reports whether two users have a lesson or a course in common, in
+// any role, or a pending booking between them.
+func (r *messageRepository) UsersShareContext(userA, userB uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var shared bool
+	err := r.db.WithContext(ctx).Raw(`
+		WITH lesson_members AS (
+			SELECT id AS lesson_id, tutor_id AS user_id FROM lessons
+			UNION SELECT lesson_id, user_id FROM lesson_students
+		), course_members AS (
+			SELECT id AS course_id, tutor_id AS user_id FROM courses
+			UNION SELECT course_id, user_id FROM course_students
+		)
+		SELECT EXISTS (
+			SELECT 1 FROM lesson_members a JOIN lesson_members b ON a.lesson_id = b.lesson_id
+			WHERE a.user_id = @a AND b.user_id = @b
+		) OR EXISTS (
+			SELECT 1 FROM course_members a JOIN course_members b ON a.course_id = b.course_id
+			WHERE a.user_id = @a AND b.user_id = @b
+		) OR EXISTS (
+			SELECT 1 FROM slot_holds
+			WHERE status = @active
+				AND ((tutor_id = @a AND student_id = @b) OR (tutor_id = @b AND student_id = @a))
+		)`,
+		map[string]interface{}{"a": userA, "b": userB, "active": models.SlotHoldStatusActive},
+	).Scan(&shared).Error
+	return shared, err
+}
+
+// CreateMessage inserts a message and moves its conversation to the top of the inbox.
+func (r *messageRepository) CreateMessage(message *models.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Conversation{}).
+			Where("id = ?", message.ConversationID).
+			Update("last_message_at", message.CreatedAt).Error
+	})
+}
+
+// GetMessages returns up to limit messages of a conversation sent before the given time
+// (or the latest ones when before is nil), newest first, leaving out messages from
+// hiddenSenders.
+func (r *messageRepository) GetMessages(conversationID uuid.UUID, before *time.Time, limit int, hiddenSenders []uuid.UUID) ([]models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := r.db.WithContext(ctx).Where("conversation_id = ?", conversationID)
+	if before != nil {
+		query = query.Where("created_at < ?", *before)
+	}
+	if len(hiddenSenders) > 0 {
+		query = query.Where("sender_id NOT IN ?", hiddenSenders)
+	}
+	var messages []models.Message
+	err := query.Order("created_at DESC").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// GetLastMessages returns the latest message of each of the given conversations.
+func (r *messageRepository) GetLastMessages(conversationIDs []uuid.UUID) ([]models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var messages []models.Message
+	if len(conversationIDs) == 0 {
+		return messages, nil
+	}
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (conversation_id) * FROM messages
+			WHERE conversation_id IN ? ORDER BY conversation_id, created_at DESC`, conversationIDs).
+		Scan(&messages).Error
+	return messages, err
+}
+
+// GetReadReceipts lists how far each participant has read a conversation.
+func (r *messageRepository) GetReadReceipts(conversationID uuid.UUID) ([]models.ConversationRead, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var receipts []models.ConversationRead
+	err := r.db.WithContext(ctx).Where("conversation_id = ?", conversationID).Find(&receipts).Error
+	return receipts, err
+}
+
+// MarkRead records that the user has read the conversation up to at. A receipt never
+// moves backwards.
+func (r *messageRepository) MarkRead(conversationID, userID uuid.UUID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO conversation_reads (conversation_id, user_id, last_read_at) VALUES (?, ?, ?)
+		ON CONFLICT (conversation_id, user_id)
+		DO UPDATE SET last_read_at = GREATEST(conversation_reads.last_read_at, EXCLUDED.last_read_at)`,
+		conversationID, userID, at).Error
+}
+
+// CountUnread counts, per conversation, the messages from others the user has not read.
+// Conversations without unread messages are left out.
+func (r *messageRepository) CountUnread(userID uuid.UUID, conversationIDs []uuid.UUID) ([]UnreadCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var counts []UnreadCount
+	if len(conversationIDs) == 0 {
+		return counts, nil
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT m.conversation_id, COUNT(*) AS count
+		FROM messages m
+		LEFT JOIN conversation_reads r ON r.conversation_id = m.conversation_id AND r.user_id = ?
+		WHERE m.conversation_id IN ? AND m.sender_id <> ?
+			AND (r.last_read_at IS NULL OR m.created_at > r.last_read_at)
+		GROUP BY m.conversation_id`,
+		userID, conversationIDs, userID).Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// ModerationRepository defines the methods to interact with reports and user blocks.
+type ModerationRepository interface {
+	Transaction(fn func(repo ModerationRepository) error) error
+
+	// Report methods
+	CreateReport(report *models.Report) error
+	GetReportByID(reportID uuid.UUID) (models.Report, error)
+	LockReport(reportID uuid.UUID) (models.Report, error)
+	GetReports(status string, page, limit int) ([]models.Report, int64, error)
+	GetOpenReportByReporter(reporterID uuid.UUID, targetType string, targetID uuid.UUID) (models.Report, error)
+	UpdateReport(report *models.Report) error
+
+	// Block methods
+	CreateBlock(block *models.UserBlock) error
+	DeleteBlock(blockerID, blockedID uuid.UUID) error
+	GetBlocksByBlocker(blockerID uuid.UUID) ([]models.UserBlock, error)
+	IsBlocked(userA, userB uuid.UUID) (bool, error)
+
+	// Suspensions
+	SuspendUser(userID uuid.UUID, reason string, at time.Time) error
+}
+
+type moderationRepository struct {
+	db *gorm.DB
+}
+
+// NewModerationRepository creates a new instance of ModerationRepository.
+func NewModerationRepository(db *gorm.DB) ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *moderationRepository) Transaction(fn func(repo ModerationRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&moderationRepository{db: tx})
+	})
+}
+
+// CreateReport inserts a new Report.
+func (r *moderationRepository) CreateReport(report *models.Report) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(report).Error
+}
+
+// GetReportByID retrieves a Report with its reporter.
+func (r *moderationRepository) GetReportByID(reportID uuid.UUID) (models.Report, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var report models.Report
+	if err := r.db.WithContext(ctx).
+		Preload("Reporter").
+		First(&report, "id = ?", reportID).Error; err != nil {
+		return models.Report{}, err
+	}
+	return report, nil
+}
+
+// LockReport locks the report row until the end of the transaction and returns it. It
+// must run inside Transaction.
+func (r *moderationRepository) LockReport(reportID uuid.UUID) (models.Report, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var report models.Report
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&report, "id = ?", reportID).Error; err != nil {
+		return models.Report{}, err
+	}
+	return report, nil
+}
+
+// GetReports returns a page of reports, oldest first, optionally filtered by status.
+func (r *moderationRepository) GetReports(status string, page, limit int) ([]models.Report, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var reports []models.Report
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Report{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := query.
+		Preload("Reporter").
+		Order("created_at ASC").
+		Offset(offset).Limit(limit).
+		Find(&reports).Error; err != nil {
+		return nil, 0, err
+	}
+	return reports, total, nil
+}
+
+// GetOpenReportByReporter finds an open report already filed by the reporter against the target.
+func (r *moderationRepository) GetOpenReportByReporter(reporterID uuid.UUID, targetType string, targetID uuid.UUID) (models.Report, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var report models.Report
+	if err := r.db.WithContext(ctx).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?",
+			reporterID, targetType, targetID, models.ReportStatusOpen).
+		First(&report).Error; err != nil {
+		return models.Report{}, err
+	}
+	return report, nil
+}
+
+// UpdateReport saves changes to an existing Report.
+func (r *moderationRepository) UpdateReport(report *models.Report) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Omit("Reporter").Save(report).Error
+}
+
+// CreateBlock inserts a new UserBlock.
+func (r *moderationRepository) CreateBlock(block *models.UserBlock) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(block).Error
+}
+
+// DeleteBlock removes the block placed by blockerID on blockedID.
+func (r *moderationRepository) DeleteBlock(blockerID, blockedID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&models.UserBlock{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetBlocksByBlocker lists the users blocked by blockerID.
+func (r *moderationRepository) GetBlocksByBlocker(blockerID uuid.UUID) ([]models.UserBlock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var blocks []models.UserBlock
+	if err := r.db.WithContext(ctx).
+		Preload("Blocked").
+		Where("blocker_id = ?", blockerID).
+		Order("created_at DESC").
+		Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// IsBlocked reports whether either user has blocked the other.
+func (r *moderationRepository) IsBlocked(userA, userB uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)",
+			userA, userB, userB, userA).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SuspendUser suspends the user for reason as of at, unless they are already suspended.
+func (r *moderationRepository) SuspendUser(userID uuid.UUID, reason string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND suspended_at IS NULL", userID).
+		Updates(map[string]interface{}{"suspended_at": at, "suspension_reason": reason}).Error
+}
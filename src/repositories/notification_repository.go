@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// NotificationRepository defines the methods to interact with notifications and the
+// users' notification preferences.
+type NotificationRepository interface {
+	// Notifications
+	CreateNotification(notification *models.Notification) error
+	GetNotifications(userID uuid.UUID, unreadOnly bool, page, limit int) ([]models.Notification, int64, error)
+	CountUnread(userID uuid.UUID) (int64, error)
+	MarkRead(notificationID, userID uuid.UUID, at time.Time) (bool, error)
+	MarkAllRead(userID uuid.UUID, at time.Time) error
+
+	// Email delivery
+	GetDueEmails(now time.Time, limit int) ([]models.Notification, error)
+	SetEmailStatus(notificationID uuid.UUID, status string) error
+
+	// Preferences
+	GetPreferences(userID uuid.UUID) (models.NotificationPreferences, error)
+	SavePreferences(preferences *models.NotificationPreferences) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new instance of NotificationRepository.
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) CreateNotification(notification *models.Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+// GetNotifications returns a page of the user's in-app notifications, newest first, and
+// how many there are in total.
+func (r *notificationRepository) GetNotifications(userID uuid.UUID, unreadOnly bool, page, limit int) ([]models.Notification, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var notifications []models.Notification
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND in_app", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := query.
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (r *notificationRepository) CountUnread(userID uuid.UUID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND in_app AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications as read. It returns false when the user
+// has no such notification.
+func (r *notificationRepository) MarkRead(notificationID, userID uuid.UUID, at time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND in_app", notificationID, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", at))
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *notificationRepository) MarkAllRead(userID uuid.UUID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND in_app AND read_at IS NULL", userID).
+		Update("read_at", at).Error
+}
+
+// GetDueEmails returns up to limit notifications whose email is pending and due, oldest first.
+func (r *notificationRepository) GetDueEmails(now time.Time, limit int) ([]models.Notification, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var notifications []models.Notification
+	err := r.db.WithContext(ctx).
+		Where("email_status = ? AND email_due_at <= ?", models.NotificationEmailPending, now).
+		Order("email_due_at ASC").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) SetEmailStatus(notificationID uuid.UUID, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("id = ?", notificationID).
+		Update("email_status", status).Error
+}
+
+// GetPreferences returns the user's saved preferences, or gorm.ErrRecordNotFound.
+func (r *notificationRepository) GetPreferences(userID uuid.UUID) (models.NotificationPreferences, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var preferences models.NotificationPreferences
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&preferences).Error
+	return preferences, err
+}
+
+// SavePreferences creates or replaces the user's preferences.
+func (r *notificationRepository) SavePreferences(preferences *models.NotificationPreferences) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Select all columns, or disabled channels would be inserted as their default.
+	return r.db.WithContext(ctx).
+		Select("*").
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(preferences).Error
+}
@@ -0,0 +1,280 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// QuizRepository defines the methods to interact with quizzes, their questions and the
+// students' attempts. The Lock methods must run inside Transaction.
+type QuizRepository interface {
+	Transaction(fn func(repo QuizRepository) error) error
+
+	// Quizzes
+	CreateQuiz(quiz *models.Quiz) error
+	GetQuizByID(quizID uuid.UUID) (models.Quiz, error)
+	GetQuizzesByCourseID(courseID uuid.UUID) ([]models.Quiz, error)
+	GetQuizzesByLessonID(lessonID uuid.UUID) ([]models.Quiz, error)
+	UpdateQuiz(quiz *models.Quiz) error
+	DeleteQuiz(quizID uuid.UUID) error
+
+	// Attempts
+	CountAttempts(quizID uuid.UUID) (int64, error)
+	LockStudentAttempts(quizID, studentID uuid.UUID) ([]models.QuizAttempt, error)
+	LockAttempt(attemptID uuid.UUID) (models.QuizAttempt, error)
+	CreateAttempt(attempt *models.QuizAttempt) error
+	UpdateAttempt(attempt *models.QuizAttempt) error
+	CreateAnswers(answers []models.QuizAnswer) error
+	UpdateAnswer(answer *models.QuizAnswer) error
+	GetAttemptByID(attemptID uuid.UUID) (models.QuizAttempt, error)
+	GetAttemptsByQuizID(quizID uuid.UUID) ([]models.QuizAttempt, error)
+	GetGradedAttemptsByCourseID(courseID uuid.UUID) ([]models.QuizAttempt, error)
+	ExpireAttempts(before time.Time) (int64, error)
+}
+
+type quizRepository struct {
+	db *gorm.DB
+}
+
+// NewQuizRepository creates a new instance of QuizRepository.
+func NewQuizRepository(db *gorm.DB) QuizRepository {
+	return &quizRepository{db: db}
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *quizRepository) Transaction(fn func(repo QuizRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&quizRepository{db: tx})
+	})
+}
+
+// CreateQuiz inserts a new quiz together with its questions.
+func (r *quizRepository) CreateQuiz(quiz *models.Quiz) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(quiz).Error
+}
+
+// GetQuizByID retrieves a quiz with its questions in order.
+func (r *quizRepository) GetQuizByID(quizID uuid.UUID) (models.Quiz, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var quiz models.Quiz
+	if err := r.db.WithContext(ctx).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		First(&quiz, "id = ?", quizID).Error; err != nil {
+		return models.Quiz{}, err
+	}
+	return quiz, nil
+}
+
+// GetQuizzesByCourseID lists the quizzes of a course, oldest first.
+func (r *quizRepository) GetQuizzesByCourseID(courseID uuid.UUID) ([]models.Quiz, error) {
+	return r.getQuizzes("course_id = ?", courseID)
+}
+
+// GetQuizzesByLessonID lists the quizzes of a lesson, oldest first.
+func (r *quizRepository) GetQuizzesByLessonID(lessonID uuid.UUID) ([]models.Quiz, error) {
+	return r.getQuizzes("lesson_id = ?", lessonID)
+}
+
+func (r *quizRepository) getQuizzes(query string, args ...interface{}) ([]models.Quiz, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var quizzes []models.Quiz
+	err := r.db.WithContext(ctx).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where(query, args...).
+		Order("created_at ASC").
+		Find(&quizzes).Error
+	return quizzes, err
+}
+
+// UpdateQuiz saves changes to a quiz and replaces its questions with quiz.Questions.
+func (r *quizRepository) UpdateQuiz(quiz *models.Quiz) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(quiz).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.QuizQuestion{}, "quiz_id = ?", quiz.ID).Error; err != nil {
+			return err
+		}
+		for i := range quiz.Questions {
+			quiz.Questions[i].ID = uuid.Nil
+			quiz.Questions[i].QuizID = quiz.ID
+		}
+		if len(quiz.Questions) == 0 {
+			return nil
+		}
+		return tx.Create(&quiz.Questions).Error
+	})
+}
+
+// DeleteQuiz removes a quiz and its questions.
+func (r *quizRepository) DeleteQuiz(quizID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.QuizQuestion{}, "quiz_id = ?", quizID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Quiz{}, "id = ?", quizID).Error
+	})
+}
+
+// CountAttempts counts every attempt at the quiz, in any status.
+func (r *quizRepository) CountAttempts(quizID uuid.UUID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.QuizAttempt{}).
+		Where("quiz_id = ?", quizID).
+		Count(&count).Error
+	return count, err
+}
+
+// LockStudentAttempts serializes starting attempts by the student at the quiz with an
+// advisory lock, and returns their attempts so far, oldest first.
+func (r *quizRepository) LockStudentAttempts(quizID, studentID uuid.UUID) ([]models.QuizAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key := fmt.Sprintf("quiz:%s:%s", quizID, studentID)
+	if err := r.db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+		return nil, err
+	}
+
+	var attempts []models.QuizAttempt
+	err := r.db.WithContext(ctx).
+		Where("quiz_id = ? AND student_id = ?", quizID, studentID).
+		Order("started_at ASC").
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// LockAttempt locks the attempt row until the end of the transaction and returns the
+// attempt with its answers.
+func (r *quizRepository) LockAttempt(attemptID uuid.UUID) (models.QuizAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var attempt models.QuizAttempt
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&attempt, "id = ?", attemptID).Error; err != nil {
+		return models.QuizAttempt{}, err
+	}
+	if err := r.db.WithContext(ctx).
+		Where("attempt_id = ?", attemptID).
+		Find(&attempt.Answers).Error; err != nil {
+		return models.QuizAttempt{}, err
+	}
+	return attempt, nil
+}
+
+// CreateAttempt inserts a new attempt.
+func (r *quizRepository) CreateAttempt(attempt *models.QuizAttempt) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(attempt).Error
+}
+
+// UpdateAttempt saves changes to the attempt row; its answers are left untouched.
+func (r *quizRepository) UpdateAttempt(attempt *models.QuizAttempt) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(attempt).Error
+}
+
+// CreateAnswers inserts the answers of an attempt.
+func (r *quizRepository) CreateAnswers(answers []models.QuizAnswer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if len(answers) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&answers).Error
+}
+
+// UpdateAnswer saves changes to an answer, such as the tutor's grade.
+func (r *quizRepository) UpdateAnswer(answer *models.QuizAnswer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Save(answer).Error
+}
+
+// GetAttemptByID retrieves an attempt with its student and answers.
+func (r *quizRepository) GetAttemptByID(attemptID uuid.UUID) (models.QuizAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var attempt models.QuizAttempt
+	if err := r.db.WithContext(ctx).
+		Preload("Student").
+		Preload("Answers").
+		First(&attempt, "id = ?", attemptID).Error; err != nil {
+		return models.QuizAttempt{}, err
+	}
+	return attempt, nil
+}
+
+// GetAttemptsByQuizID lists the attempts at a quiz with students and answers, oldest first.
+func (r *quizRepository) GetAttemptsByQuizID(quizID uuid.UUID) ([]models.QuizAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var attempts []models.QuizAttempt
+	err := r.db.WithContext(ctx).
+		Preload("Student").
+		Preload("Answers").
+		Where("quiz_id = ?", quizID).
+		Order("started_at ASC").
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// GetGradedAttemptsByCourseID lists the graded attempts at the quizzes of a course.
+func (r *quizRepository) GetGradedAttemptsByCourseID(courseID uuid.UUID) ([]models.QuizAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var attempts []models.QuizAttempt
+	err := r.db.WithContext(ctx).
+		Joins("JOIN quizzes ON quizzes.id = quiz_attempts.quiz_id").
+		Where("quizzes.course_id = ? AND quiz_attempts.status = ?", courseID, models.QuizAttemptStatusGraded).
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// ExpireAttempts marks attempts still in progress whose time ran out before the given
+// time as expired.
+func (r *quizRepository) ExpireAttempts(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Model(&models.QuizAttempt{}).
+		Where("status = ? AND expires_at < ?", models.QuizAttemptStatusInProgress, before).
+		Update("status", models.QuizAttemptStatusExpired)
+	return result.RowsAffected, result.Error
+}
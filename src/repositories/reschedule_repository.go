@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vibely-backend/src/models"
+)
+
+// RescheduleRepository defines the methods to interact with lesson reschedule proposals.
+type RescheduleRepository interface {
+	Transaction(fn func(repo RescheduleRepository) error) error
+
+	CreateProposal(proposal *models.RescheduleProposal) error
+	GetProposalByID(proposalID uuid.UUID) (models.RescheduleProposal, error)
+	GetProposalsByLesson(lessonID uuid.UUID) ([]models.RescheduleProposal, error)
+	GetPendingProposalForLesson(lessonID uuid.UUID) (models.RescheduleProposal, error)
+	UpdateProposalIfStatus(proposal *models.RescheduleProposal, fromStatus string) (bool, error)
+	ExpirePendingProposals(now time.Time) (int64, error)
+
+	LockProposal(proposalID uuid.UUID) (models.RescheduleProposal, error)
+	SaveAcceptance(acceptance *models.RescheduleAcceptance) error
+	GetAcceptances(proposalID uuid.UUID) ([]models.RescheduleAcceptance, error)
+}
+
+type rescheduleRepository struct {
+	db *gorm.DB
+}
+
+// NewRescheduleRepository creates a new instance of RescheduleRepository.
+func NewRescheduleRepository(db *gorm.DB) RescheduleRepository {
+	return &rescheduleRepository{db: db}
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *rescheduleRepository) Transaction(fn func(repo RescheduleRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&rescheduleRepository{db: tx})
+	})
+}
+
+// CreateProposal inserts a new proposal together with its options.
+func (r *rescheduleRepository) CreateProposal(proposal *models.RescheduleProposal) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(proposal).Error
+}
+
+// GetProposalByID retrieves a proposal with its options.
+func (r *rescheduleRepository) GetProposalByID(proposalID uuid.UUID) (models.RescheduleProposal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var proposal models.RescheduleProposal
+	if err := r.db.WithContext(ctx).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("start_time ASC") }).
+		Preload("Acceptances").
+		First(&proposal, "id = ?", proposalID).Error; err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	return proposal, nil
+}
+
+// GetProposalsByLesson returns all proposals of a lesson, newest first.
+func (r *rescheduleRepository) GetProposalsByLesson(lessonID uuid.UUID) ([]models.RescheduleProposal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var proposals []models.RescheduleProposal
+	err := r.db.WithContext(ctx).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("start_time ASC") }).
+		Preload("Acceptances").
+		Where("lesson_id = ?", lessonID).
+		Order("created_at DESC").
+		Find(&proposals).Error
+	return proposals, err
+}
+
+// GetPendingProposalForLesson returns the lesson's pending proposal, or
+// gorm.ErrRecordNotFound if there is none.
+func (r *rescheduleRepository) GetPendingProposalForLesson(lessonID uuid.UUID) (models.RescheduleProposal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var proposal models.RescheduleProposal
+	if err := r.db.WithContext(ctx).
+		Where("lesson_id = ? AND status = ?", lessonID, models.RescheduleStatusPending).
+		First(&proposal).Error; err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	return proposal, nil
+}
+
+// UpdateProposalIfStatus saves the proposal's response fields only if it is still in
+// fromStatus, and reports whether it was updated.
+func (r *rescheduleRepository) UpdateProposalIfStatus(proposal *models.RescheduleProposal, fromStatus string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Model(&models.RescheduleProposal{}).
+		Where("id = ? AND status = ?", proposal.ID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":             proposal.Status,
+			"responded_by_id":    proposal.RespondedByID,
+			"responded_at":       proposal.RespondedAt,
+			"response_note":      proposal.ResponseNote,
+			"accepted_option_id": proposal.AcceptedOptionID,
+			"updated_at":         time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ExpirePendingProposals marks every pending proposal past its ExpiresAt as expired and
+// returns how many were expired.
+func (r *rescheduleRepository) ExpirePendingProposals(now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Model(&models.RescheduleProposal{}).
+		Where("status = ? AND expires_at <= ?", models.RescheduleStatusPending, now).
+		Updates(map[string]interface{}{
+			"status":     models.RescheduleStatusExpired,
+			"updated_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// LockProposal reads a proposal and locks its row until the end of the transaction, so
+// concurrent answers to it are applied one at a time. It must run inside Transaction.
+func (r *rescheduleRepository) LockProposal(proposalID uuid.UUID) (models.RescheduleProposal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var proposal models.RescheduleProposal
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&proposal, "id = ?", proposalID).Error; err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	return proposal, nil
+}
+
+// SaveAcceptance records a student's acceptance of a proposal, replacing the option they
+// accepted before, if any.
+func (r *rescheduleRepository) SaveAcceptance(acceptance *models.RescheduleAcceptance) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "proposal_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"option_id"}),
+		}).
+		Create(acceptance).Error
+}
+
+// GetAcceptances returns the acceptances recorded for a proposal.
+func (r *rescheduleRepository) GetAcceptances(proposalID uuid.UUID) ([]models.RescheduleAcceptance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var acceptances []models.RescheduleAcceptance
+	err := r.db.WithContext(ctx).
+		Where("proposal_id = ?", proposalID).
+		Find(&acceptances).Error
+	return acceptances, err
+}
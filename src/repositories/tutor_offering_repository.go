@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"vibely-backend/src/models"
+)
+
+// TutorOfferingRepository defines the methods to interact with tutors' lesson offerings.
+type TutorOfferingRepository interface {
+	CreateOffering(offering *models.TutorOffering) error
+	GetOfferingByID(offeringID uuid.UUID) (models.TutorOffering, error)
+	GetOfferingsByTutorID(tutorID uuid.UUID, activeOnly bool) ([]models.TutorOffering, error)
+	UpdateOffering(offering *models.TutorOffering) error
+}
+
+type tutorOfferingRepository struct {
+	db *gorm.DB
+}
+
+// NewTutorOfferingRepository creates a new instance of TutorOfferingRepository.
+func NewTutorOfferingRepository(db *gorm.DB) TutorOfferingRepository {
+	return &tutorOfferingRepository{db: db}
+}
+
+// CreateOffering inserts a new offering.
+func (r *tutorOfferingRepository) CreateOffering(offering *models.TutorOffering) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(offering).Error
+}
+
+// GetOfferingByID retrieves an offering by its ID.
+func (r *tutorOfferingRepository) GetOfferingByID(offeringID uuid.UUID) (models.TutorOffering, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var offering models.TutorOffering
+	if err := r.db.WithContext(ctx).First(&offering, "id = ?", offeringID).Error; err != nil {
+		return models.TutorOffering{}, err
+	}
+	return offering, nil
+}
+
+// GetOfferingsByTutorID lists the tutor's offerings, shortest first.
+func (r *tutorOfferingRepository) GetOfferingsByTutorID(tutorID uuid.UUID, activeOnly bool) ([]models.TutorOffering, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := r.db.WithContext(ctx).Where("tutor_id = ?", tutorID)
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	var offerings []models.TutorOffering
+	err := query.Order("duration_minutes ASC, price ASC").Find(&offerings).Error
+	return offerings, err
+}
+
+// UpdateOffering saves changes to an existing offering.
+func (r *tutorOfferingRepository) UpdateOffering(offering *models.TutorOffering) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Save(offering).Error
+}
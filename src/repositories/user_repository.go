@@ -14,6 +14,7 @@ type UserRepository interface {
 	GetUserByID(userID uuid.UUID) (models.User, error)
 	GetUserByEmail(email string) (models.User, error)
 	GetTutors(filters models.TutorFilters) ([]models.User, int64, error)
+	UpdateUser(user *models.User) error
 }
 type userRepository struct {
 	db *gorm.DB
@@ -88,3 +89,11 @@ func (r *userRepository) GetTutors(filters models.TutorFilters) ([]models.User,
 
 	return tutors, total, nil
 }
+
+// UpdateUser saves changes to an existing user.
+func (r *userRepository) UpdateUser(user *models.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Save(user).Error
+}
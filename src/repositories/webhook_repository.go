@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"vibely-backend/src/models"
+)
+
+// WebhookRepository defines the methods to interact with webhook subscriptions and their
+// delivery log.
+type WebhookRepository interface {
+	// Subscriptions
+	CreateSubscription(subscription *models.WebhookSubscription) error
+	GetSubscriptionByID(subscriptionID uuid.UUID) (models.WebhookSubscription, error)
+	GetSubscriptionsByUserID(userID uuid.UUID) ([]models.WebhookSubscription, error)
+	GetActiveSubscriptionsForUsers(userIDs []uuid.UUID) ([]models.WebhookSubscription, error)
+	UpdateSubscription(subscription *models.WebhookSubscription) error
+	DeleteSubscription(subscriptionID uuid.UUID) error
+
+	// Deliveries
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	GetDeliveryByID(deliveryID uuid.UUID) (models.WebhookDelivery, error)
+	GetDeliveries(subscriptionID uuid.UUID, page, limit int) ([]models.WebhookDelivery, int64, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	DeleteDeliveriesBefore(before time.Time) (int64, error)
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository.
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+func (r *webhookRepository) GetSubscriptionByID(subscriptionID uuid.UUID) (models.WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var subscription models.WebhookSubscription
+	err := r.db.WithContext(ctx).Where("id = ?", subscriptionID).First(&subscription).Error
+	return subscription, err
+}
+
+func (r *webhookRepository) GetSubscriptionsByUserID(userID uuid.UUID) ([]models.WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var subscriptions []models.WebhookSubscription
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// GetActiveSubscriptionsForUsers returns the active subscriptions of any of the users.
+func (r *webhookRepository) GetActiveSubscriptionsForUsers(userIDs []uuid.UUID) ([]models.WebhookSubscription, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var subscriptions []models.WebhookSubscription
+	err := r.db.WithContext(ctx).
+		Where("user_id IN ? AND active", userIDs).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *webhookRepository) UpdateSubscription(subscription *models.WebhookSubscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Save(subscription).Error
+}
+
+// DeleteSubscription deletes the subscription along with its delivery log.
+func (r *webhookRepository) DeleteSubscription(subscriptionID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", subscriptionID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", subscriptionID).Delete(&models.WebhookSubscription{}).Error
+	})
+}
+
+func (r *webhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Create(delivery).Error
+}
+
+func (r *webhookRepository) GetDeliveryByID(deliveryID uuid.UUID) (models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var delivery models.WebhookDelivery
+	err := r.db.WithContext(ctx).Where("id = ?", deliveryID).First(&delivery).Error
+	return delivery, err
+}
+
+// GetDeliveries returns a page of the subscription's deliveries, newest first, and how
+// many there are in total.
+func (r *webhookRepository) GetDeliveries(subscriptionID uuid.UUID, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("subscription_id = ?", subscriptionID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := query.
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Save(delivery).Error
+}
+
+// DeleteDeliveriesBefore deletes the finished deliveries created before before.
+func (r *webhookRepository) DeleteDeliveriesBefore(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Where("status <> ? AND created_at < ?", models.WebhookDeliveryPending, before).
+		Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
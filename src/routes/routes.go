@@ -13,8 +13,22 @@ func Setup(app *app.Application) *gin.Engine {
 	// Initialize Handlers
 	userHandler := handlers.NewUserHandler(app)
 	lessonHandler := handlers.NewLessonHandler(app)
+	lessonMaterialHandler := handlers.NewLessonMaterialHandler(app)
+	homeworkHandler := handlers.NewHomeworkHandler(app)
+	quizHandler := handlers.NewQuizHandler(app)
+	messageHandler := handlers.NewMessageHandler(app)
+	eventHandler := handlers.NewEventHandler(app)
+	notificationHandler := handlers.NewNotificationHandler(app)
+	webhookHandler := handlers.NewWebhookHandler(app)
+	mailHandler := handlers.NewMailHandler(app)
 	tutorHandler := handlers.NewTutorHandler(app)
 	courseHandler := handlers.NewCourseHandler(app)
+	enrollmentHandler := handlers.NewEnrollmentHandler(app)
+	moderationHandler := handlers.NewModerationHandler(app)
+	lessonSeriesHandler := handlers.NewLessonSeriesHandler(app)
+	rescheduleHandler := handlers.NewRescheduleHandler(app)
+	bookingHandler := handlers.NewBookingHandler(app)
+	tutorOfferingHandler := handlers.NewTutorOfferingHandler(app)
 
 	// Apply Global Middleware
 	router.Use(middleware.EnableCORS)
@@ -31,6 +45,11 @@ func Setup(app *app.Application) *gin.Engine {
 		router.POST("/api/token/refresh-token", userHandler.RefreshTokens)
 
 		publicAPI.GET("/tutors/:tutorID/availability", tutorHandler.GetAvailability)
+		publicAPI.GET("/tutors/:tutorID/offerings", tutorOfferingHandler.GetOfferings)
+
+		// Unsubscribe links in emails and bounce reports from the mail provider.
+		publicAPI.POST("/mail/unsubscribe", mailHandler.Unsubscribe)
+		publicAPI.POST("/mail/bounces", mailHandler.ReportBounce)
 		//router.GET("/api/auth/logout", userHandler.Logout)
 	}
 	// Protected API Routes
@@ -41,21 +60,98 @@ func Setup(app *app.Application) *gin.Engine {
 		authorized.GET("/user/:userID", userHandler.GetUserById)
 		authorized.POST("/lessons", lessonHandler.CreateLesson)
 		authorized.GET("/lessons/:lessonID", lessonHandler.GetLesson)
+		authorized.GET("/lessons/:lessonID/history", lessonHandler.GetLessonHistory)
 		authorized.PATCH("/lessons/:lessonID/confirm", lessonHandler.ConfirmLesson)
 		authorized.PATCH("/lessons/:lessonID/start", lessonHandler.StartLesson)
 		authorized.PATCH("/lessons/:lessonID/complete", lessonHandler.CompleteLesson)
 		authorized.PATCH("/lessons/:lessonID/fail", lessonHandler.FailLesson)
 		authorized.PATCH("/lessons/:lessonID/cancel", lessonHandler.CancelLesson)
-		authorized.PATCH("/lessons/:lessonID/postpone", lessonHandler.PostponeLesson)
+		authorized.PATCH("/lessons/:lessonID/dispute", lessonHandler.DisputeLesson)
+		authorized.POST("/lessons/:lessonID/enroll", enrollmentHandler.JoinLesson)
+		authorized.DELETE("/lessons/:lessonID/enroll", enrollmentHandler.LeaveLesson)
+		authorized.GET("/lessons/:lessonID/waitlist", enrollmentHandler.GetLessonWaitlist)
+		authorized.PUT("/lessons/:lessonID/attendance", lessonHandler.RecordAttendance)
+
+		// Lesson notes, homework and attachments
+		authorized.GET("/lessons/:lessonID/materials", lessonMaterialHandler.GetMaterials)
+		authorized.POST("/lessons/:lessonID/notes", lessonMaterialHandler.AddNote)
+		authorized.PUT("/notes/:noteID", lessonMaterialHandler.UpdateNote)
+		authorized.DELETE("/notes/:noteID", lessonMaterialHandler.DeleteNote)
+		authorized.POST("/lessons/:lessonID/homework", lessonMaterialHandler.AddHomework)
+		authorized.PUT("/homework/:homeworkID", lessonMaterialHandler.UpdateHomework)
+		authorized.DELETE("/homework/:homeworkID", lessonMaterialHandler.DeleteHomework)
+		authorized.POST("/lessons/:lessonID/attachments", lessonMaterialHandler.UploadAttachment)
+		authorized.GET("/attachments/:attachmentID", lessonMaterialHandler.DownloadAttachment)
+		authorized.DELETE("/attachments/:attachmentID", lessonMaterialHandler.DeleteAttachment)
+
+		// Homework submissions and grading
+		authorized.POST("/homework/:homeworkID/submissions", homeworkHandler.SubmitHomework)
+		authorized.GET("/homework/:homeworkID/submissions", homeworkHandler.GetSubmissions)
+		authorized.GET("/submissions/:submissionID", homeworkHandler.GetSubmission)
+		authorized.PUT("/submissions/:submissionID/grade", homeworkHandler.GradeSubmission)
+		authorized.GET("/submission-files/:fileID", homeworkHandler.DownloadSubmissionFile)
+
+		// Quizzes on courses and lessons
+		authorized.POST("/quizzes", quizHandler.CreateQuiz)
+		authorized.GET("/quizzes/:quizID", quizHandler.GetQuiz)
+		authorized.PUT("/quizzes/:quizID", quizHandler.UpdateQuiz)
+		authorized.DELETE("/quizzes/:quizID", quizHandler.DeleteQuiz)
+		authorized.GET("/courses/:courseID/quizzes", quizHandler.GetCourseQuizzes)
+		authorized.GET("/lessons/:lessonID/quizzes", quizHandler.GetLessonQuizzes)
+		authorized.POST("/quizzes/:quizID/attempts", quizHandler.StartAttempt)
+		authorized.GET("/quizzes/:quizID/attempts", quizHandler.GetAttempts)
+		authorized.POST("/quiz-attempts/:attemptID/submit", quizHandler.SubmitAttempt)
+		authorized.PUT("/quiz-attempts/:attemptID/answers/:questionID/grade", quizHandler.GradeAnswer)
+
+		// Messaging
+		authorized.GET("/conversations", messageHandler.GetConversations)
+		authorized.POST("/conversations", messageHandler.StartConversation)
+		authorized.GET("/conversations/unread-count", messageHandler.GetUnreadCount)
+		authorized.GET("/lessons/:lessonID/conversation", messageHandler.GetLessonConversation)
+		authorized.GET("/conversations/:conversationID/messages", messageHandler.GetMessages)
+		authorized.POST("/conversations/:conversationID/messages", messageHandler.SendMessage)
+		authorized.POST("/conversations/:conversationID/read", messageHandler.MarkRead)
+		authorized.GET("/events", eventHandler.StreamEvents)
+		authorized.GET("/notifications", notificationHandler.GetNotifications)
+		authorized.POST("/notifications/read", notificationHandler.MarkAllNotificationsRead)
+		authorized.POST("/notifications/:notificationID/read", notificationHandler.MarkNotificationRead)
+		authorized.GET("/notifications/preferences", notificationHandler.GetPreferences)
+		authorized.PUT("/notifications/preferences", notificationHandler.UpdatePreferences)
+
+		// Webhooks send lesson and course events to the user's own systems.
+		authorized.GET("/webhooks", webhookHandler.GetWebhooks)
+		authorized.POST("/webhooks", webhookHandler.CreateWebhook)
+		authorized.PUT("/webhooks/:webhookID", webhookHandler.UpdateWebhook)
+		authorized.DELETE("/webhooks/:webhookID", webhookHandler.DeleteWebhook)
+		authorized.GET("/webhooks/:webhookID/deliveries", webhookHandler.GetDeliveries)
+		authorized.POST("/webhooks/:webhookID/deliveries/:deliveryID/redeliver", webhookHandler.RedeliverDelivery)
+
+		// Postponing a lesson proposes new times the other party must accept.
+		authorized.PATCH("/lessons/:lessonID/postpone", rescheduleHandler.ProposeReschedule)
+		authorized.POST("/lessons/:lessonID/reschedule-proposals", rescheduleHandler.ProposeReschedule)
+		authorized.GET("/lessons/:lessonID/reschedule-proposals", rescheduleHandler.GetLessonProposals)
+		authorized.PATCH("/reschedule-proposals/:proposalID/accept", rescheduleHandler.AcceptProposal)
+		authorized.PATCH("/reschedule-proposals/:proposalID/reject", rescheduleHandler.RejectProposal)
+		authorized.POST("/reschedule-proposals/:proposalID/counter", rescheduleHandler.CounterProposal)
+
+		authorized.POST("/lesson-series", lessonSeriesHandler.CreateSeries)
+		authorized.GET("/lesson-series/:seriesID", lessonSeriesHandler.GetSeries)
+		authorized.PATCH("/lesson-series/:seriesID/occurrences/:lessonID", lessonSeriesHandler.UpdateOccurrence)
+		authorized.PATCH("/lesson-series/:seriesID/occurrences/:lessonID/cancel", lessonSeriesHandler.CancelOccurrence)
 
 		authorized.GET("/user/:userID/lessons", lessonHandler.GetLessonsForUser)
 		authorized.GET("/user/:userID/tutors", lessonHandler.GetTutorsForUser)
+		authorized.GET("/user/:userID/stats", lessonHandler.GetStudentStats)
+		authorized.GET("/user/:userID/homework", homeworkHandler.GetHomeworkOverview)
 
 		authorized.POST("/courses", courseHandler.CreateCourse)
 		authorized.GET("/courses/:courseID", courseHandler.GetCourse)
 		authorized.GET("/courses", courseHandler.GetCourses)
 		authorized.GET("/user/:userID/courses", courseHandler.GetCoursesForUser)
-		authorized.POST("/courses/:courseID/enroll", courseHandler.EnrollInCourse)
+		authorized.POST("/courses/:courseID/enroll", enrollmentHandler.JoinCourse)
+		authorized.DELETE("/courses/:courseID/enroll", enrollmentHandler.LeaveCourse)
+		authorized.GET("/courses/:courseID/waitlist", enrollmentHandler.GetCourseWaitlist)
+		authorized.GET("/courses/:courseID/progress", courseHandler.GetCourseProgress)
 
 		authorized.POST("/tutors/:tutorID/weekly-schedules", tutorHandler.AddWeeklySchedule)
 		authorized.GET("/tutors/:tutorID/weekly-schedules", tutorHandler.GetWeeklySchedule)
@@ -66,7 +162,35 @@ func Setup(app *app.Application) *gin.Engine {
 		authorized.GET("/tutors/:tutorID/exceptions", tutorHandler.GetExceptions)
 
 		authorized.GET("/tutors/:tutorID/students", lessonHandler.GetStudentsForTutor)
+		authorized.POST("/tutors/:tutorID/offerings", tutorOfferingHandler.CreateOffering)
+		authorized.PUT("/tutors/:tutorID/offerings/:offeringID", tutorOfferingHandler.UpdateOffering)
+		authorized.DELETE("/tutors/:tutorID/offerings/:offeringID", tutorOfferingHandler.ArchiveOffering)
+		authorized.POST("/tutors/:tutorID/bookings", bookingHandler.CreateBooking)
+		authorized.POST("/tutors/:tutorID/holds", bookingHandler.CreateHold)
+		authorized.POST("/holds/:holdID/confirm", bookingHandler.ConfirmHold)
+		authorized.DELETE("/holds/:holdID", bookingHandler.ReleaseHold)
+		authorized.GET("/tutors/:tutorID/cancellation-policy", tutorHandler.GetCancellationPolicy)
+		authorized.PUT("/tutors/:tutorID/cancellation-policy", tutorHandler.UpdateCancellationPolicy)
+		authorized.GET("/tutors/:tutorID/booking-rules", tutorHandler.GetBookingRules)
+		authorized.GET("/tutors/:tutorID/stats", tutorHandler.GetTutorStats)
+		authorized.GET("/tutors/:tutorID/trial-eligibility", tutorHandler.GetTrialEligibility)
+		authorized.PUT("/tutors/:tutorID/booking-rules", tutorHandler.UpdateBookingRules)
+
+		authorized.POST("/reports", moderationHandler.CreateReport)
+		authorized.GET("/user/me/blocks", moderationHandler.GetBlockedUsers)
+		authorized.POST("/user/:userID/block", moderationHandler.BlockUser)
+		authorized.DELETE("/user/:userID/block", moderationHandler.UnblockUser)
 
 	}
+	// Admin Routes
+	admin := authorized.Group("/admin")
+	admin.Use(middleware.RequireAdmin)
+	{
+		admin.GET("/reports", moderationHandler.GetReports)
+		admin.PATCH("/reports/:reportID/resolve", moderationHandler.ResolveReport)
+		admin.PATCH("/reports/:reportID/dismiss", moderationHandler.DismissReport)
+		admin.PATCH("/user/:userID/suspend", moderationHandler.SuspendUser)
+		admin.PATCH("/user/:userID/unsuspend", moderationHandler.UnsuspendUser)
+	}
 	return router
 }
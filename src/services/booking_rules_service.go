@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// ErrBookingRule is returned when a booking breaks one of the tutor's booking rules.
+var ErrBookingRule = errors.New("booking breaks the tutor's booking rules")
+
+// Limits of the values tutors may set.
+const (
+	maxBufferMinutes    = 120
+	maxMinNoticeHours   = 7 * 24
+	maxBookingDaysAhead = 365
+)
+
+// validSlotGranularities are the lesson start steps tutors can choose from; each divides an hour.
+var validSlotGranularities = map[int]bool{5: true, 10: true, 15: true, 20: true, 30: true, 60: true}
+
+// BookingRulesService defines business logic for tutors' booking rules.
+type BookingRulesService interface {
+	GetRules(tutorID uuid.UUID) (models.TutorBookingRules, error)
+	UpdateRules(rules models.TutorBookingRules) (models.TutorBookingRules, error)
+}
+
+type bookingRulesService struct {
+	repo     repositories.BookingRulesRepository
+	userRepo repositories.UserRepository
+}
+
+// NewBookingRulesService creates a new instance of BookingRulesService.
+func NewBookingRulesService(repo repositories.BookingRulesRepository, userRepo repositories.UserRepository) BookingRulesService {
+	return &bookingRulesService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// GetRules returns the tutor's rules, or the default ones if the tutor has not set any.
+func (s *bookingRulesService) GetRules(tutorID uuid.UUID) (models.TutorBookingRules, error) {
+	if err := s.ensureTutor(tutorID); err != nil {
+		return models.TutorBookingRules{}, err
+	}
+	return getBookingRules(s.repo, tutorID)
+}
+
+// UpdateRules sets the tutor's rules.
+func (s *bookingRulesService) UpdateRules(rules models.TutorBookingRules) (models.TutorBookingRules, error) {
+	if err := s.ensureTutor(rules.TutorID); err != nil {
+		return models.TutorBookingRules{}, err
+	}
+	switch {
+	case rules.BufferMinutes < 0 || rules.BufferMinutes > maxBufferMinutes:
+		return models.TutorBookingRules{}, fmt.Errorf("buffer_minutes must be between 0 and %d", maxBufferMinutes)
+	case rules.MinNoticeHours < 0 || rules.MinNoticeHours > maxMinNoticeHours:
+		return models.TutorBookingRules{}, fmt.Errorf("min_notice_hours must be between 0 and %d", maxMinNoticeHours)
+	case rules.MaxDaysAhead < 0 || rules.MaxDaysAhead > maxBookingDaysAhead:
+		return models.TutorBookingRules{}, fmt.Errorf("max_days_ahead must be between 0 and %d", maxBookingDaysAhead)
+	case rules.MaxLessonsPerDay < 0:
+		return models.TutorBookingRules{}, errors.New("max_lessons_per_day cannot be negative")
+	case !validSlotGranularities[rules.SlotGranularityMinutes]:
+		return models.TutorBookingRules{}, errors.New("slot_granularity_minutes must be one of 5, 10, 15, 20, 30 or 60")
+	}
+
+	rules.ID = uuid.Nil
+	if err := s.repo.UpsertRules(&rules); err != nil {
+		return models.TutorBookingRules{}, err
+	}
+	return s.repo.GetRulesByTutorID(rules.TutorID)
+}
+
+func (s *bookingRulesService) ensureTutor(tutorID uuid.UUID) error {
+	tutor, err := s.userRepo.GetUserByID(tutorID)
+	if err != nil {
+		return errors.New("tutor not found")
+	}
+	if tutor.Role != models.UserRoleTutor {
+		return errors.New("user is not a tutor")
+	}
+	return nil
+}
+
+// getBookingRules returns the tutor's rules, falling back to the default ones.
+func getBookingRules(repo repositories.BookingRulesRepository, tutorID uuid.UUID) (models.TutorBookingRules, error) {
+	rules, err := repo.GetRulesByTutorID(tutorID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultBookingRules(tutorID), nil
+	}
+	return rules, err
+}
+
+// enforceBookingRules checks a booking of the tutor's [start, end) made at now against the
+// tutor's rules: notice, horizon and start granularity, and, against the tutor's other
+// lessons, the buffer and the daily cap. Like reserveLessonSlot it must run inside a
+// LessonRepository transaction, after the tutor has been locked.
+func enforceBookingRules(tx repositories.LessonRepository, rules models.TutorBookingRules, studentIDs []uuid.UUID, start, end time.Time, excludeLessonID uuid.UUID, now time.Time) error {
+	if start.Before(rules.EarliestStart(now)) {
+		return fmt.Errorf("%w: lessons must be booked at least %d hours ahead", ErrBookingRule, rules.MinNoticeHours)
+	}
+	if before, limited := rules.StartsBefore(now); limited && !start.Before(before) {
+		return fmt.Errorf("%w: lessons can be booked at most %d days ahead", ErrBookingRule, rules.MaxDaysAhead)
+	}
+	if !rules.AlignStart(start).Equal(start) {
+		return fmt.Errorf("%w: lessons start every %d minutes", ErrBookingRule, rules.SlotGranularityMinutes)
+	}
+
+	if buffer := rules.Buffer(); buffer > 0 {
+		nearby, err := tx.GetOverlappingLessons([]uuid.UUID{rules.TutorID}, start.Add(-buffer), end.Add(buffer), excludeLessonID)
+		if err != nil {
+			return err
+		}
+		holds, err := tx.GetActiveSlotHolds(rules.TutorID, start.Add(-buffer), end.Add(buffer), studentIDs)
+		if err != nil {
+			return err
+		}
+		if len(nearby) > 0 || len(holds) > 0 {
+			return fmt.Errorf("%w: the tutor keeps %d minutes free between lessons", ErrBookingRule, rules.BufferMinutes)
+		}
+	}
+
+	if rules.MaxLessonsPerDay > 0 {
+		// Days are counted in the lesson's location, the one its availability slots are
+		// built in (see slotTimeRange).
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		count, err := tx.CountTutorLessonsBetween(rules.TutorID, day, day.AddDate(0, 0, 1), excludeLessonID)
+		if err != nil {
+			return err
+		}
+		if count >= int64(rules.MaxLessonsPerDay) {
+			return fmt.Errorf("%w: the tutor takes at most %d lessons a day", ErrBookingRule, rules.MaxLessonsPerDay)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// Length limits and pricing unit of self-booked lessons.
+const (
+	minBookingMinutes = 30
+	maxBookingMinutes = 180
+	bookingMinuteStep = 15
+	pricedMinutes     = 60 // tutors' prices are per hour
+)
+
+// ErrHoldNotActive is returned when confirming or releasing a hold that was already used,
+// released or has expired.
+var ErrHoldNotActive = errors.New("slot hold is no longer active")
+
+// BookingRequest is what a student submits to book a lesson from a tutor's availability.
+// When the tutor publishes offerings the lesson is booked against one of them, which
+// sets its length and price; DurationMinutes is then optional.
+type BookingRequest struct {
+	StartTime       time.Time
+	OfferingID      *uuid.UUID
+	DurationMinutes int
+	Subject         string
+	Level           string
+	Title           string
+	Description     string
+}
+
+// BookingService lets students book lessons directly from a tutor's availability,
+// optionally holding the slot while they go through checkout.
+type BookingService interface {
+	BookLesson(tutorID, studentID uuid.UUID, req BookingRequest) (models.Lesson, error)
+
+	// Checkout holds
+	HoldSlot(tutorID, studentID uuid.UUID, req BookingRequest) (models.SlotHold, error)
+	ConfirmHold(holdID, studentID uuid.UUID) (models.Lesson, error)
+	ReleaseHold(holdID, studentID uuid.UUID) error
+}
+
+type bookingService struct {
+	userRepo      repositories.UserRepository
+	lessonRepo    repositories.LessonRepository
+	offeringRepo  repositories.TutorOfferingRepository
+	lessonService LessonService
+	holdTTL       time.Duration
+}
+
+// NewBookingService creates a new instance of BookingService. Slot holds last holdTTL.
+func NewBookingService(userRepo repositories.UserRepository, lessonRepo repositories.LessonRepository, offeringRepo repositories.TutorOfferingRepository, lessonService LessonService, holdTTL time.Duration) BookingService {
+	return &bookingService{
+		userRepo:      userRepo,
+		lessonRepo:    lessonRepo,
+		offeringRepo:  offeringRepo,
+		lessonService: lessonService,
+		holdTTL:       holdTTL,
+	}
+}
+
+// BookLesson creates a lesson with studentID as its only student. Subject, level and
+// price come from the chosen offering or the tutor; the slot is validated against the tutor's availability and
+// existing lessons by LessonService.ScheduleLesson.
+func (s *bookingService) BookLesson(tutorID, studentID uuid.UUID, req BookingRequest) (models.Lesson, error) {
+	lesson, err := s.draftLesson(tutorID, studentID, req)
+	if err != nil {
+		return models.Lesson{}, err
+	}
+	// Only the tutor's ID is saved with the lesson; the user row itself is left alone.
+	tutor := lesson.Tutor
+	lesson.Tutor = models.User{}
+	created, err := s.lessonService.ScheduleLesson(lesson, studentID)
+	if err != nil {
+		return models.Lesson{}, err
+	}
+	created.Tutor = tutor
+	return created, nil
+}
+
+// HoldSlot reserves the requested slot for the student for a short checkout window,
+// hiding it from other users. The returned hold's ID is used to confirm or release it.
+func (s *bookingService) HoldSlot(tutorID, studentID uuid.UUID, req BookingRequest) (models.SlotHold, error) {
+	lesson, err := s.draftLesson(tutorID, studentID, req)
+	if err != nil {
+		return models.SlotHold{}, err
+	}
+	return s.lessonService.HoldSlot(models.SlotHold{
+		TutorID:     lesson.TutorID,
+		StudentID:   studentID,
+		StartTime:   lesson.StartTime,
+		EndTime:     lesson.EndTime,
+		Title:       lesson.Title,
+		Description: lesson.Description,
+		Subject:     lesson.Subject,
+		Level:       lesson.Level,
+		Price:       lesson.Price,
+		OfferingID:  lesson.OfferingID,
+		MaxStudents: lesson.MaxStudents,
+		IsTrial:     lesson.IsTrial,
+		ExpiresAt:   time.Now().Add(s.holdTTL),
+	})
+}
+
+// ConfirmHold books the lesson described by the student's active hold.
+func (s *bookingService) ConfirmHold(holdID, studentID uuid.UUID) (models.Lesson, error) {
+	hold, err := s.getOwnHold(holdID, studentID)
+	if err != nil {
+		return models.Lesson{}, err
+	}
+	if !hold.IsActive(time.Now()) {
+		return models.Lesson{}, ErrHoldNotActive
+	}
+
+	tutor, err := s.userRepo.GetUserByID(hold.TutorID)
+	if err != nil {
+		return models.Lesson{}, errors.New("tutor not found")
+	}
+	student, err := s.userRepo.GetUserByID(studentID)
+	if err != nil {
+		return models.Lesson{}, errors.New("student not found")
+	}
+
+	// Claim the hold first so it cannot be confirmed twice.
+	hold.Status = models.SlotHoldStatusConfirmed
+	claimed, err := s.lessonRepo.UpdateSlotHoldIfStatus(&hold, models.SlotHoldStatusActive)
+	if err != nil {
+		return models.Lesson{}, err
+	}
+	if !claimed {
+		return models.Lesson{}, ErrHoldNotActive
+	}
+
+	created, err := s.lessonService.ScheduleLesson(models.Lesson{
+		TutorID:     hold.TutorID,
+		Students:    []models.User{student},
+		Title:       hold.Title,
+		Description: hold.Description,
+		Subject:     hold.Subject,
+		Level:       hold.Level,
+		StartTime:   hold.StartTime,
+		EndTime:     hold.EndTime,
+		Price:       hold.Price,
+		OfferingID:  hold.OfferingID,
+		MaxStudents: hold.MaxStudents,
+		IsTrial:     hold.IsTrial,
+	}, studentID)
+	if err != nil {
+		// Give the slot back to the student until the hold expires.
+		hold.Status = models.SlotHoldStatusActive
+		if _, revertErr := s.lessonRepo.UpdateSlotHoldIfStatus(&hold, models.SlotHoldStatusConfirmed); revertErr != nil {
+			return models.Lesson{}, revertErr
+		}
+		return models.Lesson{}, err
+	}
+
+	hold.LessonID = &created.ID
+	if _, err := s.lessonRepo.UpdateSlotHoldIfStatus(&hold, models.SlotHoldStatusConfirmed); err != nil {
+		return models.Lesson{}, err
+	}
+	created.Tutor = tutor
+	return created, nil
+}
+
+// ReleaseHold gives up the student's hold, making the slot visible to others again.
+func (s *bookingService) ReleaseHold(holdID, studentID uuid.UUID) error {
+	hold, err := s.getOwnHold(holdID, studentID)
+	if err != nil {
+		return err
+	}
+	hold.Status = models.SlotHoldStatusReleased
+	released, err := s.lessonRepo.UpdateSlotHoldIfStatus(&hold, models.SlotHoldStatusActive)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrHoldNotActive
+	}
+	return nil
+}
+
+func (s *bookingService) getOwnHold(holdID, studentID uuid.UUID) (models.SlotHold, error) {
+	hold, err := s.lessonRepo.GetSlotHoldByID(holdID)
+	if err != nil || hold.StudentID != studentID {
+		return models.SlotHold{}, errors.New("hold not found")
+	}
+	return hold, nil
+}
+
+// draftLesson builds, without saving, the lesson a student books with a tutor. Length,
+// subject, level and price come from the chosen offering, or from the tutor when the
+// tutor has no offerings.
+func (s *bookingService) draftLesson(tutorID, studentID uuid.UUID, req BookingRequest) (models.Lesson, error) {
+	if tutorID == studentID {
+		return models.Lesson{}, errors.New("you cannot book a lesson with yourself")
+	}
+
+	tutor, err := s.userRepo.GetUserByID(tutorID)
+	if err != nil || tutor.Role != models.UserRoleTutor {
+		return models.Lesson{}, errors.New("tutor not found")
+	}
+	if tutor.IsSuspended() {
+		return models.Lesson{}, errors.New("this tutor is not accepting bookings")
+	}
+	student, err := s.userRepo.GetUserByID(studentID)
+	if err != nil {
+		return models.Lesson{}, errors.New("student not found")
+	}
+
+	offering, err := s.pickOffering(tutorID, req)
+	if err != nil {
+		return models.Lesson{}, err
+	}
+	if offering != nil && offering.IsTrial {
+		eligible, err := s.lessonService.IsTrialEligible(tutorID, studentID)
+		if err != nil {
+			return models.Lesson{}, err
+		}
+		if !eligible {
+			return models.Lesson{}, ErrTrialNotEligible
+		}
+	}
+	minutes := req.DurationMinutes
+	subjects, levels := tutor.Subjects, tutor.Levels
+	var price float64
+	var maxStudents int
+	if offering != nil {
+		minutes = offering.DurationMinutes
+		if len(offering.Subjects) > 0 {
+			subjects = offering.Subjects
+		}
+		if len(offering.Levels) > 0 {
+			levels = offering.Levels
+		}
+		price = offering.Price
+		maxStudents = offering.MaxStudents
+	} else {
+		if err := validateBookingMinutes(minutes); err != nil {
+			return models.Lesson{}, err
+		}
+		price = bookingPrice(tutor.Price, minutes)
+	}
+
+	subject, err := pickTutorValue(subjects, req.Subject, "subject")
+	if err != nil {
+		return models.Lesson{}, err
+	}
+	level, err := pickTutorValue(levels, req.Level, "level")
+	if err != nil {
+		return models.Lesson{}, err
+	}
+
+	title := req.Title
+	if title == "" && offering != nil {
+		title = offering.Title
+	}
+	if title == "" {
+		title = strings.TrimSpace(subject + " lesson")
+	}
+
+	lesson := models.Lesson{
+		TutorID:     tutor.ID,
+		Tutor:       tutor,
+		Students:    []models.User{student},
+		Title:       title,
+		Description: req.Description,
+		Subject:     subject,
+		Level:       level,
+		StartTime:   req.StartTime,
+		EndTime:     req.StartTime.Add(time.Duration(minutes) * time.Minute),
+		Price:       price,
+		MaxStudents: maxStudents,
+	}
+	if offering != nil {
+		lesson.OfferingID = &offering.ID
+		lesson.IsTrial = offering.IsTrial
+	}
+	return lesson, nil
+}
+
+// pickOffering returns the active offering of the tutor the student booked against, or
+// nil for a tutor without offerings. Tutors who publish offerings are only booked
+// through them.
+func (s *bookingService) pickOffering(tutorID uuid.UUID, req BookingRequest) (*models.TutorOffering, error) {
+	if req.OfferingID == nil {
+		offerings, err := s.offeringRepo.GetOfferingsByTutorID(tutorID, true)
+		if err != nil {
+			return nil, err
+		}
+		if len(offerings) > 0 {
+			return nil, errors.New("offering_id is required, choose one of the tutor's offerings")
+		}
+		return nil, nil
+	}
+
+	offering, err := s.offeringRepo.GetOfferingByID(*req.OfferingID)
+	if err != nil || offering.TutorID != tutorID || !offering.Active {
+		return nil, errors.New("offering not found")
+	}
+	if req.DurationMinutes != 0 && req.DurationMinutes != offering.DurationMinutes {
+		return nil, fmt.Errorf("%w: this offering is %d minutes long", ErrInvalidLessonTime, offering.DurationMinutes)
+	}
+	return &offering, nil
+}
+
+// pickTutorValue resolves a subject or level against the tutor's list: a requested value
+// must be one the tutor offers, and an empty one defaults to the tutor's only value.
+func pickTutorValue(offered []string, requested, field string) (string, error) {
+	if requested == "" {
+		if len(offered) == 1 {
+			return offered[0], nil
+		}
+		if len(offered) == 0 {
+			return "", nil
+		}
+		return "", fmt.Errorf("%s is required, this tutor offers: %s", field, strings.Join(offered, ", "))
+	}
+	for _, value := range offered {
+		if strings.EqualFold(value, requested) {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("this tutor does not offer %s %q", field, requested)
+}
+
+// bookingPrice prices a lesson of the given length at the tutor's hourly rate, in cents precision.
+func bookingPrice(hourlyPrice float64, minutes int) float64 {
+	return math.Round(hourlyPrice*float64(minutes)/pricedMinutes*100) / 100
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// CancellationPolicyService defines business logic for tutors' cancellation policies.
+type CancellationPolicyService interface {
+	GetPolicy(tutorID uuid.UUID) (models.TutorCancellationPolicy, error)
+	UpdatePolicy(tutorID uuid.UUID, freeCancellationHours int, lateFeePercent float64) (models.TutorCancellationPolicy, error)
+}
+
+type cancellationPolicyService struct {
+	repo     repositories.CancellationPolicyRepository
+	userRepo repositories.UserRepository
+}
+
+// NewCancellationPolicyService creates a new instance of CancellationPolicyService.
+func NewCancellationPolicyService(repo repositories.CancellationPolicyRepository, userRepo repositories.UserRepository) CancellationPolicyService {
+	return &cancellationPolicyService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// GetPolicy returns the tutor's policy, or the default one if the tutor has not set any.
+func (s *cancellationPolicyService) GetPolicy(tutorID uuid.UUID) (models.TutorCancellationPolicy, error) {
+	if err := s.ensureTutor(tutorID); err != nil {
+		return models.TutorCancellationPolicy{}, err
+	}
+	return getCancellationPolicy(s.repo, tutorID)
+}
+
+// UpdatePolicy sets the tutor's policy.
+func (s *cancellationPolicyService) UpdatePolicy(tutorID uuid.UUID, freeCancellationHours int, lateFeePercent float64) (models.TutorCancellationPolicy, error) {
+	if err := s.ensureTutor(tutorID); err != nil {
+		return models.TutorCancellationPolicy{}, err
+	}
+	if freeCancellationHours < 0 {
+		return models.TutorCancellationPolicy{}, errors.New("free_cancellation_hours cannot be negative")
+	}
+	if lateFeePercent < 0 || lateFeePercent > 100 {
+		return models.TutorCancellationPolicy{}, errors.New("late_cancellation_fee_percent must be between 0 and 100")
+	}
+
+	policy := models.TutorCancellationPolicy{
+		TutorID:                    tutorID,
+		FreeCancellationHours:      freeCancellationHours,
+		LateCancellationFeePercent: lateFeePercent,
+	}
+	if err := s.repo.UpsertPolicy(&policy); err != nil {
+		return models.TutorCancellationPolicy{}, err
+	}
+	return s.repo.GetPolicyByTutorID(tutorID)
+}
+
+func (s *cancellationPolicyService) ensureTutor(tutorID uuid.UUID) error {
+	tutor, err := s.userRepo.GetUserByID(tutorID)
+	if err != nil {
+		return errors.New("tutor not found")
+	}
+	if tutor.Role != models.UserRoleTutor {
+		return errors.New("user is not a tutor")
+	}
+	return nil
+}
+
+// getCancellationPolicy returns the tutor's policy, falling back to the default one.
+func getCancellationPolicy(repo repositories.CancellationPolicyRepository, tutorID uuid.UUID) (models.TutorCancellationPolicy, error) {
+	policy, err := repo.GetPolicyByTutorID(tutorID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultCancellationPolicy(tutorID), nil
+	}
+	return policy, err
+}
+
+var validCancellationReasons = map[string]bool{
+	models.CancellationReasonScheduleConflict: true,
+	models.CancellationReasonIllness:          true,
+	models.CancellationReasonEmergency:        true,
+	models.CancellationReasonNoLongerNeeded:   true,
+	models.CancellationReasonTechnicalIssues:  true,
+	models.CancellationReasonOther:            true,
+}
+
+// validateCancellation checks the reason given for cancelling a lesson or a seat in one.
+func validateCancellation(reason, note string) error {
+	if !validCancellationReasons[reason] {
+		return errors.New("invalid cancellation reason")
+	}
+	if reason == models.CancellationReasonOther && strings.TrimSpace(note) == "" {
+		return errors.New("a note is required when reason is 'other'")
+	}
+	return nil
+}
+
+// lateCancellationFee returns what a student owes under the tutor's policy for cancelling
+// the lesson, or their seat in it, at cancelledAt. The lesson must have its Tutor loaded.
+func lateCancellationFee(repo repositories.CancellationPolicyRepository, lesson models.Lesson, cancelledAt time.Time) (float64, error) {
+	policy, err := getCancellationPolicy(repo, lesson.TutorID)
+	if err != nil {
+		return 0, err
+	}
+	price := lesson.Price
+	if price == 0 && !lesson.IsTrial {
+		price = lesson.Tutor.Price
+	}
+	return policy.LateCancellationFee(price, lesson.StartTime, cancelledAt), nil
+}
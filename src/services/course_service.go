@@ -2,8 +2,10 @@ package services
 
 import (
 	"errors"
+	"log"
 
 	"github.com/google/uuid"
+	"vibely-backend/src/events"
 	"vibely-backend/src/models"
 	"vibely-backend/src/repositories"
 )
@@ -16,17 +18,17 @@ type CourseService interface {
 	UpdateCourse(course models.Course) (models.Course, error)
 	GetCourses(subject, level string, page, limit int) ([]models.Course, error)
 	GetCoursesForUser(userID uuid.UUID) ([]models.Course, error)
-	EnrollStudent(courseID uuid.UUID, student models.User) (models.Course, error)
+	GetCourseProgress(courseID uuid.UUID) (models.CourseProgress, error)
 }
 
 type courseService struct {
-	courseRepo    repositories.CourseRepository
-	lessonService LessonService
+	courseRepo repositories.CourseRepository
+	publisher  events.Publisher
 }
 
 // NewCourseService creates a new instance of CourseService.
-func NewCourseService(courseRepo repositories.CourseRepository, lessonService LessonService) CourseService {
-	return &courseService{courseRepo: courseRepo, lessonService: lessonService}
+func NewCourseService(courseRepo repositories.CourseRepository, publisher events.Publisher) CourseService {
+	return &courseService{courseRepo: courseRepo, publisher: publisher}
 }
 
 // CreateCourse validates and creates a new course.
@@ -37,12 +39,16 @@ func (s *courseService) CreateCourse(course models.Course) (models.Course, error
 	if course.Name == "" {
 		return models.Course{}, errors.New("course name is required")
 	}
+	if err := validateCapacity(course.MaxStudents, len(course.Students)); err != nil {
+		return models.Course{}, err
+	}
 
 	// Additional validations (e.g., check for students) can be added here.
 
 	if err := s.courseRepo.CreateCourse(&course); err != nil {
 		return models.Course{}, err
 	}
+	s.publishCourseChange(course)
 	return course, nil
 }
 
@@ -61,42 +67,78 @@ func (s *courseService) UpdateCourse(course models.Course) (models.Course, error
 	if err := s.courseRepo.UpdateCourse(&course); err != nil {
 		return models.Course{}, err
 	}
+	s.publishCourseChange(course)
 	return course, nil
 }
+
+// publishCourseChange notifies the course's tutor and students that it changed.
+func (s *courseService) publishCourseChange(course models.Course) {
+	if len(course.Students) == 0 {
+		withParticipants, err := s.courseRepo.GetCourseWithParticipants(course.ID)
+		if err != nil {
+			log.Printf("Failed to load participants of course %s: %v", course.ID, err)
+			return
+		}
+		course.Students = withParticipants.Students
+	}
+	userIDs := []uuid.UUID{course.TutorID}
+	for _, student := range course.Students {
+		userIDs = append(userIDs, student.ID)
+	}
+	change := events.CourseChange{CourseID: course.ID, TutorID: course.TutorID}
+	s.publisher.Publish(events.CourseUpdated, change, events.UserTopics(userIDs...)...)
+}
 func (s *courseService) GetCourses(subject, level string, page, limit int) ([]models.Course, error) {
 	return s.courseRepo.GetCourses(subject, level, page, limit)
 }
 func (s *courseService) GetCoursesForUser(userID uuid.UUID) ([]models.Course, error) {
 	return s.courseRepo.GetCoursesForUser(userID)
 }
-func (s *courseService) EnrollStudent(courseID uuid.UUID, student models.User) (models.Course, error) {
-	// Retrieve the course with its participants and lessons.
+
+// GetCourseProgress reports how many of the course's lessons are completed and, for each
+// enrolled student, their attendance in the completed ones.
+func (s *courseService) GetCourseProgress(courseID uuid.UUID) (models.CourseProgress, error) {
 	course, err := s.courseRepo.GetCourseWithParticipants(courseID)
 	if err != nil {
-		return models.Course{}, err
+		return models.CourseProgress{}, err
+	}
+	total, completed, err := s.courseRepo.CountCourseLessons(courseID)
+	if err != nil {
+		return models.CourseProgress{}, err
+	}
+	counts, err := s.courseRepo.GetCourseAttendanceCounts(courseID)
+	if err != nil {
+		return models.CourseProgress{}, err
 	}
 
-	// Check if student is already enrolled in the course.
-	for _, enrolled := range course.Students {
-		if enrolled.ID == student.ID {
-			return models.Course{}, errors.New("student already enrolled in this course")
+	summaries := make(map[uuid.UUID]*models.AttendanceSummary)
+	for _, count := range counts {
+		if summaries[count.UserID] == nil {
+			summaries[count.UserID] = &models.AttendanceSummary{}
 		}
+		summaries[count.UserID].Add(count.Attendance, count.Lessons)
 	}
 
-	// Enroll student in course.
-	if err := s.courseRepo.EnrollStudent(courseID, student); err != nil {
-		return models.Course{}, err
+	progress := models.CourseProgress{
+		CourseID:         courseID,
+		TotalLessons:     int(total),
+		CompletedLessons: int(completed),
+		Students:         []models.StudentCourseProgress{},
 	}
-
-	// Enroll student in every lesson of the course.
-	// Here we assume s.courseRepo is an instance of courseRepository injected in the course service.
-	for _, lesson := range course.Lessons {
-		if err := s.lessonService.EnrollStudent(lesson.ID, student); err != nil {
-			// Optionally, you might want to roll back the course enrollment if lesson enrollment fails.
-			return models.Course{}, err
+	for _, student := range course.Students {
+		var summary models.AttendanceSummary
+		if counted := summaries[student.ID]; counted != nil {
+			summary = *counted
 		}
+		studentProgress := models.StudentCourseProgress{
+			Student:        student.ToStudentDTO(),
+			Attendance:     summary,
+			AttendanceRate: summary.Rate(),
+		}
+		if total > 0 {
+			studentProgress.Progress = float64(summary.Attended()) / float64(total)
+		}
+		progress.Students = append(progress.Students, studentProgress)
 	}
-
-	// Return the updated course with its associated tutor, students, and lessons.
-	return s.courseRepo.GetCourseWithParticipants(courseID)
+	return progress, nil
 }
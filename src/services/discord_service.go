@@ -0,0 +1,221 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vibely-backend/src/discord"
+	"vibely-backend/src/events"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// Kinds of the queued jobs talking to Discord.
+const (
+	DiscordDMJob                   = "discord-dm"
+	DiscordCreateLessonChannelsJob = "discord-create-lesson-channels"
+	DiscordDeleteLessonChannelsJob = "discord-delete-lesson-channels"
+)
+
+// Limits of the Discord API, in characters.
+const (
+	discordMaxMessageLength     = 2000
+	discordMaxChannelNameLength = 100
+)
+
+// DiscordDM is the payload of a DiscordDMJob.
+type DiscordDM struct {
+	UserID uuid.UUID `json:"user_id"`
+	Title  string    `json:"title"`
+	Body   string    `json:"body"`
+}
+
+// discordLessonJob is the payload of the lesson channel jobs.
+type discordLessonJob struct {
+	LessonID uuid.UUID `json:"lesson_id"`
+}
+
+// DiscordService connects the platform to our Discord server through the bot: it sends
+// notifications as direct messages and gives each confirmed lesson private text and voice
+// channels, deleted some time after the lesson ends. All calls to Discord run as queued
+// jobs, so they are retried when Discord is unavailable.
+type DiscordService interface {
+	// Publish creates and deletes lesson channels as lessons are confirmed, moved and
+	// come to an end.
+	events.Publisher
+
+	SendDM(payload []byte) error
+	CreateLessonChannels(payload []byte) error
+	DeleteLessonChannels(payload []byte) error
+}
+
+type discordService struct {
+	client       discord.Client
+	repo         repositories.DiscordRepository
+	lessonRepo   repositories.LessonRepository
+	userRepo     repositories.UserRepository
+	jobRepo      repositories.JobRepository
+	cleanupDelay time.Duration
+}
+
+// NewDiscordService creates a new instance of DiscordService. Lesson channels are deleted
+// cleanupDelay after their lesson ends.
+func NewDiscordService(client discord.Client, repo repositories.DiscordRepository, lessonRepo repositories.LessonRepository, userRepo repositories.UserRepository, jobRepo repositories.JobRepository, cleanupDelay time.Duration) DiscordService {
+	return &discordService{
+		client:       client,
+		repo:         repo,
+		lessonRepo:   lessonRepo,
+		userRepo:     userRepo,
+		jobRepo:      jobRepo,
+		cleanupDelay: cleanupDelay,
+	}
+}
+
+func (s *discordService) Publish(eventType string, data interface{}, topics ...string) {
+	change, ok := data.(events.LessonChange)
+	if !ok || eventType != events.LessonUpdated {
+		return
+	}
+	var err error
+	switch {
+	case change.Action == models.LessonStatusConfirmed:
+		if err = s.queueLessonJob(DiscordCreateLessonChannelsJob, change.LessonID, time.Now()); err == nil {
+			err = s.queueLessonJob(DiscordDeleteLessonChannelsJob, change.LessonID, change.EndTime.Add(s.cleanupDelay))
+		}
+	case change.Action == events.LessonActionPostponed:
+		// Channels made for the old time stay until the lesson is over.
+		err = s.queueLessonJob(DiscordDeleteLessonChannelsJob, change.LessonID, change.EndTime.Add(s.cleanupDelay))
+	case slotReleased(change.Action):
+		err = s.queueLessonJob(DiscordDeleteLessonChannelsJob, change.LessonID, time.Now())
+	}
+	if err != nil {
+		log.Printf("Failed to queue Discord channel update of lesson %s: %v", change.LessonID, err)
+	}
+}
+
+// queueLessonJob queues a lesson channel job, replacing the lesson's pending job of the same kind.
+func (s *discordService) queueLessonJob(kind string, lessonID uuid.UUID, runAt time.Time) error {
+	payload, err := json.Marshal(discordLessonJob{LessonID: lessonID})
+	if err != nil {
+		return err
+	}
+	return s.jobRepo.Enqueue(&models.QueuedJob{
+		Kind:    kind,
+		Key:     kind + ":" + lessonID.String(),
+		Payload: string(payload),
+		RunAt:   runAt,
+	})
+}
+
+// SendDM sends a notification to the user's Discord account.
+func (s *discordService) SendDM(payload []byte) error {
+	var dm DiscordDM
+	if err := json.Unmarshal(payload, &dm); err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetUserByID(dm.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	content := []rune(fmt.Sprintf("**%s**\n%s", dm.Title, dm.Body))
+	if len(content) > discordMaxMessageLength {
+		content = append(content[:discordMaxMessageLength-3], []rune("...")...)
+	}
+	return s.client.SendDM(user.DiscordID, string(content))
+}
+
+// CreateLessonChannels creates the private channels of a confirmed lesson, open to its
+// tutor and students. Lessons that already have channels keep them.
+func (s *discordService) CreateLessonChannels(payload []byte) error {
+	var job discordLessonJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetLessonChannels(job.LessonID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	lesson, err := s.lessonRepo.GetLessonWithParticipants(job.LessonID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if lesson.Status != models.LessonStatusConfirmed && lesson.Status != models.LessonStatusInProgress {
+		return nil
+	}
+
+	memberIDs := []string{lesson.Tutor.DiscordID}
+	for _, student := range lesson.Students {
+		memberIDs = append(memberIDs, student.DiscordID)
+	}
+	textID, voiceID, err := s.client.CreatePrivateChannels(lessonChannelName(lesson), memberIDs)
+	if err != nil {
+		return err
+	}
+	channels := models.LessonDiscordChannels{LessonID: lesson.ID, TextChannelID: textID, VoiceChannelID: voiceID}
+	if err := s.repo.CreateLessonChannels(&channels); err != nil {
+		// Without a record, nothing would ever delete them.
+		_ = s.deleteChannels(channels)
+		return err
+	}
+	return nil
+}
+
+// DeleteLessonChannels deletes the channels of a lesson, if it has any.
+func (s *discordService) DeleteLessonChannels(payload []byte) error {
+	var job discordLessonJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+	channels, err := s.repo.GetLessonChannels(job.LessonID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.deleteChannels(channels); err != nil {
+		return err
+	}
+	return s.repo.DeleteLessonChannels(job.LessonID)
+}
+
+func (s *discordService) deleteChannels(channels models.LessonDiscordChannels) error {
+	if err := s.client.DeleteChannel(channels.TextChannelID); err != nil {
+		return err
+	}
+	return s.client.DeleteChannel(channels.VoiceChannelID)
+}
+
+// lessonChannelName names a lesson's channels after its start and title, in the form
+// Discord accepts for text channels, e.g. "lesson-2024-05-02-1400-algebra".
+func lessonChannelName(lesson models.Lesson) string {
+	name := "lesson-" + lesson.StartTime.UTC().Format("2006-01-02-1504")
+	var slug strings.Builder
+	for _, r := range strings.ToLower(lesson.Title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			slug.WriteRune(r)
+		case slug.Len() > 0 && !strings.HasSuffix(slug.String(), "-"):
+			slug.WriteRune('-')
+		}
+	}
+	if title := strings.Trim(slug.String(), "-"); title != "" {
+		name += "-" + title
+	}
+	if len(name) > discordMaxChannelNameLength {
+		name = strings.TrimRight(name[:discordMaxChannelNameLength], "-")
+	}
+	return name
+}
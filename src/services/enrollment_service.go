@@ -0,0 +1,451 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"vibely-backend/src/events"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// Enrollment errors
+var (
+	// ErrAlreadyEnrolled is returned when a student joins a lesson or course they already attend.
+	ErrAlreadyEnrolled = errors.New("student is already enrolled")
+	// ErrAlreadyWaitlisted is returned when a student joins a waitlist they are already on.
+	ErrAlreadyWaitlisted = errors.New("student is already on the waitlist")
+	// ErrNotEnrolled is returned when a student leaves a lesson or course they are neither
+	// enrolled in nor waiting for.
+	ErrNotEnrolled = errors.New("student is neither enrolled nor on the waitlist")
+	// ErrEnrollmentClosed is returned when joining or leaving a lesson that has already
+	// started or is no longer going to take place.
+	ErrEnrollmentClosed = errors.New("this lesson is no longer open for enrollment")
+)
+
+// EnrollmentResult tells whether a student got a seat or was put on the waitlist.
+type EnrollmentResult struct {
+	Enrolled bool
+	// Entry and Position (starting at 1) are set when the student was waitlisted.
+	Entry    models.WaitlistEntry
+	Position int
+}
+
+// EnrollmentService manages seats in group lessons and courses. Students joining a full
+// group are put on a FIFO waitlist and promoted, with a notification, when a seat frees up.
+type EnrollmentService interface {
+	JoinLesson(lessonID uuid.UUID, student models.User) (EnrollmentResult, error)
+	LeaveLesson(lessonID, studentID uuid.UUID) error
+	CancelSeat(lessonID, studentID uuid.UUID, reason, note string) (models.SeatCancellation, error)
+	GetLessonWaitlist(lessonID uuid.UUID) ([]models.WaitlistEntry, error)
+
+	JoinCourse(courseID uuid.UUID, student models.User) (EnrollmentResult, error)
+	LeaveCourse(courseID, studentID uuid.UUID) error
+	GetCourseWaitlist(courseID uuid.UUID) ([]models.WaitlistEntry, error)
+}
+
+type enrollmentService struct {
+	repo           repositories.EnrollmentRepository
+	moderationRepo repositories.ModerationRepository
+	policyRepo     repositories.CancellationPolicyRepository
+	notifier       Notifier
+	publisher      events.Publisher
+}
+
+// NewEnrollmentService creates a new instance of EnrollmentService.
+func NewEnrollmentService(repo repositories.EnrollmentRepository, moderationRepo repositories.ModerationRepository, policyRepo repositories.CancellationPolicyRepository, notifier Notifier, publisher events.Publisher) EnrollmentService {
+	return &enrollmentService{
+		repo:           repo,
+		moderationRepo: moderationRepo,
+		policyRepo:     policyRepo,
+		notifier:       notifier,
+		publisher:      publisher,
+	}
+}
+
+// JoinLesson gives the student a seat in a group lesson, or a place on its waitlist when
+// the lesson is full. Lessons of a course are joined by enrolling in the course.
+func (s *enrollmentService) JoinLesson(lessonID uuid.UUID, student models.User) (EnrollmentResult, error) {
+	var result EnrollmentResult
+	change := events.EnrollmentChange{LessonID: &lessonID, StudentID: student.ID}
+	err := s.repo.Transaction(func(tx repositories.EnrollmentRepository) error {
+		// The student is locked before the lesson, as bookings lock participants first.
+		if err := tx.LockParticipants([]uuid.UUID{student.ID}); err != nil {
+			return err
+		}
+		lesson, err := tx.LockLesson(lessonID)
+		if err != nil {
+			return errors.New("lesson not found")
+		}
+		change.TutorID, change.Title = lesson.TutorID, lesson.Title
+		if lesson.CourseID != nil {
+			return errors.New("this lesson is part of a course, enroll in the course instead")
+		}
+		if err := checkLessonOpen(lesson); err != nil {
+			return err
+		}
+		if err := s.checkCanJoin(lesson.TutorID, student.ID, lesson.Students); err != nil {
+			return err
+		}
+
+		waitlist, err := tx.GetLessonWaitlist(lessonID)
+		if err != nil {
+			return err
+		}
+		// Waiting students are served first, even if a seat is free at the moment.
+		if len(waitlist) == 0 && models.HasFreeSeat(lesson.MaxStudents, len(lesson.Students)) {
+			if err := checkStudentFree(tx, student.ID, []models.Lesson{lesson}); err != nil {
+				return err
+			}
+			result.Enrolled = true
+			return tx.AddLessonStudent(lessonID, student.ID)
+		}
+		result, err = joinWaitlist(tx, waitlist, models.WaitlistEntry{LessonID: &lessonID, StudentID: student.ID})
+		return err
+	})
+	if err != nil {
+		return EnrollmentResult{}, err
+	}
+	s.publishEnrollment(change, result)
+	result.Entry.Student = student
+	return result, nil
+}
+
+// LeaveLesson frees the student's seat in a group lesson, promoting the next student on
+// the waitlist, or removes the student from the waitlist. Leaving late is charged like
+// cancelling the seat.
+func (s *enrollmentService) LeaveLesson(lessonID, studentID uuid.UUID) error {
+	_, err := s.releaseSeat(lessonID, studentID, models.CancellationReasonNoLongerNeeded, "", false)
+	return err
+}
+
+// CancelSeat frees the student's seat in a lesson that goes on for its other students,
+// including a lesson of a course the student stays enrolled in, and charges the late
+// cancellation fee of the tutor's policy.
+func (s *enrollmentService) CancelSeat(lessonID, studentID uuid.UUID, reason, note string) (models.SeatCancellation, error) {
+	if err := validateCancellation(reason, note); err != nil {
+		return models.SeatCancellation{}, err
+	}
+	return s.releaseSeat(lessonID, studentID, reason, note, true)
+}
+
+// releaseSeat removes the student from the lesson or its waitlist and records the seat
+// cancellation, promoting the next waiting student into the freed seat. Lessons of a
+// course are only released when inCourse is set.
+func (s *enrollmentService) releaseSeat(lessonID, studentID uuid.UUID, reason, note string, inCourse bool) (models.SeatCancellation, error) {
+	var promoted []models.WaitlistEntry
+	var title string
+	var cancellation models.SeatCancellation
+	err := s.repo.Transaction(func(tx repositories.EnrollmentRepository) error {
+		lesson, err := tx.LockLesson(lessonID)
+		if err != nil {
+			return errors.New("lesson not found")
+		}
+		if lesson.CourseID != nil && !inCourse {
+			return errors.New("this lesson is part of a course, leave the course instead")
+		}
+		if err := checkLessonOpen(lesson); err != nil {
+			return err
+		}
+		title = lesson.Title
+
+		waitlist, err := tx.GetLessonWaitlist(lessonID)
+		if err != nil {
+			return err
+		}
+		if left, err := leaveWaitlist(tx, waitlist, studentID); left || err != nil {
+			return err
+		}
+
+		if !isEnrolled(lesson.Students, studentID) {
+			return ErrNotEnrolled
+		}
+		if len(lesson.Students) == 1 && len(waitlist) == 0 {
+			return errors.New("you are the only student of this lesson, cancel the lesson instead")
+		}
+		if _, err := tx.RemoveLessonStudent(lessonID, studentID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		fee, err := lateCancellationFee(s.policyRepo, lesson, now)
+		if err != nil {
+			return err
+		}
+		cancellation = models.SeatCancellation{
+			LessonID:        lessonID,
+			StudentID:       studentID,
+			Reason:          reason,
+			Note:            note,
+			CancellationFee: fee,
+		}
+		if err := tx.CreateSeatCancellation(&cancellation); err != nil {
+			return err
+		}
+
+		promoted, err = s.promote(waitlist, lesson.TutorID, lesson.MaxStudents, len(lesson.Students)-1,
+			func(entry models.WaitlistEntry) error {
+				if err := tx.LockParticipants([]uuid.UUID{entry.StudentID}); err != nil {
+					return err
+				}
+				if err := checkStudentFree(tx, entry.StudentID, []models.Lesson{lesson}); err != nil {
+					return err
+				}
+				return tx.AddLessonStudent(lessonID, entry.StudentID)
+			}, tx.DeleteWaitlistEntry)
+		return err
+	})
+	if err != nil {
+		return models.SeatCancellation{}, err
+	}
+	s.notifyPromoted(promoted, fmt.Sprintf("A seat in the lesson %q freed up and you have been enrolled.", title))
+	return cancellation, nil
+}
+
+// GetLessonWaitlist returns the lesson's waitlist in order.
+func (s *enrollmentService) GetLessonWaitlist(lessonID uuid.UUID) ([]models.WaitlistEntry, error) {
+	return s.repo.GetLessonWaitlist(lessonID)
+}
+
+// JoinCourse enrolls the student in the course and its upcoming lessons, or puts them on
+// its waitlist when the course is full.
+func (s *enrollmentService) JoinCourse(courseID uuid.UUID, student models.User) (EnrollmentResult, error) {
+	var result EnrollmentResult
+	change := events.EnrollmentChange{CourseID: &courseID, StudentID: student.ID}
+	err := s.repo.Transaction(func(tx repositories.EnrollmentRepository) error {
+		// The student is locked before the course, as bookings lock participants first.
+		if err := tx.LockParticipants([]uuid.UUID{student.ID}); err != nil {
+			return err
+		}
+		course, err := tx.LockCourse(courseID)
+		if err != nil {
+			return errors.New("course not found")
+		}
+		change.TutorID, change.Title = course.TutorID, course.Name
+		if err := s.checkCanJoin(course.TutorID, student.ID, course.Students); err != nil {
+			return err
+		}
+
+		waitlist, err := tx.GetCourseWaitlist(courseID)
+		if err != nil {
+			return err
+		}
+		if len(waitlist) == 0 && models.HasFreeSeat(course.MaxStudents, len(course.Students)) {
+			now := time.Now()
+			lessons, err := tx.GetUpcomingCourseLessons(courseID, now)
+			if err != nil {
+				return err
+			}
+			if err := checkStudentFree(tx, student.ID, lessons); err != nil {
+				return err
+			}
+			result.Enrolled = true
+			return tx.AddCourseStudent(courseID, student.ID, now)
+		}
+		result, err = joinWaitlist(tx, waitlist, models.WaitlistEntry{CourseID: &courseID, StudentID: student.ID})
+		return err
+	})
+	if err != nil {
+		return EnrollmentResult{}, err
+	}
+	s.publishEnrollment(change, result)
+	result.Entry.Student = student
+	return result, nil
+}
+
+// LeaveCourse unenrolls the student from the course and its upcoming lessons, promoting
+// the next student on the waitlist, or removes the student from the waitlist.
+func (s *enrollmentService) LeaveCourse(courseID, studentID uuid.UUID) error {
+	var promoted []models.WaitlistEntry
+	var name string
+	err := s.repo.Transaction(func(tx repositories.EnrollmentRepository) error {
+		course, err := tx.LockCourse(courseID)
+		if err != nil {
+			return errors.New("course not found")
+		}
+		name = course.Name
+
+		waitlist, err := tx.GetCourseWaitlist(courseID)
+		if err != nil {
+			return err
+		}
+		if left, err := leaveWaitlist(tx, waitlist, studentID); left || err != nil {
+			return err
+		}
+
+		now := time.Now()
+		removed, err := tx.RemoveCourseStudent(courseID, studentID, now)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return ErrNotEnrolled
+		}
+
+		lessons, err := tx.GetUpcomingCourseLessons(courseID, now)
+		if err != nil {
+			return err
+		}
+		promoted, err = s.promote(waitlist, course.TutorID, course.MaxStudents, len(course.Students)-1,
+			func(entry models.WaitlistEntry) error {
+				if err := tx.LockParticipants([]uuid.UUID{entry.StudentID}); err != nil {
+					return err
+				}
+				if err := checkStudentFree(tx, entry.StudentID, lessons); err != nil {
+					return err
+				}
+				return tx.AddCourseStudent(courseID, entry.StudentID, now)
+			}, tx.DeleteWaitlistEntry)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.notifyPromoted(promoted, fmt.Sprintf("A seat in the course %q freed up and you have been enrolled.", name))
+	return nil
+}
+
+// GetCourseWaitlist returns the course's waitlist in order.
+func (s *enrollmentService) GetCourseWaitlist(courseID uuid.UUID) ([]models.WaitlistEntry, error) {
+	return s.repo.GetCourseWaitlist(courseID)
+}
+
+// checkCanJoin validates that the student may join a group of the tutor.
+func (s *enrollmentService) checkCanJoin(tutorID, studentID uuid.UUID, enrolled []models.User) error {
+	if tutorID == studentID {
+		return errors.New("you cannot enroll in your own lesson or course")
+	}
+	if isEnrolled(enrolled, studentID) {
+		return ErrAlreadyEnrolled
+	}
+	blocked, err := s.moderationRepo.IsBlocked(tutorID, studentID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrUsersBlocked
+	}
+	return nil
+}
+
+// promote fills the seats free after a student left with the students at the head of
+// the waitlist, dropping students who were blocked by the tutor since they joined it.
+// Students enroll refuses with ErrLessonConflict are skipped but stay on the waitlist.
+// It returns the promoted entries.
+func (s *enrollmentService) promote(waitlist []models.WaitlistEntry, tutorID uuid.UUID, maxStudents, enrolled int,
+	enroll func(entry models.WaitlistEntry) error, remove func(entryID uuid.UUID) error) ([]models.WaitlistEntry, error) {
+	var promoted []models.WaitlistEntry
+	for _, entry := range waitlist {
+		if !models.HasFreeSeat(maxStudents, enrolled) {
+			break
+		}
+		blocked, err := s.moderationRepo.IsBlocked(tutorID, entry.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			if err := remove(entry.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := enroll(entry); errors.Is(err, ErrLessonConflict) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if err := remove(entry.ID); err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, entry)
+		enrolled++
+	}
+	return promoted, nil
+}
+
+// publishEnrollment tells the tutor and the student that the student joined a group or its waitlist.
+func (s *enrollmentService) publishEnrollment(change events.EnrollmentChange, result EnrollmentResult) {
+	change.Waitlisted = !result.Enrolled
+	s.publisher.Publish(events.EnrollmentCreated, change, events.UserTopics(change.TutorID, change.StudentID)...)
+}
+
+func (s *enrollmentService) notifyPromoted(promoted []models.WaitlistEntry, message string) {
+	for _, entry := range promoted {
+		if err := s.notifier.Notify(entry.StudentID, "You got a seat", message); err != nil {
+			log.Printf("failed to notify %s about their waitlist promotion: %v", entry.StudentID, err)
+		}
+	}
+}
+
+// joinWaitlist appends the entry to the waitlist unless the student is already on it.
+func joinWaitlist(tx repositories.EnrollmentRepository, waitlist []models.WaitlistEntry, entry models.WaitlistEntry) (EnrollmentResult, error) {
+	for _, waiting := range waitlist {
+		if waiting.StudentID == entry.StudentID {
+			return EnrollmentResult{}, ErrAlreadyWaitlisted
+		}
+	}
+	if err := tx.CreateWaitlistEntry(&entry); err != nil {
+		return EnrollmentResult{}, err
+	}
+	return EnrollmentResult{Entry: entry, Position: len(waitlist) + 1}, nil
+}
+
+// leaveWaitlist removes the student from the waitlist, reporting whether they were on it.
+func leaveWaitlist(tx repositories.EnrollmentRepository, waitlist []models.WaitlistEntry, studentID uuid.UUID) (bool, error) {
+	for _, entry := range waitlist {
+		if entry.StudentID == studentID {
+			return true, tx.DeleteWaitlistEntry(entry.ID)
+		}
+	}
+	return false, nil
+}
+
+// checkStudentFree makes sure the student has no other lesson overlapping the given
+// lessons, like reserveLessonSlot does for bookings. The student must be locked with
+// LockParticipants.
+func checkStudentFree(tx repositories.EnrollmentRepository, studentID uuid.UUID, lessons []models.Lesson) error {
+	for _, lesson := range lessons {
+		overlapping, err := tx.GetOverlappingLessons([]uuid.UUID{studentID}, lesson.StartTime, lesson.EndTime, lesson.ID)
+		if err != nil {
+			return err
+		}
+		if len(overlapping) > 0 {
+			return lessonConflictError(overlapping[0])
+		}
+	}
+	return nil
+}
+
+// checkLessonOpen rejects seat changes in lessons that have started or will not take place.
+func checkLessonOpen(lesson models.Lesson) error {
+	if lesson.Status != models.LessonStatusScheduled && lesson.Status != models.LessonStatusConfirmed {
+		return ErrEnrollmentClosed
+	}
+	if !lesson.StartTime.After(time.Now()) {
+		return ErrEnrollmentClosed
+	}
+	return nil
+}
+
+func isEnrolled(students []models.User, studentID uuid.UUID) bool {
+	for _, student := range students {
+		if student.ID == studentID {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCapacity checks a lesson's or course's capacity against its initial students.
+func validateCapacity(maxStudents, students int) error {
+	if maxStudents < 0 {
+		return errors.New("max_students cannot be negative")
+	}
+	if !models.HasFreeSeat(maxStudents, students-1) {
+		return fmt.Errorf("max_students is %d but %d students were given", maxStudents, students)
+	}
+	return nil
+}
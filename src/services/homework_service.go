@@ -0,0 +1,329 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+	"vibely-backend/src/storage"
+)
+
+// maxSubmissionFiles caps the number of files handed in with one submission.
+const maxSubmissionFiles = 10
+
+// ErrSubmissionResubmitted is returned when grading an attempt the student has since
+// replaced with a new one.
+var ErrSubmissionResubmitted = errors.New("the student handed in a new attempt, review it before grading")
+
+// ErrHomeworkOverviewForbidden is returned when someone other than the student, their
+// tutors or an admin asks for a student's homework overview.
+var ErrHomeworkOverviewForbidden = errors.New("you cannot view this student's homework")
+
+// HomeworkService manages students handing in homework and tutors grading it.
+type HomeworkService interface {
+	Submit(homeworkID, studentID uuid.UUID, text string, uploads []Upload) (models.HomeworkSubmission, error)
+	GetSubmissions(homeworkID, userID uuid.UUID) ([]models.HomeworkSubmission, error)
+	GetSubmission(submissionID, userID uuid.UUID) (models.HomeworkSubmission, error)
+	Grade(submissionID, tutorID uuid.UUID, attempt int, score float64, feedback string) (models.HomeworkSubmission, error)
+	OpenSubmissionFile(fileID, userID uuid.UUID) (models.SubmissionFile, io.ReadCloser, error)
+
+	GetStudentOverview(studentID uuid.UUID, viewer models.User) (models.HomeworkOverview, error)
+}
+
+type homeworkService struct {
+	repo         repositories.HomeworkRepository
+	materialRepo repositories.LessonMaterialRepository
+	lessonRepo   repositories.LessonRepository
+	files        storage.Storage
+	maxFileSize  int64
+}
+
+// NewHomeworkService creates a new instance of HomeworkService. Submitted files larger
+// than maxFileSize bytes are rejected.
+func NewHomeworkService(repo repositories.HomeworkRepository, materialRepo repositories.LessonMaterialRepository, lessonRepo repositories.LessonRepository, files storage.Storage, maxFileSize int64) HomeworkService {
+	return &homeworkService{
+		repo:         repo,
+		materialRepo: materialRepo,
+		lessonRepo:   lessonRepo,
+		files:        files,
+		maxFileSize:  maxFileSize,
+	}
+}
+
+// Submit hands in the student's answer to a homework. Submitting again replaces the
+// earlier text and files and clears any grade, so the tutor grades the new attempt.
+// Attempts after the due date are flagged as late.
+func (s *homeworkService) Submit(homeworkID, studentID uuid.UUID, text string, uploads []Upload) (models.HomeworkSubmission, error) {
+	homework, lesson, err := s.homeworkLesson(homeworkID)
+	if err != nil {
+		return models.HomeworkSubmission{}, err
+	}
+	if !isLessonParticipant(lesson, studentID) || lesson.TutorID == studentID {
+		return models.HomeworkSubmission{}, errors.New("only students of the lesson can hand in its homework")
+	}
+	text = strings.TrimSpace(text)
+	if text == "" && len(uploads) == 0 {
+		return models.HomeworkSubmission{}, errors.New("a submission needs text or files")
+	}
+	if len(uploads) > maxSubmissionFiles {
+		return models.HomeworkSubmission{}, fmt.Errorf("at most %d files can be submitted", maxSubmissionFiles)
+	}
+
+	// Store the files before touching the submission, and drop them again if it fails.
+	files := make([]models.SubmissionFile, 0, len(uploads))
+	cleanup := func(files []models.SubmissionFile) {
+		for _, file := range files {
+			deleteStoredFile(s.files, file.StorageKey)
+		}
+	}
+	for _, upload := range uploads {
+		file := models.SubmissionFile{ID: uuid.New()}
+		file.StorageKey = fmt.Sprintf("homework/%s/%s/%s", homeworkID, studentID, file.ID)
+		stored, err := saveUpload(s.files, file.StorageKey, upload, s.maxFileSize)
+		if err != nil {
+			cleanup(files)
+			return models.HomeworkSubmission{}, err
+		}
+		file.FileName = stored.FileName
+		file.ContentType = stored.ContentType
+		file.Size = stored.Size
+		files = append(files, file)
+	}
+
+	now := time.Now()
+	var submissionID uuid.UUID
+	var replaced []models.SubmissionFile
+	err = s.repo.Transaction(func(tx repositories.HomeworkRepository) error {
+		submission, err := tx.LockSubmission(homeworkID, studentID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			submission = models.HomeworkSubmission{
+				HomeworkID:  homeworkID,
+				StudentID:   studentID,
+				Text:        text,
+				Files:       files,
+				Status:      models.SubmissionStatusSubmitted,
+				Attempt:     1,
+				SubmittedAt: now,
+				Late:        isLate(homework, now),
+			}
+			if err := tx.CreateSubmission(&submission); err != nil {
+				return err
+			}
+			submissionID = submission.ID
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		replaced = submission.Files
+		submission.Text = text
+		submission.Status = models.SubmissionStatusSubmitted
+		submission.Attempt++
+		submission.SubmittedAt = now
+		submission.Late = isLate(homework, now)
+		submission.Score = nil
+		submission.Feedback = ""
+		submission.GradedByID = nil
+		submission.GradedAt = nil
+		if err := tx.UpdateSubmission(&submission); err != nil {
+			return err
+		}
+		submissionID = submission.ID
+		return tx.ReplaceSubmissionFiles(submission.ID, files)
+	})
+	if err != nil {
+		cleanup(files)
+		return models.HomeworkSubmission{}, err
+	}
+	cleanup(replaced)
+
+	return s.repo.GetSubmissionByID(submissionID)
+}
+
+// GetSubmissions lists the submissions for a homework. The tutor sees every student's
+// submission; a student only sees their own.
+func (s *homeworkService) GetSubmissions(homeworkID, userID uuid.UUID) ([]models.HomeworkSubmission, error) {
+	_, lesson, err := s.homeworkLesson(homeworkID)
+	if err != nil {
+		return nil, err
+	}
+	if !isLessonParticipant(lesson, userID) {
+		return nil, ErrNotLessonParticipant
+	}
+
+	submissions, err := s.repo.GetSubmissionsByHomeworkID(homeworkID)
+	if err != nil {
+		return nil, err
+	}
+	if lesson.TutorID == userID {
+		return submissions, nil
+	}
+	own := []models.HomeworkSubmission{}
+	for _, submission := range submissions {
+		if submission.StudentID == userID {
+			own = append(own, submission)
+		}
+	}
+	return own, nil
+}
+
+// GetSubmission returns a submission to the student who handed it in or the lesson's tutor.
+func (s *homeworkService) GetSubmission(submissionID, userID uuid.UUID) (models.HomeworkSubmission, error) {
+	submission, err := s.repo.GetSubmissionByID(submissionID)
+	if err != nil {
+		return models.HomeworkSubmission{}, errors.New("submission not found")
+	}
+	if submission.StudentID == userID {
+		return submission, nil
+	}
+	_, lesson, err := s.homeworkLesson(submission.HomeworkID)
+	if err != nil {
+		return models.HomeworkSubmission{}, err
+	}
+	if lesson.TutorID != userID {
+		return models.HomeworkSubmission{}, ErrNotLessonParticipant
+	}
+	return submission, nil
+}
+
+// Grade records the tutor's score and comments on a submission. Grading again overwrites
+// the earlier grade. Unless attempt is 0, it must be the attempt the student last handed in.
+func (s *homeworkService) Grade(submissionID, tutorID uuid.UUID, attempt int, score float64, feedback string) (models.HomeworkSubmission, error) {
+	submission, err := s.repo.GetSubmissionByID(submissionID)
+	if err != nil {
+		return models.HomeworkSubmission{}, errors.New("submission not found")
+	}
+	homework, lesson, err := s.homeworkLesson(submission.HomeworkID)
+	if err != nil {
+		return models.HomeworkSubmission{}, err
+	}
+	if lesson.TutorID != tutorID {
+		return models.HomeworkSubmission{}, errors.New("only the lesson's tutor can grade its homework")
+	}
+	if score < 0 || score > homework.MaxScore {
+		return models.HomeworkSubmission{}, fmt.Errorf("score must be between 0 and %g", homework.MaxScore)
+	}
+
+	// Graded under the submission's lock, so a concurrent resubmission is either graded
+	// as a whole or not at all.
+	err = s.repo.Transaction(func(tx repositories.HomeworkRepository) error {
+		locked, err := tx.LockSubmission(submission.HomeworkID, submission.StudentID)
+		if err != nil {
+			return err
+		}
+		if attempt != 0 && locked.Attempt != attempt {
+			return ErrSubmissionResubmitted
+		}
+		now := time.Now()
+		locked.Score = &score
+		locked.Feedback = strings.TrimSpace(feedback)
+		locked.GradedByID = &tutorID
+		locked.GradedAt = &now
+		locked.Status = models.SubmissionStatusGraded
+		return tx.GradeSubmission(&locked)
+	})
+	if err != nil {
+		return models.HomeworkSubmission{}, err
+	}
+	return s.repo.GetSubmissionByID(submissionID)
+}
+
+// OpenSubmissionFile returns a submitted file with its contents for the student who
+// handed it in or the lesson's tutor. The caller closes the returned reader.
+func (s *homeworkService) OpenSubmissionFile(fileID, userID uuid.UUID) (models.SubmissionFile, io.ReadCloser, error) {
+	file, err := s.repo.GetSubmissionFileByID(fileID)
+	if err != nil {
+		return models.SubmissionFile{}, nil, errors.New("file not found")
+	}
+	if _, err := s.GetSubmission(file.SubmissionID, userID); err != nil {
+		return models.SubmissionFile{}, nil, err
+	}
+
+	contents, err := s.files.Open(file.StorageKey)
+	if err != nil {
+		return models.SubmissionFile{}, nil, err
+	}
+	return file, contents, nil
+}
+
+// GetStudentOverview sorts the homework from the student's lessons into outstanding,
+// waiting for grading and graded. The student and admins see all of it; a tutor of the
+// student only sees the homework from their own lessons.
+func (s *homeworkService) GetStudentOverview(studentID uuid.UUID, viewer models.User) (models.HomeworkOverview, error) {
+	tutorID := uuid.Nil
+	if viewer.ID != studentID && viewer.Role != models.UserRoleAdmin {
+		if viewer.Role != models.UserRoleTutor {
+			return models.HomeworkOverview{}, ErrHomeworkOverviewForbidden
+		}
+		teaches, err := s.lessonRepo.HasLessonsWithTutor(viewer.ID, studentID)
+		if err != nil {
+			return models.HomeworkOverview{}, err
+		}
+		if !teaches {
+			return models.HomeworkOverview{}, ErrHomeworkOverviewForbidden
+		}
+		tutorID = viewer.ID
+	}
+
+	homework, err := s.repo.GetHomeworkForStudent(studentID, tutorID)
+	if err != nil {
+		return models.HomeworkOverview{}, err
+	}
+	submissions, err := s.repo.GetSubmissionsByStudentID(studentID)
+	if err != nil {
+		return models.HomeworkOverview{}, err
+	}
+	byHomework := make(map[uuid.UUID]models.HomeworkSubmission, len(submissions))
+	for _, submission := range submissions {
+		byHomework[submission.HomeworkID] = submission
+	}
+
+	now := time.Now()
+	overview := models.HomeworkOverview{
+		StudentID:   studentID,
+		Outstanding: []models.StudentHomework{},
+		Submitted:   []models.StudentHomework{},
+		Graded:      []models.StudentHomework{},
+	}
+	for _, hw := range homework {
+		item := models.StudentHomework{Homework: hw}
+		submission, ok := byHomework[hw.ID]
+		switch {
+		case !ok:
+			item.Overdue = isLate(hw, now)
+			overview.Outstanding = append(overview.Outstanding, item)
+		case submission.Status == models.SubmissionStatusGraded:
+			item.Submission = &submission
+			overview.Graded = append(overview.Graded, item)
+		default:
+			item.Submission = &submission
+			overview.Submitted = append(overview.Submitted, item)
+		}
+	}
+	return overview, nil
+}
+
+// homeworkLesson returns the homework with the lesson it was given in.
+func (s *homeworkService) homeworkLesson(homeworkID uuid.UUID) (models.Homework, models.Lesson, error) {
+	homework, err := s.materialRepo.GetHomeworkByID(homeworkID)
+	if err != nil {
+		return models.Homework{}, models.Lesson{}, errors.New("homework not found")
+	}
+	lesson, err := s.lessonRepo.GetLessonWithParticipants(homework.LessonID)
+	if err != nil {
+		return models.Homework{}, models.Lesson{}, errors.New("lesson not found")
+	}
+	return homework, lesson, nil
+}
+
+// isLate reports whether handing in the homework at t would be past its due date.
+func isLate(homework models.Homework, t time.Time) bool {
+	return homework.DueAt != nil && t.After(*homework.DueAt)
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+	"vibely-backend/src/storage"
+)
+
+// Lesson material errors
+var (
+	// ErrNotLessonParticipant is returned when someone outside a lesson asks for its
+	// materials or tries to change it.
+	ErrNotLessonParticipant = errors.New("only lesson participants can access this lesson")
+	// ErrNotLessonTutor is returned when someone other than the lesson's tutor changes its materials.
+	ErrNotLessonTutor = errors.New("only the lesson's tutor can manage its materials")
+)
+
+// LessonMaterialService manages what a tutor shares with the participants of a lesson:
+// notes on what was covered, homework and file attachments.
+type LessonMaterialService interface {
+	GetMaterials(lessonID, userID uuid.UUID) (models.LessonMaterials, error)
+
+	AddNote(lessonID, tutorID uuid.UUID, body string) (models.LessonNote, error)
+	UpdateNote(noteID, tutorID uuid.UUID, body string) (models.LessonNote, error)
+	DeleteNote(noteID, tutorID uuid.UUID) error
+
+	AddHomework(lessonID, tutorID uuid.UUID, homework models.Homework) (models.Homework, error)
+	UpdateHomework(homeworkID, tutorID uuid.UUID, homework models.Homework) (models.Homework, error)
+	DeleteHomework(homeworkID, tutorID uuid.UUID) error
+
+	AddAttachment(lessonID, tutorID uuid.UUID, upload Upload) (models.LessonAttachment, error)
+	OpenAttachment(attachmentID, userID uuid.UUID) (models.LessonAttachment, io.ReadCloser, error)
+	DeleteAttachment(attachmentID, tutorID uuid.UUID) error
+}
+
+type lessonMaterialService struct {
+	repo          repositories.LessonMaterialRepository
+	lessonRepo    repositories.LessonRepository
+	files         storage.Storage
+	maxAttachment int64
+}
+
+// NewLessonMaterialService creates a new instance of LessonMaterialService. Attachments
+// larger than maxAttachment bytes are rejected.
+func NewLessonMaterialService(repo repositories.LessonMaterialRepository, lessonRepo repositories.LessonRepository, files storage.Storage, maxAttachment int64) LessonMaterialService {
+	return &lessonMaterialService{
+		repo:          repo,
+		lessonRepo:    lessonRepo,
+		files:         files,
+		maxAttachment: maxAttachment,
+	}
+}
+
+// GetMaterials lists the notes, homework and attachments of a lesson for one of its participants.
+func (s *lessonMaterialService) GetMaterials(lessonID, userID uuid.UUID) (models.LessonMaterials, error) {
+	if _, err := s.participantLesson(lessonID, userID); err != nil {
+		return models.LessonMaterials{}, err
+	}
+	return s.repo.GetLessonMaterials(lessonID)
+}
+
+func (s *lessonMaterialService) AddNote(lessonID, tutorID uuid.UUID, body string) (models.LessonNote, error) {
+	if _, err := s.tutorLesson(lessonID, tutorID); err != nil {
+		return models.LessonNote{}, err
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return models.LessonNote{}, errors.New("note body is required")
+	}
+
+	note := models.LessonNote{LessonID: lessonID, AuthorID: tutorID, Body: body}
+	if err := s.repo.CreateNote(&note); err != nil {
+		return models.LessonNote{}, err
+	}
+	return note, nil
+}
+
+func (s *lessonMaterialService) UpdateNote(noteID, tutorID uuid.UUID, body string) (models.LessonNote, error) {
+	note, err := s.repo.GetNoteByID(noteID)
+	if err != nil {
+		return models.LessonNote{}, errors.New("note not found")
+	}
+	if _, err := s.tutorLesson(note.LessonID, tutorID); err != nil {
+		return models.LessonNote{}, err
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return models.LessonNote{}, errors.New("note body is required")
+	}
+
+	note.Body = body
+	if err := s.repo.UpdateNote(&note); err != nil {
+		return models.LessonNote{}, err
+	}
+	return note, nil
+}
+
+func (s *lessonMaterialService) DeleteNote(noteID, tutorID uuid.UUID) error {
+	note, err := s.repo.GetNoteByID(noteID)
+	if err != nil {
+		return errors.New("note not found")
+	}
+	if _, err := s.tutorLesson(note.LessonID, tutorID); err != nil {
+		return err
+	}
+	return s.repo.DeleteNote(noteID)
+}
+
+func (s *lessonMaterialService) AddHomework(lessonID, tutorID uuid.UUID, homework models.Homework) (models.Homework, error) {
+	lesson, err := s.tutorLesson(lessonID, tutorID)
+	if err != nil {
+		return models.Homework{}, err
+	}
+	if err := validateHomework(&homework, lesson); err != nil {
+		return models.Homework{}, err
+	}
+
+	homework.ID = uuid.Nil
+	homework.LessonID = lessonID
+	if err := s.repo.CreateHomework(&homework); err != nil {
+		return models.Homework{}, err
+	}
+	return homework, nil
+}
+
+func (s *lessonMaterialService) UpdateHomework(homeworkID, tutorID uuid.UUID, homework models.Homework) (models.Homework, error) {
+	existing, err := s.repo.GetHomeworkByID(homeworkID)
+	if err != nil {
+		return models.Homework{}, errors.New("homework not found")
+	}
+	lesson, err := s.tutorLesson(existing.LessonID, tutorID)
+	if err != nil {
+		return models.Homework{}, err
+	}
+	if err := validateHomework(&homework, lesson); err != nil {
+		return models.Homework{}, err
+	}
+
+	existing.Title = homework.Title
+	existing.Description = homework.Description
+	existing.DueAt = homework.DueAt
+	existing.MaxScore = homework.MaxScore
+	if err := s.repo.UpdateHomework(&existing); err != nil {
+		return models.Homework{}, err
+	}
+	return existing, nil
+}
+
+func (s *lessonMaterialService) DeleteHomework(homeworkID, tutorID uuid.UUID) error {
+	homework, err := s.repo.GetHomeworkByID(homeworkID)
+	if err != nil {
+		return errors.New("homework not found")
+	}
+	if _, err := s.tutorLesson(homework.LessonID, tutorID); err != nil {
+		return err
+	}
+	submissions, err := s.repo.CountSubmissions(homeworkID)
+	if err != nil {
+		return err
+	}
+	if submissions > 0 {
+		return errors.New("homework that students have handed in cannot be deleted")
+	}
+	return s.repo.DeleteHomework(homeworkID)
+}
+
+// AddAttachment stores the uploaded file and records it on the lesson.
+func (s *lessonMaterialService) AddAttachment(lessonID, tutorID uuid.UUID, upload Upload) (models.LessonAttachment, error) {
+	if _, err := s.tutorLesson(lessonID, tutorID); err != nil {
+		return models.LessonAttachment{}, err
+	}
+	attachment := models.LessonAttachment{
+		ID:         uuid.New(),
+		LessonID:   lessonID,
+		UploaderID: tutorID,
+	}
+	attachment.StorageKey = fmt.Sprintf("lessons/%s/%s", lessonID, attachment.ID)
+
+	stored, err := saveUpload(s.files, attachment.StorageKey, upload, s.maxAttachment)
+	if err != nil {
+		return models.LessonAttachment{}, err
+	}
+	attachment.FileName = stored.FileName
+	attachment.ContentType = stored.ContentType
+	attachment.Size = stored.Size
+
+	if err := s.repo.CreateAttachment(&attachment); err != nil {
+		deleteStoredFile(s.files, attachment.StorageKey)
+		return models.LessonAttachment{}, err
+	}
+	return attachment, nil
+}
+
+// OpenAttachment returns an attachment with its contents for one of the lesson's
+// participants. The caller closes the returned reader.
+func (s *lessonMaterialService) OpenAttachment(attachmentID, userID uuid.UUID) (models.LessonAttachment, io.ReadCloser, error) {
+	attachment, err := s.repo.GetAttachmentByID(attachmentID)
+	if err != nil {
+		return models.LessonAttachment{}, nil, errors.New("attachment not found")
+	}
+	if _, err := s.participantLesson(attachment.LessonID, userID); err != nil {
+		return models.LessonAttachment{}, nil, err
+	}
+
+	file, err := s.files.Open(attachment.StorageKey)
+	if err != nil {
+		return models.LessonAttachment{}, nil, err
+	}
+	return attachment, file, nil
+}
+
+// DeleteAttachment removes the attachment record first, so a file that fails to be
+// deleted is at worst orphaned in storage, never listed without its contents.
+func (s *lessonMaterialService) DeleteAttachment(attachmentID, tutorID uuid.UUID) error {
+	attachment, err := s.repo.GetAttachmentByID(attachmentID)
+	if err != nil {
+		return errors.New("attachment not found")
+	}
+	if _, err := s.tutorLesson(attachment.LessonID, tutorID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteAttachment(attachmentID); err != nil {
+		return err
+	}
+	deleteStoredFile(s.files, attachment.StorageKey)
+	return nil
+}
+
+// participantLesson returns the lesson if userID is its tutor or one of its students.
+func (s *lessonMaterialService) participantLesson(lessonID, userID uuid.UUID) (models.Lesson, error) {
+	lesson, err := s.lessonRepo.GetLessonWithParticipants(lessonID)
+	if err != nil {
+		return models.Lesson{}, errors.New("lesson not found")
+	}
+	if !isLessonParticipant(lesson, userID) {
+		return models.Lesson{}, ErrNotLessonParticipant
+	}
+	return lesson, nil
+}
+
+// tutorLesson returns the lesson if tutorID is its tutor.
+func (s *lessonMaterialService) tutorLesson(lessonID, tutorID uuid.UUID) (models.Lesson, error) {
+	lesson, err := s.lessonRepo.GetLessonWithParticipants(lessonID)
+	if err != nil {
+		return models.Lesson{}, errors.New("lesson not found")
+	}
+	if lesson.TutorID != tutorID {
+		return models.Lesson{}, ErrNotLessonTutor
+	}
+	return lesson, nil
+}
+
+// validateHomework checks the assignment has a title, a non-negative maximum score
+// (100 when unset) and, if it has a due date, that it falls after the lesson has started.
+func validateHomework(homework *models.Homework, lesson models.Lesson) error {
+	homework.Title = strings.TrimSpace(homework.Title)
+	if homework.Title == "" {
+		return errors.New("homework title is required")
+	}
+	if homework.MaxScore < 0 {
+		return errors.New("max_score cannot be negative")
+	}
+	if homework.MaxScore == 0 {
+		homework.MaxScore = 100
+	}
+	if homework.DueAt != nil {
+		due := homework.DueAt.UTC()
+		if !due.After(lesson.StartTime) {
+			return errors.New("homework must be due after the lesson starts")
+		}
+		homework.DueAt = &due
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vibely-backend/src/events"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// LessonReminderJob is the kind of the queued jobs reminding participants of a lesson.
+const LessonReminderJob = "lesson-reminder"
+
+// lessonReminder is the payload of a lesson reminder job.
+type lessonReminder struct {
+	LessonID  uuid.UUID `json:"lesson_id"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// LessonReminderService reminds the tutor and students of a lesson shortly before it
+// starts. Reminders are queued jobs, scheduled from the lesson's domain events.
+type LessonReminderService interface {
+	// Publish schedules, moves or drops reminders as lessons are created, postponed
+	// or no longer take place.
+	events.Publisher
+
+	// SendReminder runs a lesson reminder job.
+	SendReminder(payload []byte) error
+}
+
+type lessonReminderService struct {
+	jobRepo    repositories.JobRepository
+	lessonRepo repositories.LessonRepository
+	publisher  events.Publisher
+	offsets    []time.Duration
+}
+
+// NewLessonReminderService creates a new instance of LessonReminderService, sending a
+// reminder each offset before a lesson starts. Reminders are published as domain events.
+func NewLessonReminderService(jobRepo repositories.JobRepository, lessonRepo repositories.LessonRepository, publisher events.Publisher, offsets []time.Duration) LessonReminderService {
+	return &lessonReminderService{
+		jobRepo:    jobRepo,
+		lessonRepo: lessonRepo,
+		publisher:  publisher,
+		offsets:    offsets,
+	}
+}
+
+func (s *lessonReminderService) Publish(eventType string, data interface{}, topics ...string) {
+	change, ok := data.(events.LessonChange)
+	if !ok {
+		return
+	}
+	var err error
+	switch {
+	case eventType == events.LessonCreated:
+		err = s.schedule(change)
+	case change.Action == events.LessonActionPostponed:
+		if err = s.cancel(change.LessonID); err == nil {
+			err = s.schedule(change)
+		}
+	case slotReleased(change.Action):
+		err = s.cancel(change.LessonID)
+	}
+	if err != nil {
+		log.Printf("Failed to update the reminders of lesson %s: %v", change.LessonID, err)
+	}
+}
+
+// schedule queues the lesson's reminders that are still ahead. Scheduling a reminder
+// again replaces it, as its key only depends on the lesson and the offset.
+func (s *lessonReminderService) schedule(change events.LessonChange) error {
+	payload, err := json.Marshal(lessonReminder{LessonID: change.LessonID, StartTime: change.StartTime})
+	if err != nil {
+		return err
+	}
+	for _, offset := range s.offsets {
+		runAt := change.StartTime.Add(-offset)
+		if runAt.Before(time.Now()) {
+			continue
+		}
+		job := models.QueuedJob{
+			Kind:    LessonReminderJob,
+			Key:     fmt.Sprintf("%s%s", lessonReminderKeyPrefix(change.LessonID), offset),
+			Payload: string(payload),
+			RunAt:   runAt,
+		}
+		if err := s.jobRepo.Enqueue(&job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *lessonReminderService) cancel(lessonID uuid.UUID) error {
+	_, err := s.jobRepo.CancelJobs(lessonReminderKeyPrefix(lessonID))
+	return err
+}
+
+// lessonReminderKeyPrefix is the start of the keys of all reminders of a lesson.
+func lessonReminderKeyPrefix(lessonID uuid.UUID) string {
+	return LessonReminderJob + ":" + lessonID.String() + ":"
+}
+
+// SendReminder publishes a reminder to the lesson's participants. Reminders of lessons
+// that were moved or will no longer take place are dropped, in case they ran before
+// being cancelled.
+func (s *lessonReminderService) SendReminder(payload []byte) error {
+	var reminder lessonReminder
+	if err := json.Unmarshal(payload, &reminder); err != nil {
+		return err
+	}
+	lesson, err := s.lessonRepo.GetLessonWithParticipants(reminder.LessonID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !lesson.StartTime.Equal(reminder.StartTime) ||
+		(lesson.Status != models.LessonStatusScheduled && lesson.Status != models.LessonStatusConfirmed) {
+		return nil
+	}
+
+	change := events.LessonChange{
+		LessonID:  lesson.ID,
+		TutorID:   lesson.TutorID,
+		CourseID:  lesson.CourseID,
+		Title:     lesson.Title,
+		Status:    lesson.Status,
+		StartTime: lesson.StartTime,
+		EndTime:   lesson.EndTime,
+	}
+	s.publisher.Publish(events.LessonReminder, change, events.UserTopics(lessonParticipantIDs(lesson)...)...)
+	return nil
+}
@@ -0,0 +1,607 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// defaultSeriesTimezone is used when a series is created without a time zone.
+const defaultSeriesTimezone = "Europe/Warsaw"
+
+// Lesson series errors
+var (
+	// ErrNotSeriesTutor is returned when someone other than the series' tutor or an admin
+	// creates or edits a series.
+	ErrNotSeriesTutor = errors.New("only the series' tutor can manage it")
+	// ErrNotSeriesParticipant is returned when someone outside a series views or cancels it.
+	ErrNotSeriesParticipant = errors.New("only series participants can access it")
+)
+
+// LessonSeriesService defines business logic for recurring lesson series.
+type LessonSeriesService interface {
+	CreateSeries(series models.LessonSeries, actor models.User) (models.LessonSeriesResult, error)
+	GetSeries(seriesID uuid.UUID, viewer models.User) (models.LessonSeries, error)
+	UpdateOccurrence(seriesID, lessonID uuid.UUID, actor models.User, scope string, changes models.LessonSeriesChanges) (models.LessonSeriesResult, error)
+	CancelOccurrence(seriesID, lessonID uuid.UUID, actor models.User, scope string) (models.LessonSeriesResult, error)
+
+	// ExtendHorizons materializes upcoming occurrences of every active series.
+	ExtendHorizons() error
+}
+
+type lessonSeriesService struct {
+	seriesRepo    repositories.LessonSeriesRepository
+	lessonRepo    repositories.LessonRepository
+	userRepo      repositories.UserRepository
+	lessonService LessonService
+	horizon       time.Duration
+}
+
+// NewLessonSeriesService creates a new instance of LessonSeriesService.
+// Occurrences are materialized as Lesson rows up to horizon ahead of now.
+func NewLessonSeriesService(seriesRepo repositories.LessonSeriesRepository, lessonRepo repositories.LessonRepository, userRepo repositories.UserRepository, lessonService LessonService, horizon time.Duration) LessonSeriesService {
+	return &lessonSeriesService{
+		seriesRepo:    seriesRepo,
+		lessonRepo:    lessonRepo,
+		userRepo:      userRepo,
+		lessonService: lessonService,
+		horizon:       horizon,
+	}
+}
+
+// CreateSeries validates and stores a series, then books its occurrences within the horizon.
+// Occurrences that cannot be booked are reported as conflicts instead of failing the series.
+// Only the series' tutor or an admin may create it.
+func (s *lessonSeriesService) CreateSeries(series models.LessonSeries, actor models.User) (models.LessonSeriesResult, error) {
+	if series.TutorID == uuid.Nil {
+		return models.LessonSeriesResult{}, errors.New("tutor_id is required")
+	}
+	if !canManageSeries(series, actor) {
+		return models.LessonSeriesResult{}, ErrNotSeriesTutor
+	}
+	if len(series.Students) == 0 {
+		return models.LessonSeriesResult{}, errors.New("at least one student is required")
+	}
+	if series.DurationMinutes <= 0 {
+		return models.LessonSeriesResult{}, errors.New("duration_minutes must be positive")
+	}
+
+	tutor, err := s.userRepo.GetUserByID(series.TutorID)
+	if err != nil {
+		return models.LessonSeriesResult{}, errors.New("tutor not found")
+	}
+	if tutor.Role != models.UserRoleTutor {
+		return models.LessonSeriesResult{}, errors.New("user is not a tutor")
+	}
+
+	if series.Timezone == "" {
+		series.Timezone = defaultSeriesTimezone
+	}
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return models.LessonSeriesResult{}, errors.New("invalid timezone")
+	}
+	rule, err := parseRRule(series.RRule)
+	if err != nil {
+		return models.LessonSeriesResult{}, err
+	}
+	if series.StartTime.Before(time.Now()) {
+		return models.LessonSeriesResult{}, fmt.Errorf("%w: series cannot start in the past", ErrInvalidLessonTime)
+	}
+
+	series.StartTime = series.StartTime.In(loc)
+	series.RRule = rule.String()
+	series.Status = models.LessonSeriesStatusActive
+	series.MaterializedUntil = series.StartTime
+	if err := s.seriesRepo.CreateSeries(&series); err != nil {
+		return models.LessonSeriesResult{}, err
+	}
+	series.Tutor = tutor
+
+	lessons, conflicts, err := s.materialize(&series, time.Now().Add(s.horizon), actor.ID)
+	if err != nil {
+		// Don't leave a half-booked series behind.
+		if deleteErr := s.seriesRepo.DeleteSeries(series.ID); deleteErr != nil {
+			log.Printf("Failed to delete lesson series %s after failing to book it: %v", series.ID, deleteErr)
+		}
+		return models.LessonSeriesResult{}, err
+	}
+	return models.LessonSeriesResult{Series: series, Lessons: lessons, Conflicts: conflicts}, nil
+}
+
+// GetSeries retrieves a series with its participants and materialized lessons. Only its
+// participants and admins may view it.
+func (s *lessonSeriesService) GetSeries(seriesID uuid.UUID, viewer models.User) (models.LessonSeries, error) {
+	series, err := s.seriesRepo.GetSeriesWithLessons(seriesID)
+	if err != nil {
+		return models.LessonSeries{}, err
+	}
+	if !isSeriesParticipant(series, viewer.ID) && viewer.Role != models.UserRoleAdmin {
+		return models.LessonSeries{}, ErrNotSeriesParticipant
+	}
+	return series, nil
+}
+
+// UpdateOccurrence edits the occurrence held by lessonID and, depending on scope,
+// the following occurrences or the whole series. A time change is applied as a shift
+// relative to the edited occurrence; occurrences that cannot be moved are reported.
+// Only the series' tutor or an admin may edit it.
+func (s *lessonSeriesService) UpdateOccurrence(seriesID, lessonID uuid.UUID, actor models.User, scope string, changes models.LessonSeriesChanges) (models.LessonSeriesResult, error) {
+	series, lesson, err := s.getSeriesOccurrence(seriesID, lessonID)
+	if err != nil {
+		return models.LessonSeriesResult{}, err
+	}
+	if !canManageSeries(series, actor) {
+		return models.LessonSeriesResult{}, ErrNotSeriesTutor
+	}
+	actorID := actor.ID
+	if changes.DurationMinutes != nil && *changes.DurationMinutes <= 0 {
+		return models.LessonSeriesResult{}, errors.New("duration_minutes must be positive")
+	}
+
+	switch scope {
+	case models.SeriesScopeThis:
+		return s.updateSingleOccurrence(series, lesson, actorID, changes)
+	case models.SeriesScopeFollowing, models.SeriesScopeAll:
+	default:
+		return models.LessonSeriesResult{}, errors.New("scope must be one of: this, following, all")
+	}
+
+	lessons, err := s.seriesRepo.GetSeriesLessons(series.ID)
+	if err != nil {
+		return models.LessonSeriesResult{}, err
+	}
+
+	pivot := occurrenceStart(lesson)
+	var delta time.Duration
+	if changes.StartTime != nil {
+		delta = changes.StartTime.Sub(lesson.StartTime)
+	}
+	duration := series.DurationMinutes
+	if changes.DurationMinutes != nil {
+		duration = *changes.DurationMinutes
+	}
+
+	// Lessons that already started are history and stay as they are.
+	now := time.Now()
+	var affected []models.Lesson
+	for _, l := range lessons {
+		if scope == models.SeriesScopeFollowing && occurrenceStart(l).Before(pivot) {
+			continue
+		}
+		if !l.StartTime.After(now) {
+			continue
+		}
+		affected = append(affected, l)
+	}
+
+	// "This and following" on anything but the first occurrence splits the series in two.
+	target := &series
+	if scope == models.SeriesScopeFollowing {
+		target, err = s.splitSeries(&series, pivot)
+		if err != nil {
+			return models.LessonSeriesResult{}, err
+		}
+	}
+
+	if err := shiftSeriesStart(target, delta); err != nil {
+		return models.LessonSeriesResult{}, err
+	}
+	target.MaterializedUntil = target.MaterializedUntil.Add(delta)
+	target.DurationMinutes = duration
+	if changes.Title != nil {
+		target.Title = *changes.Title
+	}
+	if changes.Description != nil {
+		target.Description = *changes.Description
+	}
+	if err := s.seriesRepo.UpdateSeries(target); err != nil {
+		return models.LessonSeriesResult{}, err
+	}
+
+	result := models.LessonSeriesResult{Series: *target}
+	for _, l := range affected {
+		if l.Status == models.LessonStatusScheduled || l.Status == models.LessonStatusConfirmed {
+			newStart := l.StartTime.Add(delta)
+			newEnd := newStart.Add(time.Duration(duration) * time.Minute)
+			if !newStart.Equal(l.StartTime) || !newEnd.Equal(l.EndTime) {
+				moved, err := s.lessonService.PostponeLesson(l.ID, actorID, newStart, newEnd)
+				if err != nil {
+					if !isSchedulingConflict(err) {
+						return result, err
+					}
+					id := l.ID
+					result.Conflicts = append(result.Conflicts, models.SeriesOccurrenceConflict{
+						LessonID:  &id,
+						StartTime: newStart,
+						EndTime:   newEnd,
+						Reason:    err.Error(),
+					})
+				} else {
+					l = moved
+				}
+			}
+		}
+
+		l.SeriesID = &target.ID
+		if l.OccurrenceStart != nil {
+			shifted := l.OccurrenceStart.Add(delta)
+			l.OccurrenceStart = &shifted
+		}
+		if changes.Title != nil {
+			l.Title = *changes.Title
+		}
+		if changes.Description != nil {
+			l.Description = *changes.Description
+		}
+		if err := s.lessonRepo.UpdateLesson(&l); err != nil {
+			return result, err
+		}
+		result.Lessons = append(result.Lessons, l)
+	}
+
+	// Book any occurrences the new schedule adds within the horizon.
+	created, conflicts, err := s.materialize(target, time.Now().Add(s.horizon), actorID)
+	if err != nil {
+		return result, err
+	}
+	result.Series = *target
+	result.Lessons = append(result.Lessons, created...)
+	result.Conflicts = append(result.Conflicts, conflicts...)
+	return result, nil
+}
+
+// CancelOccurrence cancels the occurrence held by lessonID and, depending on scope,
+// the following occurrences or the whole series. Only the series' participants may cancel it.
+func (s *lessonSeriesService) CancelOccurrence(seriesID, lessonID uuid.UUID, actor models.User, scope string) (models.LessonSeriesResult, error) {
+	series, lesson, err := s.getSeriesOccurrence(seriesID, lessonID)
+	if err != nil {
+		return models.LessonSeriesResult{}, err
+	}
+	if !isSeriesParticipant(series, actor.ID) {
+		return models.LessonSeriesResult{}, ErrNotSeriesParticipant
+	}
+	actorID := actor.ID
+
+	if scope == models.SeriesScopeThis {
+		cancelled, err := s.lessonService.CancelLesson(lesson.ID, actorID, models.CancellationReasonOther, "series occurrence cancelled")
+		if err != nil {
+			return models.LessonSeriesResult{}, err
+		}
+		return models.LessonSeriesResult{Series: series, Lessons: []models.Lesson{cancelled}}, nil
+	}
+	if scope != models.SeriesScopeFollowing && scope != models.SeriesScopeAll {
+		return models.LessonSeriesResult{}, errors.New("scope must be one of: this, following, all")
+	}
+
+	lessons, err := s.seriesRepo.GetSeriesLessons(series.ID)
+	if err != nil {
+		return models.LessonSeriesResult{}, err
+	}
+
+	pivot := occurrenceStart(lesson)
+	now := time.Now()
+	result := models.LessonSeriesResult{}
+	for _, l := range lessons {
+		if scope == models.SeriesScopeFollowing && occurrenceStart(l).Before(pivot) {
+			continue
+		}
+		if !l.StartTime.After(now) ||
+			(l.Status != models.LessonStatusScheduled && l.Status != models.LessonStatusConfirmed) {
+			continue
+		}
+		cancelled, err := s.lessonService.CancelLesson(l.ID, actorID, models.CancellationReasonOther, "series occurrences cancelled")
+		if err != nil {
+			return result, err
+		}
+		result.Lessons = append(result.Lessons, cancelled)
+	}
+
+	if scope == models.SeriesScopeAll || !pivot.After(series.StartTime) {
+		series.Status = models.LessonSeriesStatusCancelled
+	} else if err := s.endSeriesBefore(&series, pivot); err != nil {
+		return result, err
+	}
+	if err := s.seriesRepo.UpdateSeries(&series); err != nil {
+		return result, err
+	}
+	result.Series = series
+	return result, nil
+}
+
+// ExtendHorizons materializes occurrences of every active series up to the horizon.
+// Occurrences that cannot be booked are skipped and logged.
+func (s *lessonSeriesService) ExtendHorizons() error {
+	horizon := time.Now().Add(s.horizon)
+	seriesList, err := s.seriesRepo.GetSeriesDueForMaterialization(horizon)
+	if err != nil {
+		return err
+	}
+
+	for i := range seriesList {
+		series := &seriesList[i]
+		_, conflicts, err := s.materialize(series, horizon, uuid.Nil)
+		if err != nil {
+			log.Printf("Failed to materialize lesson series %s: %v", series.ID, err)
+			continue
+		}
+		for _, conflict := range conflicts {
+			log.Printf("Lesson series %s: skipped occurrence at %s: %s",
+				series.ID, conflict.StartTime.Format(time.RFC3339), conflict.Reason)
+		}
+	}
+	return nil
+}
+
+// materialize books the occurrences of series between its MaterializedUntil and until,
+// skipping occurrences that already have a lesson, and advances MaterializedUntil.
+// actorID is recorded as the creator of the lessons; uuid.Nil marks the system.
+func (s *lessonSeriesService) materialize(series *models.LessonSeries, until time.Time, actorID uuid.UUID) ([]models.Lesson, []models.SeriesOccurrenceConflict, error) {
+	rule, err := parseRRule(series.RRule)
+	if err != nil {
+		return nil, nil, err
+	}
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from := series.MaterializedUntil
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+	if !until.After(from) {
+		return nil, nil, nil
+	}
+
+	existing, err := s.seriesRepo.GetSeriesLessons(series.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	booked := make(map[int64]bool, len(existing))
+	for _, l := range existing {
+		booked[occurrenceStart(l).Unix()] = true
+	}
+
+	var lessons []models.Lesson
+	var conflicts []models.SeriesOccurrenceConflict
+	duration := time.Duration(series.DurationMinutes) * time.Minute
+	dtstart := series.StartTime.In(loc)
+
+	for _, occurrence := range rule.occurrences(dtstart, from, until) {
+		if booked[occurrence.Unix()] {
+			continue
+		}
+		occ := occurrence
+		lesson := models.Lesson{
+			TutorID:         series.TutorID,
+			Students:        series.Students,
+			Title:           series.Title,
+			Description:     series.Description,
+			Subject:         series.Subject,
+			Level:           series.Level,
+			StartTime:       occ,
+			EndTime:         occ.Add(duration),
+			SeriesID:        &series.ID,
+			OccurrenceStart: &occ,
+		}
+		created, err := s.lessonService.ScheduleLesson(lesson, actorID)
+		if err != nil {
+			if !isSchedulingConflict(err) {
+				return lessons, conflicts, err
+			}
+			conflicts = append(conflicts, models.SeriesOccurrenceConflict{
+				StartTime: lesson.StartTime,
+				EndTime:   lesson.EndTime,
+				Reason:    err.Error(),
+			})
+			continue
+		}
+		lessons = append(lessons, created)
+	}
+
+	series.MaterializedUntil = until
+	// A finite rule with nothing left after the horizon is over.
+	if len(rule.occurrences(dtstart, until, until.AddDate(100, 0, 0))) == 0 {
+		series.Status = models.LessonSeriesStatusEnded
+	}
+	if err := s.seriesRepo.UpdateSeries(series); err != nil {
+		return lessons, conflicts, err
+	}
+	return lessons, conflicts, nil
+}
+
+// splitSeries ends series just before pivot and creates a new series carrying the
+// remaining occurrences. If pivot is the first occurrence, series itself is returned.
+func (s *lessonSeriesService) splitSeries(series *models.LessonSeries, pivot time.Time) (*models.LessonSeries, error) {
+	if !pivot.After(series.StartTime) {
+		return series, nil
+	}
+
+	rule, err := parseRRule(series.RRule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := rule
+	if rule.Count > 0 {
+		before := len(rule.occurrences(series.StartTime.In(loc), series.StartTime, pivot))
+		remaining.Count = rule.Count - before
+	}
+
+	following := models.LessonSeries{
+		TutorID:           series.TutorID,
+		Students:          series.Students,
+		Title:             series.Title,
+		Description:       series.Description,
+		Subject:           series.Subject,
+		Level:             series.Level,
+		StartTime:         pivot.In(loc),
+		DurationMinutes:   series.DurationMinutes,
+		RRule:             remaining.String(),
+		Timezone:          series.Timezone,
+		MaterializedUntil: series.MaterializedUntil,
+		Status:            models.LessonSeriesStatusActive,
+	}
+	if err := s.seriesRepo.CreateSeries(&following); err != nil {
+		return nil, err
+	}
+	following.Tutor = series.Tutor
+
+	if err := s.endSeriesBefore(series, pivot); err != nil {
+		return nil, err
+	}
+	if err := s.seriesRepo.UpdateSeries(series); err != nil {
+		return nil, err
+	}
+	return &following, nil
+}
+
+// endSeriesBefore rewrites the series rule so that its last occurrence is before pivot.
+func (s *lessonSeriesService) endSeriesBefore(series *models.LessonSeries, pivot time.Time) error {
+	rule, err := parseRRule(series.RRule)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return err
+	}
+
+	if rule.Count > 0 {
+		rule.Count = len(rule.occurrences(series.StartTime.In(loc), series.StartTime, pivot))
+	} else {
+		rule.Until = pivot.Add(-time.Second)
+	}
+	series.RRule = rule.String()
+	series.Status = models.LessonSeriesStatusEnded
+	if series.MaterializedUntil.After(pivot) {
+		series.MaterializedUntil = pivot
+	}
+	return nil
+}
+
+// shiftSeriesStart moves the series' start by delta. When this moves it to another day of
+// the week, the weekdays of a BYDAY rule move along, so that occurrences booked later land
+// on the same days as the lessons moved now.
+func shiftSeriesStart(series *models.LessonSeries, delta time.Duration) error {
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return err
+	}
+	oldStart := series.StartTime.In(loc)
+	newStart := oldStart.Add(delta)
+	series.StartTime = newStart
+
+	oldDay := time.Date(oldStart.Year(), oldStart.Month(), oldStart.Day(), 0, 0, 0, 0, time.UTC)
+	newDay := time.Date(newStart.Year(), newStart.Month(), newStart.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(newDay.Sub(oldDay).Hours() / 24)
+	if days%7 == 0 {
+		return nil
+	}
+	rule, err := parseRRule(series.RRule)
+	if err != nil {
+		return err
+	}
+	if len(rule.ByDay) > 0 {
+		series.RRule = rule.shiftDays(days).String()
+	}
+	return nil
+}
+
+// updateSingleOccurrence edits one lesson of a series, leaving the series untouched.
+func (s *lessonSeriesService) updateSingleOccurrence(series models.LessonSeries, lesson models.Lesson, actorID uuid.UUID, changes models.LessonSeriesChanges) (models.LessonSeriesResult, error) {
+	if changes.StartTime != nil || changes.DurationMinutes != nil {
+		start := lesson.StartTime
+		if changes.StartTime != nil {
+			start = *changes.StartTime
+		}
+		duration := lesson.EndTime.Sub(lesson.StartTime)
+		if changes.DurationMinutes != nil {
+			duration = time.Duration(*changes.DurationMinutes) * time.Minute
+		}
+		moved, err := s.lessonService.PostponeLesson(lesson.ID, actorID, start, start.Add(duration))
+		if err != nil {
+			return models.LessonSeriesResult{}, err
+		}
+		lesson = moved
+	}
+
+	if changes.Title != nil {
+		lesson.Title = *changes.Title
+	}
+	if changes.Description != nil {
+		lesson.Description = *changes.Description
+	}
+	if err := s.lessonRepo.UpdateLesson(&lesson); err != nil {
+		return models.LessonSeriesResult{}, err
+	}
+	return models.LessonSeriesResult{Series: series, Lessons: []models.Lesson{lesson}}, nil
+}
+
+// getSeriesOccurrence loads a series and one of its lessons.
+func (s *lessonSeriesService) getSeriesOccurrence(seriesID, lessonID uuid.UUID) (models.LessonSeries, models.Lesson, error) {
+	series, err := s.seriesRepo.GetSeriesByID(seriesID)
+	if err != nil {
+		return models.LessonSeries{}, models.Lesson{}, errors.New("lesson series not found")
+	}
+	if series.Status == models.LessonSeriesStatusCancelled {
+		return models.LessonSeries{}, models.Lesson{}, errors.New("lesson series is cancelled")
+	}
+	lesson, err := s.lessonRepo.GetLessonByID(lessonID)
+	if err != nil {
+		return models.LessonSeries{}, models.Lesson{}, errors.New("lesson not found")
+	}
+	if lesson.SeriesID == nil || *lesson.SeriesID != series.ID {
+		return models.LessonSeries{}, models.Lesson{}, errors.New("lesson does not belong to this series")
+	}
+	return series, lesson, nil
+}
+
+// canManageSeries reports whether the user may create or edit the series: its tutor or an admin.
+func canManageSeries(series models.LessonSeries, user models.User) bool {
+	return series.TutorID == user.ID || user.Role == models.UserRoleAdmin
+}
+
+// isSeriesParticipant reports whether userID is the series' tutor or one of its students.
+// The series must have its Students loaded.
+func isSeriesParticipant(series models.LessonSeries, userID uuid.UUID) bool {
+	if series.TutorID == userID {
+		return true
+	}
+	for _, student := range series.Students {
+		if student.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// occurrenceStart returns the series occurrence a lesson was materialized for.
+func occurrenceStart(lesson models.Lesson) time.Time {
+	if lesson.OccurrenceStart != nil {
+		return *lesson.OccurrenceStart
+	}
+	return lesson.StartTime
+}
+
+// isSchedulingConflict reports whether err is an expected booking rejection rather
+// than an infrastructure failure.
+func isSchedulingConflict(err error) bool {
+	return errors.Is(err, ErrLessonConflict) ||
+		errors.Is(err, ErrOutsideAvailability) ||
+		errors.Is(err, ErrInvalidLessonTime) ||
+		errors.Is(err, ErrUsersBlocked) ||
+		errors.Is(err, ErrSlotHeld) ||
+		errors.Is(err, ErrBookingRule) ||
+		errors.Is(err, ErrTrialNotEligible)
+}
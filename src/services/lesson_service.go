@@ -2,43 +2,97 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"vibely-backend/src/events"
 	"vibely-backend/src/models"
 	"vibely-backend/src/repositories"
 )
 
+// Scheduling errors
+var (
+	// ErrInvalidLessonTime is returned when a lesson's time range is malformed or in the past.
+	ErrInvalidLessonTime = errors.New("invalid lesson time")
+	// ErrOutsideAvailability is returned when a lesson does not fit into the tutor's availability.
+	ErrOutsideAvailability = errors.New("lesson is outside the tutor's availability")
+	// ErrLessonConflict is returned when the tutor or a student already has a lesson at that time.
+	ErrLessonConflict = errors.New("lesson conflicts with an existing lesson")
+	// ErrSlotHeld is returned when another student temporarily holds the slot during checkout.
+	ErrSlotHeld = errors.New("this time slot is temporarily held by another student")
+	// ErrTrialNotEligible is returned when booking a trial lesson with a tutor the student
+	// already had a lesson, trial or not, with.
+	ErrTrialNotEligible = errors.New("trial lessons are only for students new to this tutor")
+)
+
+// ErrStudentStatsForbidden is returned when someone other than the student, their tutors
+// or an admin asks for a student's statistics.
+var ErrStudentStatsForbidden = errors.New("you cannot view this student's statistics")
+
 type LessonService interface {
 	// Basic create & read
-	ScheduleLesson(lesson models.Lesson) (models.Lesson, error)
+	ScheduleLesson(lesson models.Lesson, actorID uuid.UUID) (models.Lesson, error)
 	GetLessonByID(lessonID uuid.UUID) (models.Lesson, error)
 	GetLessonWithParticipants(lessonID uuid.UUID) (models.Lesson, error)
 
-	// Status transitions
-	ConfirmLesson(lessonID uuid.UUID) (models.Lesson, error)
-	StartLesson(lessonID uuid.UUID) (models.Lesson, error)
-	CompleteLesson(lessonID uuid.UUID) (models.Lesson, error)
-	FailLesson(lessonID uuid.UUID) (models.Lesson, error)
-	CancelLesson(lessonID uuid.UUID) (models.Lesson, error)
-	PostponeLesson(lessonID uuid.UUID, newStart, newEnd time.Time) (models.Lesson, error)
+	// Status transitions, performed on behalf of actorID (uuid.Nil for the system)
+	ConfirmLesson(lessonID, actorID uuid.UUID) (models.Lesson, error)
+	StartLesson(lessonID, actorID uuid.UUID) (models.Lesson, error)
+	CompleteLesson(lessonID, actorID uuid.UUID) (models.Lesson, error)
+	FailLesson(lessonID, actorID uuid.UUID, reason string) (models.Lesson, error)
+	CancelLesson(lessonID, actorID uuid.UUID, reason, note string) (models.Lesson, error)
+	PostponeLesson(lessonID, actorID uuid.UUID, newStart, newEnd time.Time) (models.Lesson, error)
+	RescheduleLesson(lessonID, actorID uuid.UUID, newStart, newEnd time.Time) (models.Lesson, error)
+	GetStatusHistory(lessonID uuid.UUID, viewer models.User) ([]models.LessonStatusHistory, error)
 	GetLessonsForUser(userID uuid.UUID) ([]models.Lesson, error)
 	GetTutorsForUser(userID uuid.UUID) ([]models.User, error)
 	GetStudentsForTutor(tutorID uuid.UUID) ([]models.User, error)
-	EnrollStudent(lessonID uuid.UUID, student models.User) error
+	IsTrialEligible(tutorID, studentID uuid.UUID) (bool, error)
+	GetTutorStats(tutorID uuid.UUID) (models.TutorStats, error)
 	GetLessonsByTutorIDAndDateRange(tutorID uuid.UUID, startDate, endDate time.Time) ([]models.Lesson, error)
+	DisputeLesson(lessonID, userID uuid.UUID, reason string) (models.Lesson, error)
+
+	// Per-student attendance
+	RecordAttendance(lessonID, tutorID uuid.UUID, records []models.LessonStudent) (models.Lesson, error)
+	GetStudentStats(studentID uuid.UUID, viewer models.User) (models.StudentStats, error)
+
+	// Slot holds during checkout
+	HoldSlot(hold models.SlotHold) (models.SlotHold, error)
+	GetHeldSlots(tutorID uuid.UUID, start, end time.Time, viewerID uuid.UUID) ([]models.SlotHold, error)
+	ExpireSlotHolds() (int64, error)
+
+	// Time-driven transitions, run by the background scheduler
+	ExpireUnconfirmedLessons(deadline time.Duration) (int, error)
+	StartDueLessons() (int, error)
+	CompleteFinishedLessons() (int, error)
 }
 
 type lessonService struct {
-	repo repositories.LessonRepository
+	repo                repositories.LessonRepository
+	moderationRepo      repositories.ModerationRepository
+	policyRepo          repositories.CancellationPolicyRepository
+	rulesRepo           repositories.BookingRulesRepository
+	availabilityService TutorAvailabilityService
+	publisher           events.Publisher
 }
 
-func NewLessonService(repo repositories.LessonRepository) LessonService {
-	return &lessonService{repo: repo}
+func NewLessonService(repo repositories.LessonRepository, moderationRepo repositories.ModerationRepository, policyRepo repositories.CancellationPolicyRepository, rulesRepo repositories.BookingRulesRepository, availabilityService TutorAvailabilityService, publisher events.Publisher) LessonService {
+	return &lessonService{
+		repo:                repo,
+		moderationRepo:      moderationRepo,
+		policyRepo:          policyRepo,
+		rulesRepo:           rulesRepo,
+		availabilityService: availabilityService,
+		publisher:           publisher,
+	}
 }
 
-// ScheduleLesson: create a new Lesson with status "scheduled".
-func (s *lessonService) ScheduleLesson(lesson models.Lesson) (models.Lesson, error) {
+// ScheduleLesson: create a new Lesson with status "scheduled". Lessons booked by students
+// must also follow the tutor's booking rules.
+func (s *lessonService) ScheduleLesson(lesson models.Lesson, actorID uuid.UUID) (models.Lesson, error) {
 	// Basic validations
 	if lesson.TutorID == uuid.Nil {
 		return models.Lesson{}, errors.New("tutor_id is required")
@@ -46,11 +100,62 @@ func (s *lessonService) ScheduleLesson(lesson models.Lesson) (models.Lesson, err
 	if len(lesson.Students) == 0 {
 		return models.Lesson{}, errors.New("at least one student is required")
 	}
+	if err := validateCapacity(lesson.MaxStudents, len(lesson.Students)); err != nil {
+		return models.Lesson{}, err
+	}
+	if lesson.IsTrial && len(lesson.Students) != 1 {
+		return models.Lesson{}, errors.New("a trial lesson has exactly one student")
+	}
+	for _, student := range lesson.Students {
+		blocked, err := s.moderationRepo.IsBlocked(lesson.TutorID, student.ID)
+		if err != nil {
+			return models.Lesson{}, err
+		}
+		if blocked {
+			return models.Lesson{}, ErrUsersBlocked
+		}
+	}
 
-	lesson.Status = models.LessonStatusScheduled
-	if err := s.repo.CreateLesson(&lesson); err != nil {
+	if err := s.validateLessonSlot(lesson.TutorID, lesson.StartTime, lesson.EndTime); err != nil {
+		return models.Lesson{}, err
+	}
+	rules, err := getBookingRules(s.rulesRepo, lesson.TutorID)
+	if err != nil {
 		return models.Lesson{}, err
 	}
+
+	lesson.Status = models.LessonStatusScheduled
+	err = s.repo.Transaction(func(tx repositories.LessonRepository) error {
+		if err := reserveLessonSlot(tx, lesson, lesson.StartTime, lesson.EndTime, uuid.Nil); err != nil {
+			return err
+		}
+		if lesson.IsTrial {
+			// Checked under the participants' locks so concurrent trial bookings can't both pass.
+			hadLessons, err := tx.HasLessonsWithTutor(lesson.TutorID, lesson.Students[0].ID)
+			if err != nil {
+				return err
+			}
+			if hadLessons {
+				return ErrTrialNotEligible
+			}
+		}
+		// The tutor and the system (e.g. extending a series) are not bound by the rules.
+		if actorID != lesson.TutorID && actorID != uuid.Nil {
+			studentIDs := lessonParticipantIDs(lesson)[1:]
+			if err := enforceBookingRules(tx, rules, studentIDs, lesson.StartTime, lesson.EndTime, uuid.Nil, time.Now()); err != nil {
+				return err
+			}
+		}
+		if err := tx.CreateLesson(&lesson); err != nil {
+			return err
+		}
+		_, err := recordLessonTransition(tx, lesson.ID, "", lesson.Status, actorID, "")
+		return err
+	})
+	if err != nil {
+		return models.Lesson{}, translateLessonConflict(err)
+	}
+	s.publishLessonChange(events.LessonCreated, lesson.Status, lesson, actorID, true)
 	return lesson, nil
 }
 
@@ -65,103 +170,379 @@ func (s *lessonService) GetLessonWithParticipants(lessonID uuid.UUID) (models.Le
 }
 
 // ConfirmLesson: sets the Lesson status to "confirmed".
-func (s *lessonService) ConfirmLesson(lessonID uuid.UUID) (models.Lesson, error) {
-	lesson, err := s.repo.GetLessonByID(lessonID)
+func (s *lessonService) ConfirmLesson(lessonID, actorID uuid.UUID) (models.Lesson, error) {
+	return s.transitionLesson(lessonID, models.LessonStatusConfirmed, actorID, "")
+}
+
+// StartLesson: sets the Lesson status to "in_progress".
+func (s *lessonService) StartLesson(lessonID, actorID uuid.UUID) (models.Lesson, error) {
+	return s.transitionLesson(lessonID, models.LessonStatusInProgress, actorID, "")
+}
+
+// CompleteLesson: sets the Lesson status to "done".
+func (s *lessonService) CompleteLesson(lessonID, actorID uuid.UUID) (models.Lesson, error) {
+	return s.transitionLesson(lessonID, models.LessonStatusDone, actorID, "")
+}
+
+// FailLesson: sets the Lesson status to "failed".
+func (s *lessonService) FailLesson(lessonID, actorID uuid.UUID, reason string) (models.Lesson, error) {
+	return s.transitionLesson(lessonID, models.LessonStatusFailed, actorID, reason)
+}
+
+// CancelLesson: sets the Lesson status to "cancelled", recording who cancelled and why.
+// Only the lesson's participants, or the system (uuid.Nil), may cancel it. A student
+// cancelling after the tutor's free-cancellation window is charged the late cancellation
+// fee; cancellations by the tutor or by the system are free.
+func (s *lessonService) CancelLesson(lessonID, actorID uuid.UUID, reason, note string) (models.Lesson, error) {
+	if err := validateCancellation(reason, note); err != nil {
+		return models.Lesson{}, err
+	}
+
+	lesson, err := s.repo.GetLessonWithParticipants(lessonID)
 	if err != nil {
 		return models.Lesson{}, err
 	}
-	if lesson.Status != models.LessonStatusScheduled {
-		return models.Lesson{}, errors.New("only scheduled lessons can be confirmed")
+	if actorID != uuid.Nil && !isLessonParticipant(lesson, actorID) {
+		return models.Lesson{}, ErrNotLessonParticipant
 	}
-	lesson.Status = models.LessonStatusConfirmed
-	if err := s.repo.UpdateLesson(&lesson); err != nil {
+	if err := checkLessonTransition(lesson.Status, models.LessonStatusCancelled); err != nil {
+		return models.Lesson{}, err
+	}
+
+	now := time.Now()
+	fee := 0.0
+	if actorID != lesson.TutorID && isLessonParticipant(lesson, actorID) {
+		if fee, err = lateCancellationFee(s.policyRepo, lesson, now); err != nil {
+			return models.Lesson{}, err
+		}
+	}
+
+	previousStatus := lesson.Status
+	lesson.Status = models.LessonStatusCancelled
+	if actorID != uuid.Nil {
+		lesson.CancelledByID = &actorID
+	}
+	lesson.CancelledAt = &now
+	lesson.CancellationReason = reason
+	lesson.CancellationNote = note
+	lesson.CancellationFee = fee
+
+	err = s.repo.Transaction(func(tx repositories.LessonRepository) error {
+		moved, err := recordLessonTransition(tx, lesson.ID, previousStatus, lesson.Status, actorID, reason)
+		if err != nil {
+			return err
+		}
+		if !moved {
+			return fmt.Errorf("%w: the lesson was changed concurrently", ErrInvalidLessonTransition)
+		}
+		return tx.UpdateLesson(&lesson)
+	})
+	if err != nil {
 		return models.Lesson{}, err
 	}
+	s.publishLessonChange(events.LessonUpdated, lesson.Status, lesson, actorID, true)
 	return lesson, nil
 }
 
-// StartLesson: sets the Lesson status to "in_progress".
-func (s *lessonService) StartLesson(lessonID uuid.UUID) (models.Lesson, error) {
-	lesson, err := s.repo.GetLessonByID(lessonID)
+// GetStatusHistory returns the recorded status transitions of a lesson, oldest first, to
+// one of its participants or an admin.
+func (s *lessonService) GetStatusHistory(lessonID uuid.UUID, viewer models.User) ([]models.LessonStatusHistory, error) {
+	lesson, err := s.repo.GetLessonWithParticipants(lessonID)
+	if err != nil {
+		return nil, err
+	}
+	if viewer.Role != models.UserRoleAdmin && !isLessonParticipant(lesson, viewer.ID) {
+		return nil, ErrNotLessonParticipant
+	}
+	return s.repo.GetStatusHistory(lessonID)
+}
+
+// transitionLesson moves a lesson to status as allowed by the state machine and records
+// the transition in its history. The actor must be allowed to make the transition.
+func (s *lessonService) transitionLesson(lessonID uuid.UUID, status string, actorID uuid.UUID, reason string) (models.Lesson, error) {
+	lesson, err := s.repo.GetLessonWithParticipants(lessonID)
 	if err != nil {
 		return models.Lesson{}, err
 	}
-	if lesson.Status != models.LessonStatusConfirmed {
-		return models.Lesson{}, errors.New("lesson must be confirmed before starting")
+	if err := checkLessonActor(lesson, status, actorID); err != nil {
+		return models.Lesson{}, err
 	}
-	lesson.Status = models.LessonStatusInProgress
-	if err := s.repo.UpdateLesson(&lesson); err != nil {
+	if lesson.Status == status {
+		return models.Lesson{}, fmt.Errorf("%w: the lesson is already %s", ErrInvalidLessonTransition, status)
+	}
+	err = s.repo.Transaction(func(tx repositories.LessonRepository) error {
+		moved, err := recordLessonTransition(tx, lesson.ID, lesson.Status, status, actorID, reason)
+		if err == nil && !moved {
+			return fmt.Errorf("%w: the lesson was changed concurrently", ErrInvalidLessonTransition)
+		}
+		return err
+	})
+	if err != nil {
 		return models.Lesson{}, err
 	}
+	lesson.Status = status
+	s.publishLessonChange(events.LessonUpdated, status, lesson, actorID, slotReleased(status))
 	return lesson, nil
 }
 
-// CompleteLesson: sets the Lesson status to "done".
-func (s *lessonService) CompleteLesson(lessonID uuid.UUID) (models.Lesson, error) {
-	lesson, err := s.repo.GetLessonByID(lessonID)
+// PostponeLesson: updates times, sets status back to "scheduled" so it is confirmed again.
+func (s *lessonService) PostponeLesson(lessonID, actorID uuid.UUID, newStart, newEnd time.Time) (models.Lesson, error) {
+	lesson, err := s.repo.GetLessonWithParticipants(lessonID)
 	if err != nil {
 		return models.Lesson{}, err
 	}
-	if lesson.Status != models.LessonStatusInProgress {
-		return models.Lesson{}, errors.New("lesson must be in progress to be completed")
+	reason := "postponed to " + newStart.Format(time.RFC3339)
+	return s.moveLesson(lesson, actorID, newStart, newEnd, models.LessonStatusScheduled, events.LessonActionPostponed, reason)
+}
+
+// RescheduleLesson moves a lesson to a time its participants agreed on. Unlike
+// PostponeLesson, a confirmed lesson stays confirmed.
+func (s *lessonService) RescheduleLesson(lessonID, actorID uuid.UUID, newStart, newEnd time.Time) (models.Lesson, error) {
+	lesson, err := s.repo.GetLessonWithParticipants(lessonID)
+	if err != nil {
+		return models.Lesson{}, err
 	}
-	lesson.Status = models.LessonStatusDone
-	if err := s.repo.UpdateLesson(&lesson); err != nil {
+	status := models.LessonStatusScheduled
+	if lesson.Status == models.LessonStatusConfirmed {
+		status = models.LessonStatusConfirmed
+	}
+	reason := "rescheduled to " + newStart.Format(time.RFC3339)
+	return s.moveLesson(lesson, actorID, newStart, newEnd, status, events.LessonActionRescheduled, reason)
+}
+
+// moveLesson moves a lesson to a new time slot and to status, recording a single
+// transition in its history and publishing a single change with action.
+func (s *lessonService) moveLesson(lesson models.Lesson, actorID uuid.UUID, newStart, newEnd time.Time, status, action, reason string) (models.Lesson, error) {
+	if err := checkLessonTransition(lesson.Status, status); err != nil {
 		return models.Lesson{}, err
 	}
+	if err := s.validateLessonSlot(lesson.TutorID, newStart, newEnd); err != nil {
+		return models.Lesson{}, err
+	}
+
+	previousStatus := lesson.Status
+	lesson.StartTime = newStart
+	lesson.EndTime = newEnd
+	lesson.Status = status
+
+	err := s.repo.Transaction(func(tx repositories.LessonRepository) error {
+		if err := reserveLessonSlot(tx, lesson, newStart, newEnd, lesson.ID); err != nil {
+			return err
+		}
+		moved, err := recordLessonTransition(tx, lesson.ID, previousStatus, lesson.Status, actorID, reason)
+		if err != nil {
+			return err
+		}
+		if !moved {
+			return fmt.Errorf("%w: the lesson was changed concurrently", ErrInvalidLessonTransition)
+		}
+		return tx.UpdateLesson(&lesson)
+	})
+	if err != nil {
+		return models.Lesson{}, translateLessonConflict(err)
+	}
+	s.publishLessonChange(events.LessonUpdated, action, lesson, actorID, true)
 	return lesson, nil
 }
 
-// FailLesson: sets the Lesson status to "failed".
-func (s *lessonService) FailLesson(lessonID uuid.UUID) (models.Lesson, error) {
-	lesson, err := s.repo.GetLessonByID(lessonID)
+// DisputeLesson flags a lesson as disputed by one of its participants, which keeps it
+// from being completed automatically once it ends.
+func (s *lessonService) DisputeLesson(lessonID, userID uuid.UUID, reason string) (models.Lesson, error) {
+	lesson, err := s.repo.GetLessonWithParticipants(lessonID)
 	if err != nil {
 		return models.Lesson{}, err
 	}
-	if lesson.Status != models.LessonStatusInProgress &&
-		lesson.Status != models.LessonStatusConfirmed {
-		return models.Lesson{}, errors.New("cannot fail a lesson from the current status")
+	if !isLessonParticipant(lesson, userID) {
+		return models.Lesson{}, errors.New("only lesson participants can dispute a lesson")
 	}
-	lesson.Status = models.LessonStatusFailed
+	if lesson.Status != models.LessonStatusConfirmed &&
+		lesson.Status != models.LessonStatusInProgress {
+		return models.Lesson{}, errors.New("only confirmed or in-progress lessons can be disputed")
+	}
+	if lesson.DisputedAt != nil {
+		return models.Lesson{}, errors.New("lesson is already disputed")
+	}
+
+	now := time.Now()
+	lesson.DisputedAt = &now
+	lesson.DisputeReason = reason
 	if err := s.repo.UpdateLesson(&lesson); err != nil {
 		return models.Lesson{}, err
 	}
+	s.publishLessonChange(events.LessonUpdated, events.LessonActionDisputed, lesson, userID, false)
 	return lesson, nil
 }
 
-// CancelLesson: sets the Lesson status to "cancelled".
-func (s *lessonService) CancelLesson(lessonID uuid.UUID) (models.Lesson, error) {
-	lesson, err := s.repo.GetLessonByID(lessonID)
+// RecordAttendance records, on behalf of the lesson's tutor, whether each given student
+// attended a lesson that has started. Students not in records keep their attendance.
+func (s *lessonService) RecordAttendance(lessonID, tutorID uuid.UUID, records []models.LessonStudent) (models.Lesson, error) {
+	lesson, err := s.repo.GetLessonWithParticipants(lessonID)
 	if err != nil {
 		return models.Lesson{}, err
 	}
-	lesson.Status = models.LessonStatusCancelled
-	if err := s.repo.UpdateLesson(&lesson); err != nil {
+	if lesson.TutorID != tutorID {
+		return models.Lesson{}, errors.New("only the tutor can record attendance")
+	}
+	if lesson.Status != models.LessonStatusInProgress && lesson.Status != models.LessonStatusDone {
+		return models.Lesson{}, errors.New("attendance can only be recorded for lessons in progress or done")
+	}
+	if len(records) == 0 {
+		return models.Lesson{}, errors.New("no attendance given")
+	}
+
+	now := time.Now()
+	for i, record := range records {
+		if !models.IsValidAttendance(record.Attendance) {
+			return models.Lesson{}, fmt.Errorf("invalid attendance %q", record.Attendance)
+		}
+		if !isLessonParticipant(lesson, record.UserID) || record.UserID == lesson.TutorID {
+			return models.Lesson{}, fmt.Errorf("student %s does not attend this lesson", record.UserID)
+		}
+		records[i].LessonID = lessonID
+		records[i].AttendanceRecordedAt = &now
+	}
+
+	if err := s.repo.UpdateAttendance(lessonID, records); err != nil {
 		return models.Lesson{}, err
 	}
-	return lesson, nil
+	s.publishLessonChange(events.LessonUpdated, events.LessonActionAttendanceRecorded, lesson, tutorID, false)
+	return s.repo.GetLessonWithParticipants(lessonID)
 }
 
-// PostponeLesson: updates times, sets status back to "scheduled" (or custom flow).
-func (s *lessonService) PostponeLesson(lessonID uuid.UUID, newStart, newEnd time.Time) (models.Lesson, error) {
-	lesson, err := s.repo.GetLessonByID(lessonID)
+// GetStudentStats summarizes the attendance of a student over the lessons that took place.
+// The student and admins see all of them; a tutor of the student only their own lessons.
+func (s *lessonService) GetStudentStats(studentID uuid.UUID, viewer models.User) (models.StudentStats, error) {
+	tutorID := uuid.Nil
+	if viewer.ID != studentID && viewer.Role != models.UserRoleAdmin {
+		if viewer.Role != models.UserRoleTutor {
+			return models.StudentStats{}, ErrStudentStatsForbidden
+		}
+		teaches, err := s.repo.HasLessonsWithTutor(viewer.ID, studentID)
+		if err != nil {
+			return models.StudentStats{}, err
+		}
+		if !teaches {
+			return models.StudentStats{}, ErrStudentStatsForbidden
+		}
+		tutorID = viewer.ID
+	}
+
+	counts, err := s.repo.GetStudentAttendanceCounts(studentID, tutorID)
 	if err != nil {
-		return models.Lesson{}, err
+		return models.StudentStats{}, err
 	}
-	if lesson.Status != models.LessonStatusScheduled &&
-		lesson.Status != models.LessonStatusConfirmed {
-		return models.Lesson{}, errors.New("lesson can only be postponed if it's scheduled or confirmed")
+
+	stats := models.StudentStats{StudentID: studentID}
+	for _, count := range counts {
+		stats.Lessons += count.Lessons
+		stats.Attendance.Add(count.Attendance, count.Lessons)
 	}
+	stats.AttendanceRate = stats.Attendance.Rate()
+	return stats, nil
+}
 
-	lesson.StartTime = newStart
-	lesson.EndTime = newEnd
-	lesson.Status = models.LessonStatusScheduled // or "pending_confirmation"
+// ExpireUnconfirmedLessons expires scheduled lessons that were not confirmed by the
+// time they are within deadline of their start.
+func (s *lessonService) ExpireUnconfirmedLessons(deadline time.Duration) (int, error) {
+	lessons, err := s.repo.GetLessonsStartingBefore(models.LessonStatusScheduled, time.Now().Add(deadline))
+	if err != nil {
+		return 0, err
+	}
+	return s.transitionAll(lessons, models.LessonStatusScheduled, models.LessonStatusExpired, "not confirmed in time")
+}
 
-	if err := s.repo.UpdateLesson(&lesson); err != nil {
-		return models.Lesson{}, err
+// StartDueLessons moves confirmed lessons whose StartTime has passed to "in_progress".
+func (s *lessonService) StartDueLessons() (int, error) {
+	lessons, err := s.repo.GetLessonsStartingBefore(models.LessonStatusConfirmed, time.Now())
+	if err != nil {
+		return 0, err
 	}
-	return lesson, nil
+	return s.transitionAll(lessons, models.LessonStatusConfirmed, models.LessonStatusInProgress, "start time reached")
+}
+
+// CompleteFinishedLessons moves undisputed in-progress lessons whose EndTime has passed to "done".
+func (s *lessonService) CompleteFinishedLessons() (int, error) {
+	lessons, err := s.repo.GetUndisputedLessonsEndingBefore(models.LessonStatusInProgress, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return s.transitionAll(lessons, models.LessonStatusInProgress, models.LessonStatusDone, "end time reached")
 }
+
+// transitionAll applies a system status change to each lesson and returns how many
+// were actually moved. Lessons changed concurrently by someone else are skipped.
+func (s *lessonService) transitionAll(lessons []models.Lesson, fromStatus, toStatus, reason string) (int, error) {
+	moved := 0
+	for _, lesson := range lessons {
+		var ok bool
+		err := s.repo.Transaction(func(tx repositories.LessonRepository) error {
+			var err error
+			ok, err = recordLessonTransition(tx, lesson.ID, fromStatus, toStatus, uuid.Nil, reason)
+			return err
+		})
+		if err != nil {
+			return moved, err
+		}
+		if ok {
+			moved++
+			lesson.Status = toStatus
+			s.publishLessonChange(events.LessonUpdated, toStatus, lesson, uuid.Nil, slotReleased(toStatus))
+		}
+	}
+	return moved, nil
+}
+
+// HoldSlot reserves a tutor's slot for a student during checkout. The slot must be free
+// and inside the tutor's availability, exactly as for booking a lesson.
+func (s *lessonService) HoldSlot(hold models.SlotHold) (models.SlotHold, error) {
+	blocked, err := s.moderationRepo.IsBlocked(hold.TutorID, hold.StudentID)
+	if err != nil {
+		return models.SlotHold{}, err
+	}
+	if blocked {
+		return models.SlotHold{}, ErrUsersBlocked
+	}
+	if err := s.validateLessonSlot(hold.TutorID, hold.StartTime, hold.EndTime); err != nil {
+		return models.SlotHold{}, err
+	}
+	rules, err := getBookingRules(s.rulesRepo, hold.TutorID)
+	if err != nil {
+		return models.SlotHold{}, err
+	}
+
+	hold.Status = models.SlotHoldStatusActive
+	draft := models.Lesson{TutorID: hold.TutorID, Students: []models.User{{ID: hold.StudentID}}}
+	err = s.repo.Transaction(func(tx repositories.LessonRepository) error {
+		if err := reserveLessonSlot(tx, draft, hold.StartTime, hold.EndTime, uuid.Nil); err != nil {
+			return err
+		}
+		if err := enforceBookingRules(tx, rules, []uuid.UUID{hold.StudentID}, hold.StartTime, hold.EndTime, uuid.Nil, time.Now()); err != nil {
+			return err
+		}
+		return tx.CreateSlotHold(&hold)
+	})
+	if err != nil {
+		return models.SlotHold{}, err
+	}
+	publishAvailabilityChange(s.publisher, hold.TutorID)
+	return hold, nil
+}
+
+// GetHeldSlots returns the tutor's active holds in [start, end) that hide slots from
+// viewerID, i.e. all holds except the viewer's own.
+func (s *lessonService) GetHeldSlots(tutorID uuid.UUID, start, end time.Time, viewerID uuid.UUID) ([]models.SlotHold, error) {
+	var exclude []uuid.UUID
+	if viewerID != uuid.Nil {
+		exclude = append(exclude, viewerID)
+	}
+	return s.repo.GetActiveSlotHolds(tutorID, start, end, exclude)
+}
+
+// ExpireSlotHolds expires holds whose checkout window has passed.
+func (s *lessonService) ExpireSlotHolds() (int64, error) {
+	return s.repo.ExpireSlotHolds(time.Now())
+}
+
 func (s *lessonService) GetLessonsForUser(userID uuid.UUID) ([]models.Lesson, error) {
 	return s.repo.GetLessonsForUser(userID)
 }
@@ -169,9 +550,6 @@ func (s *lessonService) GetLessonsForUser(userID uuid.UUID) ([]models.Lesson, er
 func (s *lessonService) GetTutorsForUser(userID uuid.UUID) ([]models.User, error) {
 	return s.repo.GetTutorsForUser(userID)
 }
-func (s *lessonService) EnrollStudent(lessonID uuid.UUID, student models.User) error {
-	return s.repo.EnrollStudent(lessonID, student)
-}
 func (s *lessonService) GetLessonsByTutorIDAndDateRange(tutorID uuid.UUID, startDate, endDate time.Time) ([]models.Lesson, error) {
 	return s.repo.GetLessonsByTutorIDAndDateRange(tutorID, startDate, endDate)
 }
@@ -180,3 +558,156 @@ func (s *lessonService) GetLessonsByTutorIDAndDateRange(tutorID uuid.UUID, start
 func (s *lessonService) GetStudentsForTutor(tutorID uuid.UUID) ([]models.User, error) {
 	return s.repo.GetStudentsForTutor(tutorID)
 }
+
+// IsTrialEligible reports whether the student may book a trial lesson with the tutor: only
+// students without a booked or past lesson with the tutor may. ScheduleLesson checks the
+// same when booking.
+func (s *lessonService) IsTrialEligible(tutorID, studentID uuid.UUID) (bool, error) {
+	hadLessons, err := s.repo.HasLessonsWithTutor(tutorID, studentID)
+	if err != nil {
+		return false, err
+	}
+	return !hadLessons, nil
+}
+
+// GetTutorStats summarizes the tutor's lessons, including the conversion of trial lessons.
+func (s *lessonService) GetTutorStats(tutorID uuid.UUID) (models.TutorStats, error) {
+	stats, err := s.repo.GetTutorStats(tutorID)
+	if err != nil {
+		return models.TutorStats{}, err
+	}
+	if stats.TrialStudents > 0 {
+		stats.TrialConversionRate = float64(stats.ConvertedStudents) / float64(stats.TrialStudents)
+	}
+	return stats, nil
+}
+
+// validateLessonSlot checks that [start, end) is a well-formed future range that fits
+// entirely inside one of the tutor's availability slots for that day.
+func (s *lessonService) validateLessonSlot(tutorID uuid.UUID, start, end time.Time) error {
+	if start.IsZero() || end.IsZero() {
+		return fmt.Errorf("%w: start_time and end_time are required", ErrInvalidLessonTime)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("%w: end_time must be after start_time", ErrInvalidLessonTime)
+	}
+	if start.Before(time.Now()) {
+		return fmt.Errorf("%w: lessons cannot be scheduled in the past", ErrInvalidLessonTime)
+	}
+
+	// Availability is computed per calendar day, the same way the availability endpoint does it.
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	slots, err := s.availabilityService.GetAvailabilityForDateRange(tutorID, day, day)
+	if err != nil {
+		return err
+	}
+	for _, slot := range slots {
+		slotStart, slotEnd, err := slotTimeRange(slot, start.Location())
+		if err != nil {
+			continue
+		}
+		if !start.Before(slotStart) && !end.After(slotEnd) {
+			return nil
+		}
+	}
+	return ErrOutsideAvailability
+}
+
+// reserveLessonSlot locks the lesson's participants and makes sure none of them has another
+// lesson in [start, end) and that no other student holds the tutor's slot. It must run
+// inside a LessonRepository transaction.
+func reserveLessonSlot(tx repositories.LessonRepository, lesson models.Lesson, start, end time.Time, excludeLessonID uuid.UUID) error {
+	participantIDs := lessonParticipantIDs(lesson)
+	if err := tx.LockParticipants(participantIDs); err != nil {
+		return err
+	}
+	overlapping, err := tx.GetOverlappingLessons(participantIDs, start, end, excludeLessonID)
+	if err != nil {
+		return err
+	}
+	if len(overlapping) > 0 {
+		return lessonConflictError(overlapping[0])
+	}
+
+	holds, err := tx.GetActiveSlotHolds(lesson.TutorID, start, end, participantIDs[1:])
+	if err != nil {
+		return err
+	}
+	if len(holds) > 0 {
+		return ErrSlotHeld
+	}
+	return nil
+}
+
+// lessonConflictError is the ErrLessonConflict returned when other takes the requested time.
+func lessonConflictError(other models.Lesson) error {
+	return fmt.Errorf("%w (%s - %s)", ErrLessonConflict,
+		other.StartTime.Format("2006-01-02 15:04"), other.EndTime.Format("15:04"))
+}
+
+// isLessonParticipant reports whether userID is the lesson's tutor or one of its students.
+// The lesson must have its Students loaded.
+func isLessonParticipant(lesson models.Lesson, userID uuid.UUID) bool {
+	for _, id := range lessonParticipantIDs(lesson) {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// lessonParticipantIDs returns the tutor and student IDs of a lesson.
+func lessonParticipantIDs(lesson models.Lesson) []uuid.UUID {
+	ids := []uuid.UUID{lesson.TutorID}
+	for _, student := range lesson.Students {
+		ids = append(ids, student.ID)
+	}
+	return ids
+}
+
+// publishLessonChange notifies the lesson's participants that actorID (uuid.Nil for the
+// system) did action to it and, when this booked or freed a slot, those following the
+// tutor's calendar.
+func (s *lessonService) publishLessonChange(eventType, action string, lesson models.Lesson, actorID uuid.UUID, availabilityChanged bool) {
+	if len(lesson.Students) == 0 {
+		withParticipants, err := s.repo.GetLessonWithParticipants(lesson.ID)
+		if err != nil {
+			log.Printf("Failed to load participants of lesson %s for %s: %v", lesson.ID, eventType, err)
+			return
+		}
+		lesson.Students = withParticipants.Students
+	}
+	change := events.LessonChange{
+		LessonID:  lesson.ID,
+		TutorID:   lesson.TutorID,
+		CourseID:  lesson.CourseID,
+		Title:     lesson.Title,
+		Status:    lesson.Status,
+		StartTime: lesson.StartTime,
+		EndTime:   lesson.EndTime,
+		Action:    action,
+	}
+	if actorID != uuid.Nil {
+		change.ActorID = &actorID
+	}
+	s.publisher.Publish(eventType, change, events.UserTopics(lessonParticipantIDs(lesson)...)...)
+	if availabilityChanged {
+		publishAvailabilityChange(s.publisher, lesson.TutorID)
+	}
+}
+
+// slotReleased reports whether a lesson moving to status frees its slot in the tutor's calendar.
+func slotReleased(status string) bool {
+	return status == models.LessonStatusCancelled ||
+		status == models.LessonStatusExpired ||
+		status == models.LessonStatusFailed
+}
+
+// translateLessonConflict maps a violation of the lessons exclusion constraint to ErrLessonConflict.
+func translateLessonConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23P01" {
+		return ErrLessonConflict
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// Lesson state machine errors
+var (
+	// ErrInvalidLessonTransition is returned when a lesson cannot move from its current
+	// status to the requested one.
+	ErrInvalidLessonTransition = errors.New("invalid lesson status transition")
+	// ErrLessonTransitionForbidden is returned when the user asking for a transition is not
+	// allowed to make it.
+	ErrLessonTransitionForbidden = errors.New("not allowed to change this lesson's status")
+)
+
+// lessonTransitions is the lesson state machine: for each status, the statuses a lesson
+// may move to next. The empty status stands for a lesson being created. Statuses without
+// an entry (done, failed, cancelled, expired) are final.
+var lessonTransitions = map[string][]string{
+	"": {models.LessonStatusScheduled},
+	models.LessonStatusScheduled: {
+		models.LessonStatusScheduled, // postponed
+		models.LessonStatusConfirmed,
+		models.LessonStatusCancelled,
+		models.LessonStatusExpired,
+	},
+	models.LessonStatusConfirmed: {
+		models.LessonStatusScheduled, // postponed, needs confirming again
+		models.LessonStatusConfirmed, // rescheduled by agreement of its participants
+		models.LessonStatusInProgress,
+		models.LessonStatusFailed,
+		models.LessonStatusCancelled,
+	},
+	models.LessonStatusInProgress: {
+		models.LessonStatusDone,
+		models.LessonStatusFailed,
+	},
+}
+
+// lessonTransition is a move of a lesson from one status to another.
+type lessonTransition struct {
+	from, to string
+}
+
+// tutorOnlyTransitions are the transitions only the lesson's tutor may make. Any
+// participant may make the others.
+var tutorOnlyTransitions = map[lessonTransition]bool{
+	{models.LessonStatusScheduled, models.LessonStatusConfirmed}: true,
+	{models.LessonStatusInProgress, models.LessonStatusDone}:     true,
+}
+
+// checkLessonActor returns ErrLessonTransitionForbidden if actorID may not move the
+// lesson to status: outsiders may make no transition and students may not make the
+// tutor-only ones. The system (uuid.Nil) may make any transition. The lesson must have
+// its Students loaded.
+func checkLessonActor(lesson models.Lesson, to string, actorID uuid.UUID) error {
+	if actorID == uuid.Nil || actorID == lesson.TutorID {
+		return nil
+	}
+	if !isLessonParticipant(lesson, actorID) {
+		return fmt.Errorf("%w: only lesson participants can change its status", ErrLessonTransitionForbidden)
+	}
+	if tutorOnlyTransitions[lessonTransition{lesson.Status, to}] {
+		return fmt.Errorf("%w: only the tutor can move a %s lesson to %s", ErrLessonTransitionForbidden, lesson.Status, to)
+	}
+	return nil
+}
+
+// checkLessonTransition returns ErrInvalidLessonTransition if from -> to is not allowed.
+func checkLessonTransition(from, to string) error {
+	for _, allowed := range lessonTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	if from == "" {
+		from = "new"
+	}
+	return fmt.Errorf("%w: cannot move a %s lesson to %s", ErrInvalidLessonTransition, from, to)
+}
+
+// recordLessonTransition moves the lesson from one status to another, but only if it is
+// still in from, and records the change in its history. For a new lesson (from == "")
+// only the history entry is written. It reports whether the lesson was moved and must
+// run inside a LessonRepository transaction.
+func recordLessonTransition(tx repositories.LessonRepository, lessonID uuid.UUID, from, to string, actorID uuid.UUID, reason string) (bool, error) {
+	if err := checkLessonTransition(from, to); err != nil {
+		return false, err
+	}
+	if from != "" && from != to {
+		moved, err := tx.UpdateLessonStatusIf(lessonID, from, to)
+		if err != nil || !moved {
+			return false, err
+		}
+	}
+	return true, tx.CreateStatusHistory(newLessonStatusHistory(lessonID, from, to, actorID, reason))
+}
+
+// newLessonStatusHistory builds a history entry. A nil actorID marks a system transition.
+func newLessonStatusHistory(lessonID uuid.UUID, from, to string, actorID uuid.UUID, reason string) *models.LessonStatusHistory {
+	entry := &models.LessonStatusHistory{
+		LessonID:   lessonID,
+		FromStatus: from,
+		ToStatus:   to,
+		Reason:     reason,
+	}
+	if actorID != uuid.Nil {
+		entry.ActorID = &actorID
+	}
+	return entry
+}
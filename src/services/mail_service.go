@@ -0,0 +1,258 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vibely-backend/src/mail"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// SendEmailJob is the kind of the queued jobs sending outbox emails.
+const SendEmailJob = "send-email"
+
+// mailMaxAttempts is how many times an email is attempted before it fails. It must not
+// exceed the job queue's own limit.
+const mailMaxAttempts = 5
+
+// ErrInvalidUnsubscribeToken is returned for unsubscribe tokens that belong to no user.
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// sendEmailJob is the payload of a SendEmailJob.
+type sendEmailJob struct {
+	EmailID uuid.UUID `json:"email_id"`
+}
+
+// MailService emails users. Emails are rendered when queued and kept in the outbox, from
+// which queued jobs send them through the mail transport. Addresses that bounced are
+// suppressed, and users can turn off email notifications through the unsubscribe link
+// in every notification email.
+type MailService interface {
+	EmailSender
+
+	// SendEmail runs a SendEmailJob.
+	SendEmail(payload []byte) error
+	// RecordBounce records a bounce or spam complaint reported by the mail provider.
+	RecordBounce(address, kind, reason string) error
+	// Unsubscribe turns off the email notifications of the token's user.
+	Unsubscribe(token string) error
+	// PurgeOutbox deletes the sent and failed emails older than maxAge, run by the scheduler.
+	PurgeOutbox(maxAge time.Duration) (int64, error)
+}
+
+type mailService struct {
+	repo             repositories.MailRepository
+	userRepo         repositories.UserRepository
+	notificationRepo repositories.NotificationRepository
+	jobRepo          repositories.JobRepository
+	templates        *mail.Templates
+	transport        mail.Transport
+	defaultLanguage  string
+	frontendURL      string
+	backendURL       string
+}
+
+// NewMailService creates a new instance of MailService. Users who didn't choose a
+// language get emails in defaultLanguage. Unsubscribe links point at the frontend's
+// /unsubscribe page, and the List-Unsubscribe header at the backend.
+func NewMailService(repo repositories.MailRepository, userRepo repositories.UserRepository, notificationRepo repositories.NotificationRepository, jobRepo repositories.JobRepository, templates *mail.Templates, transport mail.Transport, defaultLanguage, frontendURL, backendURL string) MailService {
+	return &mailService{
+		repo:             repo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		jobRepo:          jobRepo,
+		templates:        templates,
+		transport:        transport,
+		defaultLanguage:  defaultLanguage,
+		frontendURL:      frontendURL,
+		backendURL:       backendURL,
+	}
+}
+
+// EmailUser renders the email in the user's language, stores it in the outbox and queues
+// it to be sent.
+func (s *mailService) EmailUser(userID uuid.UUID, template string, content interface{}, optOut bool) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	language := s.defaultLanguage
+	preferences, err := s.notificationRepo.GetPreferences(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if mail.IsLanguage(preferences.Language) {
+		language = preferences.Language
+	}
+
+	data := mail.TemplateData{Content: content, AppURL: s.frontendURL}
+	var unsubscribeURL string
+	if optOut {
+		token, err := s.unsubscribeToken(userID)
+		if err != nil {
+			return err
+		}
+		query := "?token=" + url.QueryEscape(token)
+		data.UnsubscribeURL = s.frontendURL + "/unsubscribe" + query
+		unsubscribeURL = s.backendURL + "/api/mail/unsubscribe" + query
+	}
+	subject, text, html, err := s.templates.Render(template, language, data)
+	if err != nil {
+		return err
+	}
+
+	email := models.OutboxEmail{
+		UserID:         &userID,
+		To:             user.Email,
+		Template:       template,
+		Language:       language,
+		Subject:        subject,
+		TextBody:       text,
+		HTMLBody:       html,
+		UnsubscribeURL: unsubscribeURL,
+		Status:         models.OutboxEmailPending,
+	}
+	if err := s.repo.CreateOutboxEmail(&email); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(sendEmailJob{EmailID: email.ID})
+	if err != nil {
+		return err
+	}
+	return s.jobRepo.Enqueue(&models.QueuedJob{
+		Kind:    SendEmailJob,
+		Key:     SendEmailJob + ":" + email.ID.String(),
+		Payload: string(payload),
+		RunAt:   time.Now(),
+	})
+}
+
+// unsubscribeToken returns the user's unsubscribe token, creating it on first use.
+func (s *mailService) unsubscribeToken(userID uuid.UUID) (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return s.repo.GetOrCreateUnsubscribeToken(userID, hex.EncodeToString(random))
+}
+
+// SendEmail sends a pending outbox email. Emails to suppressed addresses are dropped, and
+// emails whose recipient the mail server rejects are recorded as hard bounces. Other
+// failures are retried by the job queue until mailMaxAttempts; an address the server
+// kept deferring until then is recorded as a soft bounce.
+func (s *mailService) SendEmail(payload []byte) error {
+	var job sendEmailJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+	email, err := s.repo.GetOutboxEmailByID(job.EmailID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if email.Status != models.OutboxEmailPending {
+		return nil
+	}
+	suppressed, err := s.repo.IsSuppressed(email.To)
+	if err != nil {
+		return err
+	}
+	if suppressed {
+		email.Status = models.OutboxEmailSuppressed
+		return s.repo.UpdateOutboxEmail(&email)
+	}
+
+	email.Attempts++
+	sendErr := s.transport.Send(mail.Message{
+		ID:             email.ID.String(),
+		To:             email.To,
+		Subject:        email.Subject,
+		Text:           email.TextBody,
+		HTML:           email.HTMLBody,
+		UnsubscribeURL: email.UnsubscribeURL,
+	})
+	var bounce string
+	switch {
+	case sendErr == nil:
+		now := time.Now()
+		email.Status = models.OutboxEmailSent
+		email.SentAt = &now
+		email.LastError = ""
+	case mail.IsRejected(sendErr):
+		email.Status = models.OutboxEmailBounced
+		email.LastError = sendErr.Error()
+		bounce = models.EmailBounceHard
+	case email.Attempts >= mailMaxAttempts:
+		email.Status = models.OutboxEmailFailed
+		email.LastError = sendErr.Error()
+		if mail.IsTransient(sendErr) {
+			bounce = models.EmailBounceSoft
+		}
+	default:
+		email.LastError = sendErr.Error()
+	}
+
+	if err := s.repo.UpdateOutboxEmail(&email); err != nil {
+		return err
+	}
+	if bounce != "" {
+		if err := s.repo.CreateBounce(&models.EmailBounce{Address: email.To, Kind: bounce, Reason: email.LastError, EmailID: &email.ID}); err != nil {
+			log.Printf("Failed to record bounce of email %s: %v", email.ID, err)
+		}
+	}
+	if email.Status == models.OutboxEmailPending {
+		return sendErr
+	}
+	return nil
+}
+
+// RecordBounce records a bounce or complaint about an address. Hard bounces and
+// complaints suppress all further email to it.
+func (s *mailService) RecordBounce(address, kind, reason string) error {
+	switch kind {
+	case models.EmailBounceHard, models.EmailBounceSoft, models.EmailBounceComplaint:
+	default:
+		return fmt.Errorf("invalid bounce kind %q, use hard, soft or complaint", kind)
+	}
+	if address == "" {
+		return errors.New("email is required")
+	}
+	return s.repo.CreateBounce(&models.EmailBounce{Address: address, Kind: kind, Reason: reason})
+}
+
+// Unsubscribe turns off email notifications for the user the token belongs to. Their
+// other notification settings are kept.
+func (s *mailService) Unsubscribe(token string) error {
+	userID, err := s.repo.GetUserIDByUnsubscribeToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidUnsubscribeToken
+	}
+	if err != nil {
+		return err
+	}
+
+	preferences, err := s.notificationRepo.GetPreferences(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		preferences = models.DefaultNotificationPreferences(userID)
+	} else if err != nil {
+		return err
+	}
+	preferences.EmailEnabled = false
+	preferences.UpdatedAt = time.Now()
+	return s.notificationRepo.SavePreferences(&preferences)
+}
+
+func (s *mailService) PurgeOutbox(maxAge time.Duration) (int64, error) {
+	return s.repo.DeleteOutboxEmailsBefore(time.Now().Add(-maxAge))
+}
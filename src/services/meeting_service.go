@@ -0,0 +1,125 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vibely-backend/src/events"
+	"vibely-backend/src/meeting"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// MeetingLinkJob is the kind of the queued jobs creating lesson meeting links.
+const MeetingLinkJob = "meeting-link"
+
+// meetingLinkJob is the payload of a MeetingLinkJob. With Regenerate, a lesson's existing
+// link is replaced; otherwise lessons that have one keep it.
+type meetingLinkJob struct {
+	LessonID   uuid.UUID `json:"lesson_id"`
+	Regenerate bool      `json:"regenerate"`
+}
+
+// MeetingService gives lessons the online meeting they are held in. The meeting is created
+// when a lesson is confirmed and replaced when it is moved, as a queued job so it is
+// retried when the provider is unavailable.
+type MeetingService interface {
+	// Publish queues the meeting link of lessons as they are confirmed and moved.
+	events.Publisher
+
+	// CreateMeetingLink runs a meeting link job.
+	CreateMeetingLink(payload []byte) error
+}
+
+type meetingService struct {
+	provider   meeting.Provider
+	lessonRepo repositories.LessonRepository
+	jobRepo    repositories.JobRepository
+}
+
+// NewMeetingService creates a new instance of MeetingService creating meetings with provider.
+func NewMeetingService(provider meeting.Provider, lessonRepo repositories.LessonRepository, jobRepo repositories.JobRepository) MeetingService {
+	return &meetingService{
+		provider:   provider,
+		lessonRepo: lessonRepo,
+		jobRepo:    jobRepo,
+	}
+}
+
+func (s *meetingService) Publish(eventType string, data interface{}, topics ...string) {
+	change, ok := data.(events.LessonChange)
+	if !ok || eventType != events.LessonUpdated {
+		return
+	}
+	var err error
+	switch change.Action {
+	case models.LessonStatusConfirmed:
+		err = s.queue(meetingLinkJob{LessonID: change.LessonID})
+	case events.LessonActionPostponed:
+		// The old link went out with the old time, so the moved lesson gets a new room.
+		err = s.queue(meetingLinkJob{LessonID: change.LessonID, Regenerate: true})
+	}
+	if err != nil {
+		log.Printf("Failed to queue the meeting link of lesson %s: %v", change.LessonID, err)
+	}
+}
+
+// queue queues a meeting link job, replacing the lesson's pending one of the same sort.
+// Creating and regenerating jobs are keyed apart, so confirming a moved lesson doesn't
+// drop the regeneration of its link.
+func (s *meetingService) queue(job meetingLinkJob) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	key := MeetingLinkJob + ":" + job.LessonID.String()
+	if job.Regenerate {
+		key += ":regenerate"
+	}
+	return s.jobRepo.Enqueue(&models.QueuedJob{
+		Kind:    MeetingLinkJob,
+		Key:     key,
+		Payload: string(payload),
+		RunAt:   time.Now(),
+	})
+}
+
+// CreateMeetingLink creates the lesson's meeting and stores its link on the lesson. Lessons
+// that no longer take place are skipped, as are moved lessons that never had a link: they
+// get one once confirmed again.
+func (s *meetingService) CreateMeetingLink(payload []byte) error {
+	var job meetingLinkJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+	lesson, err := s.lessonRepo.GetLessonByID(job.LessonID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch lesson.Status {
+	case models.LessonStatusScheduled, models.LessonStatusConfirmed, models.LessonStatusInProgress:
+	default:
+		return nil
+	}
+	if job.Regenerate == (lesson.MeetingURL == "") {
+		return nil
+	}
+
+	created, err := s.provider.CreateMeeting(meeting.Request{
+		LessonID:  lesson.ID,
+		Title:     lesson.Title,
+		StartTime: lesson.StartTime,
+		EndTime:   lesson.EndTime,
+	})
+	if err != nil {
+		return err
+	}
+	return s.lessonRepo.UpdateMeetingURL(lesson.ID, created.URL)
+}
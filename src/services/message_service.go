@@ -0,0 +1,331 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"vibely-backend/src/events"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// maxMessageLength caps the length of a message, in characters.
+const maxMessageLength = 4000
+
+// Messaging errors
+var (
+	// ErrNotConversationMember is returned when someone outside a conversation reads or writes it.
+	ErrNotConversationMember = errors.New("you are not part of this conversation")
+	// ErrMessagingNotAllowed is returned when starting a conversation with a user the
+	// sender shares no lesson, course or pending booking with.
+	ErrMessagingNotAllowed = errors.New("you can only message users you share a lesson, course or pending booking with")
+	// ErrMessagingBlocked is returned when messaging between users where one has blocked the other.
+	ErrMessagingBlocked = errors.New("messaging is not possible between users who have blocked each other")
+)
+
+// MessageService manages direct conversations between users and the threads of lessons.
+type MessageService interface {
+	StartDirectConversation(userID, otherID uuid.UUID) (models.Conversation, error)
+	GetLessonConversation(lessonID, userID uuid.UUID) (models.Conversation, error)
+	GetConversations(userID uuid.UUID) ([]models.ConversationSummary, error)
+
+	GetMessages(conversationID, userID uuid.UUID, before *time.Time, limit int) ([]models.MessageDTO, error)
+	SendMessage(conversationID, senderID uuid.UUID, body string) (models.MessageDTO, error)
+	MarkRead(conversationID, userID uuid.UUID) error
+	GetUnreadCount(userID uuid.UUID) (int64, error)
+}
+
+type messageService struct {
+	repo           repositories.MessageRepository
+	userRepo       repositories.UserRepository
+	lessonRepo     repositories.LessonRepository
+	moderationRepo repositories.ModerationRepository
+	publisher      events.Publisher
+}
+
+// NewMessageService creates a new instance of MessageService.
+func NewMessageService(repo repositories.MessageRepository, userRepo repositories.UserRepository, lessonRepo repositories.LessonRepository, moderationRepo repositories.ModerationRepository, publisher events.Publisher) MessageService {
+	return &messageService{
+		repo:           repo,
+		userRepo:       userRepo,
+		lessonRepo:     lessonRepo,
+		moderationRepo: moderationRepo,
+		publisher:      publisher,
+	}
+}
+
+// StartDirectConversation returns the conversation between two users, creating it if
+// needed. Users may only message each other when they share a lesson, a course or a
+// pending booking, and neither has blocked the other.
+func (s *messageService) StartDirectConversation(userID, otherID uuid.UUID) (models.Conversation, error) {
+	if userID == otherID {
+		return models.Conversation{}, errors.New("you cannot message yourself")
+	}
+	if _, err := s.userRepo.GetUserByID(otherID); err != nil {
+		return models.Conversation{}, errors.New("user not found")
+	}
+	if err := s.checkNotBlocked(userID, otherID); err != nil {
+		return models.Conversation{}, err
+	}
+	shared, err := s.repo.UsersShareContext(userID, otherID)
+	if err != nil {
+		return models.Conversation{}, err
+	}
+	if !shared {
+		return models.Conversation{}, ErrMessagingNotAllowed
+	}
+	return s.repo.GetOrCreateDirectConversation(userID, otherID)
+}
+
+// GetLessonConversation returns the thread of a lesson for one of its participants,
+// creating it if needed.
+func (s *messageService) GetLessonConversation(lessonID, userID uuid.UUID) (models.Conversation, error) {
+	lesson, err := s.lessonRepo.GetLessonWithParticipants(lessonID)
+	if err != nil {
+		return models.Conversation{}, errors.New("lesson not found")
+	}
+	if !isLessonParticipant(lesson, userID) {
+		return models.Conversation{}, ErrNotConversationMember
+	}
+	return s.repo.GetOrCreateLessonConversation(lessonID)
+}
+
+// GetConversations lists the user's conversations with messages, most recent first,
+// each with its latest message and the number of messages the user has not read.
+func (s *messageService) GetConversations(userID uuid.UUID) ([]models.ConversationSummary, error) {
+	conversations, err := s.repo.GetConversationsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(conversations))
+	for _, conversation := range conversations {
+		ids = append(ids, conversation.ID)
+	}
+
+	lastMessages, err := s.repo.GetLastMessages(ids)
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[uuid.UUID]models.Message, len(lastMessages))
+	for _, message := range lastMessages {
+		last[message.ConversationID] = message
+	}
+	counts, err := s.repo.CountUnread(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	unread := make(map[uuid.UUID]int64, len(counts))
+	for _, count := range counts {
+		unread[count.ConversationID] = count.Count
+	}
+
+	summaries := make([]models.ConversationSummary, 0, len(conversations))
+	for _, conversation := range conversations {
+		summary := models.ConversationSummary{
+			Conversation: conversation,
+			UnreadCount:  unread[conversation.ID],
+		}
+		if message, ok := last[conversation.ID]; ok {
+			dto := message.ToDTO(nil)
+			summary.LastMessage = &dto
+		}
+		if otherID, ok := otherDirectUser(conversation, userID); ok {
+			if other, err := s.userRepo.GetUserByID(otherID); err == nil {
+				dto := other.ToStudentDTO()
+				summary.OtherUser = &dto
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// GetMessages returns a page of a conversation's history, newest first. Pass the
+// created_at of the oldest message received as before to get the previous page.
+// Messages from users the reader has blocked are left out.
+func (s *messageService) GetMessages(conversationID, userID uuid.UUID, before *time.Time, limit int) ([]models.MessageDTO, error) {
+	if _, err := s.memberConversation(conversationID, userID); err != nil {
+		return nil, err
+	}
+	blocks, err := s.moderationRepo.GetBlocksByBlocker(userID)
+	if err != nil {
+		return nil, err
+	}
+	hidden := make([]uuid.UUID, 0, len(blocks))
+	for _, block := range blocks {
+		hidden = append(hidden, block.BlockedID)
+	}
+
+	messages, err := s.repo.GetMessages(conversationID, before, limit, hidden)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := s.repo.GetReadReceipts(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]models.MessageDTO, 0, len(messages))
+	for _, message := range messages {
+		dtos = append(dtos, message.ToDTO(receipts))
+	}
+	return dtos, nil
+}
+
+// SendMessage posts a text message to a conversation. Sending also marks the
+// conversation as read for the sender.
+func (s *messageService) SendMessage(conversationID, senderID uuid.UUID, body string) (models.MessageDTO, error) {
+	conversation, err := s.memberConversation(conversationID, senderID)
+	if err != nil {
+		return models.MessageDTO{}, err
+	}
+	if otherID, ok := otherDirectUser(conversation, senderID); ok {
+		if err := s.checkNotBlocked(senderID, otherID); err != nil {
+			return models.MessageDTO{}, err
+		}
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return models.MessageDTO{}, errors.New("message body is required")
+	}
+	if utf8.RuneCountInString(body) > maxMessageLength {
+		return models.MessageDTO{}, errors.New("message is too long")
+	}
+
+	message := models.Message{
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Body:           body,
+		CreatedAt:      time.Now().Truncate(time.Microsecond), // as stored by Postgres
+	}
+	if err := s.repo.CreateMessage(&message); err != nil {
+		return models.MessageDTO{}, err
+	}
+	if err := s.repo.MarkRead(conversationID, senderID, message.CreatedAt); err != nil {
+		return models.MessageDTO{}, err
+	}
+	dto := message.ToDTO(nil)
+	s.publishMessage(conversation, dto)
+	return dto, nil
+}
+
+// publishMessage delivers a new message to the conversation's members, except to those
+// who blocked the sender and so don't see their messages.
+func (s *messageService) publishMessage(conversation models.Conversation, message models.MessageDTO) {
+	var memberIDs []uuid.UUID
+	switch conversation.Kind {
+	case models.ConversationKindDirect:
+		memberIDs = []uuid.UUID{*conversation.UserAID, *conversation.UserBID}
+	case models.ConversationKindLesson:
+		lesson, err := s.lessonRepo.GetLessonWithParticipants(*conversation.LessonID)
+		if err != nil {
+			log.Printf("Failed to load participants of lesson %s for message %s: %v", *conversation.LessonID, message.ID, err)
+			return
+		}
+		memberIDs = lessonParticipantIDs(lesson)
+	}
+
+	recipients := make([]uuid.UUID, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if memberID != message.SenderID {
+			blocks, err := s.moderationRepo.GetBlocksByBlocker(memberID)
+			if err != nil {
+				log.Printf("Failed to load blocks of user %s for message %s: %v", memberID, message.ID, err)
+				continue
+			}
+			if blocksUser(blocks, message.SenderID) {
+				continue
+			}
+		}
+		recipients = append(recipients, memberID)
+	}
+	s.publisher.Publish(events.MessageCreated, message, events.UserTopics(recipients...)...)
+}
+
+// MarkRead marks everything in the conversation up to now as read by the user.
+func (s *messageService) MarkRead(conversationID, userID uuid.UUID) error {
+	if _, err := s.memberConversation(conversationID, userID); err != nil {
+		return err
+	}
+	return s.repo.MarkRead(conversationID, userID, time.Now())
+}
+
+// GetUnreadCount counts the user's unread messages across all conversations.
+func (s *messageService) GetUnreadCount(userID uuid.UUID) (int64, error) {
+	conversations, err := s.repo.GetConversationsForUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]uuid.UUID, 0, len(conversations))
+	for _, conversation := range conversations {
+		ids = append(ids, conversation.ID)
+	}
+	counts, err := s.repo.CountUnread(userID, ids)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, count := range counts {
+		total += count.Count
+	}
+	return total, nil
+}
+
+// memberConversation returns the conversation if the user is one of its two parties or,
+// for a lesson thread, a participant of the lesson.
+func (s *messageService) memberConversation(conversationID, userID uuid.UUID) (models.Conversation, error) {
+	conversation, err := s.repo.GetConversationByID(conversationID)
+	if err != nil {
+		return models.Conversation{}, errors.New("conversation not found")
+	}
+	switch conversation.Kind {
+	case models.ConversationKindDirect:
+		if _, ok := otherDirectUser(conversation, userID); ok {
+			return conversation, nil
+		}
+	case models.ConversationKindLesson:
+		lesson, err := s.lessonRepo.GetLessonWithParticipants(*conversation.LessonID)
+		if err == nil && isLessonParticipant(lesson, userID) {
+			return conversation, nil
+		}
+	}
+	return models.Conversation{}, ErrNotConversationMember
+}
+
+// blocksUser reports whether userID is among the blocked users.
+func blocksUser(blocks []models.UserBlock, userID uuid.UUID) bool {
+	for _, block := range blocks {
+		if block.BlockedID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *messageService) checkNotBlocked(userA, userB uuid.UUID) error {
+	blocked, err := s.moderationRepo.IsBlocked(userA, userB)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrMessagingBlocked
+	}
+	return nil
+}
+
+// otherDirectUser returns the other party of a direct conversation userID is part of.
+func otherDirectUser(conversation models.Conversation, userID uuid.UUID) (uuid.UUID, bool) {
+	if conversation.Kind != models.ConversationKindDirect || conversation.UserAID == nil || conversation.UserBID == nil {
+		return uuid.Nil, false
+	}
+	switch userID {
+	case *conversation.UserAID:
+		return *conversation.UserBID, true
+	case *conversation.UserBID:
+		return *conversation.UserAID, true
+	}
+	return uuid.Nil, false
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// ModerationService defines business logic for reports, user blocks and suspensions.
+type ModerationService interface {
+	// Reporting
+	ReportContent(reporterID uuid.UUID, targetType string, targetID uuid.UUID, reason, details string) (models.Report, error)
+
+	// Moderation queue (admins)
+	GetReports(status string, page, limit int) ([]models.Report, int64, error)
+	ResolveReport(reportID, adminID uuid.UUID, note string, suspendTarget bool) (models.Report, error)
+	DismissReport(reportID, adminID uuid.UUID, note string) (models.Report, error)
+	SuspendUser(userID uuid.UUID, reason string) (models.User, error)
+	UnsuspendUser(userID uuid.UUID) (models.User, error)
+
+	// Blocking
+	BlockUser(blockerID, blockedID uuid.UUID) (models.UserBlock, error)
+	UnblockUser(blockerID, blockedID uuid.UUID) error
+	GetBlockedUsers(blockerID uuid.UUID) ([]models.User, error)
+	IsBlocked(userA, userB uuid.UUID) (bool, error)
+}
+
+type moderationService struct {
+	moderationRepo repositories.ModerationRepository
+	userRepo       repositories.UserRepository
+	courseRepo     repositories.CourseRepository
+}
+
+// NewModerationService creates a new instance of ModerationService.
+func NewModerationService(moderationRepo repositories.ModerationRepository, userRepo repositories.UserRepository, courseRepo repositories.CourseRepository) ModerationService {
+	return &moderationService{
+		moderationRepo: moderationRepo,
+		userRepo:       userRepo,
+		courseRepo:     courseRepo,
+	}
+}
+
+// ErrUsersBlocked is returned when a booking or enrollment involves two users
+// where one has blocked the other.
+var ErrUsersBlocked = errors.New("booking is not possible between users who have blocked each other")
+
+var validReportReasons = map[string]bool{
+	models.ReportReasonSpam:          true,
+	models.ReportReasonHarassment:    true,
+	models.ReportReasonFakeReview:    true,
+	models.ReportReasonInappropriate: true,
+	models.ReportReasonScam:          true,
+	models.ReportReasonOther:         true,
+}
+
+// ReportContent files a new report against a user, review or course.
+func (s *moderationService) ReportContent(reporterID uuid.UUID, targetType string, targetID uuid.UUID, reason, details string) (models.Report, error) {
+	if targetID == uuid.Nil {
+		return models.Report{}, errors.New("target_id is required")
+	}
+	if !validReportReasons[reason] {
+		return models.Report{}, errors.New("invalid report reason")
+	}
+	if reason == models.ReportReasonOther && strings.TrimSpace(details) == "" {
+		return models.Report{}, errors.New("details are required when reason is 'other'")
+	}
+
+	// Make sure the reported entity exists.
+	switch targetType {
+	case models.ReportTargetUser:
+		if targetID == reporterID {
+			return models.Report{}, errors.New("you cannot report yourself")
+		}
+		if _, err := s.userRepo.GetUserByID(targetID); err != nil {
+			return models.Report{}, errors.New("reported user not found")
+		}
+	case models.ReportTargetCourse:
+		if _, err := s.courseRepo.GetCourseByID(targetID); err != nil {
+			return models.Report{}, errors.New("reported course not found")
+		}
+	case models.ReportTargetReview:
+		// Reviews are not stored by this service yet, so the ID is accepted as-is.
+	default:
+		return models.Report{}, errors.New("target_type must be one of: user, review, course")
+	}
+
+	// Avoid flooding the queue with duplicates from the same reporter.
+	if _, err := s.moderationRepo.GetOpenReportByReporter(reporterID, targetType, targetID); err == nil {
+		return models.Report{}, errors.New("you have already reported this")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Report{}, err
+	}
+
+	report := models.Report{
+		ReporterID: reporterID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		Details:    details,
+		Status:     models.ReportStatusOpen,
+	}
+	if err := s.moderationRepo.CreateReport(&report); err != nil {
+		return models.Report{}, err
+	}
+	return s.moderationRepo.GetReportByID(report.ID)
+}
+
+// GetReports returns the moderation queue filtered by status.
+func (s *moderationService) GetReports(status string, page, limit int) ([]models.Report, int64, error) {
+	if status != "" &&
+		status != models.ReportStatusOpen &&
+		status != models.ReportStatusResolved &&
+		status != models.ReportStatusDismissed {
+		return nil, 0, errors.New("invalid report status")
+	}
+	return s.moderationRepo.GetReports(status, page, limit)
+}
+
+// ResolveReport closes a report as actioned. When suspendTarget is set and the
+// report targets a user, that user is suspended with the resolution note as reason.
+func (s *moderationService) ResolveReport(reportID, adminID uuid.UUID, note string, suspendTarget bool) (models.Report, error) {
+	return s.closeReport(reportID, adminID, note, models.ReportStatusResolved, suspendTarget)
+}
+
+// DismissReport closes a report without action.
+func (s *moderationService) DismissReport(reportID, adminID uuid.UUID, note string) (models.Report, error) {
+	return s.closeReport(reportID, adminID, note, models.ReportStatusDismissed, false)
+}
+
+// closeReport closes an open report with status and, if suspendTarget is set, suspends
+// the reported user. Both happen in one transaction, so a report whose target cannot be
+// suspended stays open.
+func (s *moderationService) closeReport(reportID, adminID uuid.UUID, note, status string, suspendTarget bool) (models.Report, error) {
+	var report models.Report
+	err := s.moderationRepo.Transaction(func(tx repositories.ModerationRepository) error {
+		var err error
+		report, err = tx.LockReport(reportID)
+		if err != nil {
+			return errors.New("report not found")
+		}
+		if report.Status != models.ReportStatusOpen {
+			return errors.New("only open reports can be resolved or dismissed")
+		}
+
+		var target models.User
+		if suspendTarget {
+			if report.TargetType != models.ReportTargetUser {
+				return errors.New("only reported users can be suspended")
+			}
+			target, err = s.userRepo.GetUserByID(report.TargetID)
+			if err != nil {
+				return errors.New("user not found")
+			}
+			if target.Role == models.UserRoleAdmin {
+				return errors.New("admins cannot be suspended")
+			}
+		}
+
+		now := time.Now()
+		report.Status = status
+		report.ResolutionNote = note
+		report.ResolvedByID = &adminID
+		report.ResolvedAt = &now
+		if err := tx.UpdateReport(&report); err != nil {
+			return err
+		}
+		if suspendTarget {
+			return tx.SuspendUser(target.ID, note, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Report{}, err
+	}
+	return s.moderationRepo.GetReportByID(report.ID)
+}
+
+// SuspendUser suspends an account so that it can no longer authenticate.
+func (s *moderationService) SuspendUser(userID uuid.UUID, reason string) (models.User, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return models.User{}, errors.New("user not found")
+	}
+	if user.Role == models.UserRoleAdmin {
+		return models.User{}, errors.New("admins cannot be suspended")
+	}
+	if user.IsSuspended() {
+		return user, nil
+	}
+
+	now := time.Now()
+	user.SuspendedAt = &now
+	user.SuspensionReason = reason
+	if err := s.userRepo.UpdateUser(&user); err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// UnsuspendUser lifts a suspension.
+func (s *moderationService) UnsuspendUser(userID uuid.UUID) (models.User, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return models.User{}, errors.New("user not found")
+	}
+
+	user.SuspendedAt = nil
+	user.SuspensionReason = ""
+	if err := s.userRepo.UpdateUser(&user); err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// BlockUser blocks blockedID on behalf of blockerID.
+func (s *moderationService) BlockUser(blockerID, blockedID uuid.UUID) (models.UserBlock, error) {
+	if blockerID == blockedID {
+		return models.UserBlock{}, errors.New("you cannot block yourself")
+	}
+	if _, err := s.userRepo.GetUserByID(blockedID); err != nil {
+		return models.UserBlock{}, errors.New("user not found")
+	}
+
+	blocks, err := s.moderationRepo.GetBlocksByBlocker(blockerID)
+	if err != nil {
+		return models.UserBlock{}, err
+	}
+	for _, b := range blocks {
+		if b.BlockedID == blockedID {
+			return b, nil // Already blocked; nothing to do.
+		}
+	}
+
+	block := models.UserBlock{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+	}
+	if err := s.moderationRepo.CreateBlock(&block); err != nil {
+		return models.UserBlock{}, err
+	}
+	return block, nil
+}
+
+// UnblockUser removes a block placed by blockerID.
+func (s *moderationService) UnblockUser(blockerID, blockedID uuid.UUID) error {
+	if err := s.moderationRepo.DeleteBlock(blockerID, blockedID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user is not blocked")
+		}
+		return err
+	}
+	return nil
+}
+
+// GetBlockedUsers lists the users blocked by blockerID.
+func (s *moderationService) GetBlockedUsers(blockerID uuid.UUID) ([]models.User, error) {
+	blocks, err := s.moderationRepo.GetBlocksByBlocker(blockerID)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]models.User, 0, len(blocks))
+	for _, b := range blocks {
+		users = append(users, b.Blocked)
+	}
+	return users, nil
+}
+
+// IsBlocked reports whether either user has blocked the other.
+func (s *moderationService) IsBlocked(userA, userB uuid.UUID) (bool, error) {
+	return s.moderationRepo.IsBlocked(userA, userB)
+}
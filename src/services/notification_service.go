@@ -0,0 +1,306 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vibely-backend/src/events"
+	"vibely-backend/src/mail"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// emailBatchSize caps how many pending emails one SendDueEmails run queues.
+const emailBatchSize = 100
+
+// ErrNotificationNotFound is returned when a user has no notification with the given ID.
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationService turns domain events into notifications, delivered in the app's
+// notification center, by email and as Discord direct messages according to each user's
+// preferences.
+type NotificationService interface {
+	// Publish receives the domain events to notify users about.
+	events.Publisher
+	// Notify sends a notification not tied to a domain event.
+	Notifier
+
+	GetNotifications(userID uuid.UUID, unreadOnly bool, page, limit int) ([]models.Notification, int64, error)
+	GetUnreadCount(userID uuid.UUID) (int64, error)
+	MarkRead(notificationID, userID uuid.UUID) error
+	MarkAllRead(userID uuid.UUID) error
+
+	GetPreferences(userID uuid.UUID) (models.NotificationPreferences, error)
+	UpdatePreferences(preferences models.NotificationPreferences) (models.NotificationPreferences, error)
+
+	// SendDueEmails queues the pending emails whose quiet hours are over, run by the scheduler.
+	SendDueEmails() (int, error)
+}
+
+type notificationService struct {
+	repo      repositories.NotificationRepository
+	emails    EmailSender
+	jobRepo   repositories.JobRepository
+	publisher events.Publisher
+	// discordDMs tells whether the Discord bot is set up to send direct messages.
+	discordDMs bool
+}
+
+// NewNotificationService creates a new instance of NotificationService. New in-app
+// notifications are announced to the user through publisher. With discordDMs, they are
+// also queued as DiscordDMJob jobs for the users who enabled Discord.
+func NewNotificationService(repo repositories.NotificationRepository, emails EmailSender, jobRepo repositories.JobRepository, publisher events.Publisher, discordDMs bool) NotificationService {
+	return &notificationService{
+		repo:       repo,
+		emails:     emails,
+		jobRepo:    jobRepo,
+		publisher:  publisher,
+		discordDMs: discordDMs,
+	}
+}
+
+// Publish notifies the users an event is addressed to about the lesson and enrollment
+// changes worth telling them about. The user who made the change is not notified.
+func (s *notificationService) Publish(eventType string, data interface{}, topics ...string) {
+	switch change := data.(type) {
+	case events.LessonChange:
+		notificationType, title, body := lessonNotification(eventType, change)
+		if notificationType == "" {
+			return
+		}
+		for _, userID := range topicUsers(topics) {
+			if change.ActorID != nil && *change.ActorID == userID {
+				continue
+			}
+			notification := models.Notification{
+				UserID:   userID,
+				Type:     notificationType,
+				Title:    title,
+				LessonID: &change.LessonID,
+				CourseID: change.CourseID,
+			}
+			s.deliver(notification, body)
+		}
+	case events.EnrollmentChange:
+		if change.Waitlisted {
+			return
+		}
+		notification := models.Notification{
+			UserID:   change.TutorID,
+			Type:     models.NotificationTypeEnrollment,
+			Title:    "New student enrolled",
+			LessonID: change.LessonID,
+			CourseID: change.CourseID,
+		}
+		s.deliver(notification, func(*time.Location) string {
+			return fmt.Sprintf("A student enrolled in %q.", change.Title)
+		})
+	}
+}
+
+// lessonNotification returns the type, title and body of the notification about a lesson
+// change, or an empty type for changes users aren't notified about.
+func lessonNotification(eventType string, change events.LessonChange) (string, string, func(*time.Location) string) {
+	name := change.Title
+	if name == "" {
+		name = "Your lesson"
+	}
+	when := func(loc *time.Location) string {
+		return change.StartTime.In(loc).Format("Mon, 2 Jan 2006 15:04 MST")
+	}
+
+	switch eventType {
+	case events.LessonCreated:
+		return models.NotificationTypeLessonScheduled, "New lesson scheduled", func(loc *time.Location) string {
+			return fmt.Sprintf("%q was scheduled for %s.", name, when(loc))
+		}
+	case events.LessonReminder:
+		return models.NotificationTypeLessonReminder, "Upcoming lesson", func(loc *time.Location) string {
+			return fmt.Sprintf("%q starts on %s.", name, when(loc))
+		}
+	}
+	switch change.Action {
+	case models.LessonStatusConfirmed:
+		return models.NotificationTypeLessonConfirmed, "Lesson confirmed", func(loc *time.Location) string {
+			return fmt.Sprintf("%q on %s was confirmed.", name, when(loc))
+		}
+	case models.LessonStatusCancelled:
+		return models.NotificationTypeLessonCancelled, "Lesson cancelled", func(loc *time.Location) string {
+			return fmt.Sprintf("%q on %s was cancelled.", name, when(loc))
+		}
+	case events.LessonActionPostponed:
+		return models.NotificationTypeLessonPostponed, "Lesson postponed", func(loc *time.Location) string {
+			return fmt.Sprintf("%q was moved to %s.", name, when(loc))
+		}
+	case models.LessonStatusExpired:
+		return models.NotificationTypeLessonExpired, "Lesson expired", func(loc *time.Location) string {
+			return fmt.Sprintf("%q on %s expired because it was not confirmed in time.", name, when(loc))
+		}
+	}
+	return "", "", nil
+}
+
+// topicUsers returns the users of the user topics among topics.
+func topicUsers(topics []string) []uuid.UUID {
+	var userIDs []uuid.UUID
+	for _, topic := range topics {
+		if userID, ok := events.UserIDFromTopic(topic); ok {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs
+}
+
+func (s *notificationService) Notify(userID uuid.UUID, subject, message string) error {
+	notification := models.Notification{UserID: userID, Type: models.NotificationTypeGeneral, Title: subject}
+	return s.deliver(notification, func(*time.Location) string { return message })
+}
+
+// deliver stores the notification on the channels the user enabled. Its body is written
+// in the user's time zone. The email is queued for SendDueEmails and the direct message
+// as a job, after the user's quiet hours when they are in them.
+func (s *notificationService) deliver(notification models.Notification, body func(loc *time.Location) string) error {
+	preferences, err := s.GetPreferences(notification.UserID)
+	if err != nil {
+		log.Printf("Failed to load notification preferences of user %s: %v", notification.UserID, err)
+		return err
+	}
+	discord := preferences.DiscordEnabled && s.discordDMs
+	if !preferences.InAppEnabled && !preferences.EmailEnabled && !discord {
+		return nil
+	}
+
+	now := time.Now()
+	due := now
+	if until, quiet := preferences.QuietUntil(now); quiet {
+		due = until
+	}
+	notification.Body = body(preferences.Location())
+	notification.InApp = preferences.InAppEnabled
+	if preferences.EmailEnabled {
+		notification.EmailStatus = models.NotificationEmailPending
+		notification.EmailDueAt = &due
+	}
+	if err := s.repo.CreateNotification(&notification); err != nil {
+		log.Printf("Failed to store %s notification for user %s: %v", notification.Type, notification.UserID, err)
+		return err
+	}
+	if discord {
+		if err := s.queueDiscordDM(notification, due); err != nil {
+			log.Printf("Failed to queue Discord message for notification %s: %v", notification.ID, err)
+		}
+	}
+	if notification.InApp {
+		s.publisher.Publish(events.NotificationCreated, notification, events.UserTopic(notification.UserID))
+	}
+	return nil
+}
+
+// queueDiscordDM queues the notification's direct message, to be sent at due.
+func (s *notificationService) queueDiscordDM(notification models.Notification, due time.Time) error {
+	payload, err := json.Marshal(DiscordDM{UserID: notification.UserID, Title: notification.Title, Body: notification.Body})
+	if err != nil {
+		return err
+	}
+	return s.jobRepo.Enqueue(&models.QueuedJob{
+		Kind:    DiscordDMJob,
+		Key:     DiscordDMJob + ":" + notification.ID.String(),
+		Payload: string(payload),
+		RunAt:   due,
+	})
+}
+
+func (s *notificationService) GetNotifications(userID uuid.UUID, unreadOnly bool, page, limit int) ([]models.Notification, int64, error) {
+	return s.repo.GetNotifications(userID, unreadOnly, page, limit)
+}
+
+func (s *notificationService) GetUnreadCount(userID uuid.UUID) (int64, error) {
+	return s.repo.CountUnread(userID)
+}
+
+// MarkRead marks one of the user's notifications as read.
+func (s *notificationService) MarkRead(notificationID, userID uuid.UUID) error {
+	found, err := s.repo.MarkRead(notificationID, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(userID uuid.UUID) error {
+	return s.repo.MarkAllRead(userID, time.Now())
+}
+
+// GetPreferences returns the user's preferences, or the defaults when they never set any.
+func (s *notificationService) GetPreferences(userID uuid.UUID) (models.NotificationPreferences, error) {
+	preferences, err := s.repo.GetPreferences(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultNotificationPreferences(userID), nil
+	}
+	return preferences, err
+}
+
+// UpdatePreferences validates and saves the user's preferences.
+func (s *notificationService) UpdatePreferences(preferences models.NotificationPreferences) (models.NotificationPreferences, error) {
+	if preferences.TimeZone == "" {
+		preferences.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(preferences.TimeZone); err != nil {
+		return models.NotificationPreferences{}, errors.New("invalid time zone")
+	}
+	if (preferences.QuietHoursStart == "") != (preferences.QuietHoursEnd == "") {
+		return models.NotificationPreferences{}, errors.New("quiet hours need both a start and an end")
+	}
+	if preferences.Language != "" && !mail.IsLanguage(preferences.Language) {
+		return models.NotificationPreferences{}, errors.New("unsupported language, use pl or en")
+	}
+	if preferences.QuietHoursStart != "" {
+		if _, err := time.Parse("15:04", preferences.QuietHoursStart); err != nil {
+			return models.NotificationPreferences{}, errors.New("invalid quiet hours start, use 24-hour format (e.g., 22:00)")
+		}
+		if _, err := time.Parse("15:04", preferences.QuietHoursEnd); err != nil {
+			return models.NotificationPreferences{}, errors.New("invalid quiet hours end, use 24-hour format (e.g., 07:00)")
+		}
+	}
+
+	preferences.UpdatedAt = time.Now()
+	if err := s.repo.SavePreferences(&preferences); err != nil {
+		return models.NotificationPreferences{}, err
+	}
+	return preferences, nil
+}
+
+// SendDueEmails hands the pending notification emails that are due to the mailer and
+// returns how many were queued. An email the mailer refuses is marked as failed and not
+// retried; the mailer's outbox tracks the delivery of the others.
+func (s *notificationService) SendDueEmails() (int, error) {
+	notifications, err := s.repo.GetDueEmails(time.Now(), emailBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, notification := range notifications {
+		status := models.NotificationEmailSent
+		content := mail.NotificationData{Title: notification.Title, Body: notification.Body}
+		if err := s.emails.EmailUser(notification.UserID, mail.TemplateNotification, content, true); err != nil {
+			log.Printf("Failed to email notification %s to user %s: %v", notification.ID, notification.UserID, err)
+			status = models.NotificationEmailFailed
+		}
+		if err := s.repo.SetEmailStatus(notification.ID, status); err != nil {
+			return sent, err
+		}
+		if status == models.NotificationEmailSent {
+			sent++
+		}
+	}
+	return sent, nil
+}
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"github.com/google/uuid"
+)
+
+// Notifier delivers short notifications to users.
+type Notifier interface {
+	Notify(userID uuid.UUID, subject, message string) error
+}
+
+// EmailSender emails users, rendered from the mail templates in each user's language.
+type EmailSender interface {
+	// EmailUser queues the template, executed with content, to the user's address. Emails
+	// the user may opt out of, like notifications, carry an unsubscribe link.
+	EmailUser(userID uuid.UUID, template string, content interface{}, optOut bool) error
+}
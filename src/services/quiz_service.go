@@ -0,0 +1,622 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// quizGracePeriod is how late after the time limit an attempt is still accepted, to
+// allow for the network.
+const quizGracePeriod = 30 * time.Second
+
+// Quiz errors
+var (
+	// ErrNoQuizAccess is returned when someone outside the course or lesson opens its quiz.
+	ErrNoQuizAccess = errors.New("only participants of the course or lesson can access this quiz")
+	// ErrQuizAttemptsUsed is returned when a student has no attempts left at a quiz.
+	ErrQuizAttemptsUsed = errors.New("no attempts left at this quiz")
+	// ErrQuizTimeUp is returned when an attempt is submitted after its time limit.
+	ErrQuizTimeUp = errors.New("the time limit of this attempt has passed")
+	// ErrQuizLocked is returned when changing a quiz students have already attempted.
+	ErrQuizLocked = errors.New("a quiz cannot be changed once students have attempted it")
+)
+
+// QuizService manages quizzes on courses and lessons: tutors write them, students take
+// them within the time and attempt limits, and answers are graded automatically where
+// possible and by the tutor otherwise.
+type QuizService interface {
+	CreateQuiz(quiz models.Quiz, tutorID uuid.UUID) (models.Quiz, error)
+	GetQuiz(quizID, userID uuid.UUID) (models.Quiz, error)
+	GetCourseQuizzes(courseID, userID uuid.UUID) ([]models.Quiz, error)
+	GetLessonQuizzes(lessonID, userID uuid.UUID) ([]models.Quiz, error)
+	UpdateQuiz(quizID, tutorID uuid.UUID, quiz models.Quiz) (models.Quiz, error)
+	DeleteQuiz(quizID, tutorID uuid.UUID) error
+
+	StartAttempt(quizID, studentID uuid.UUID) (models.QuizAttempt, error)
+	SubmitAttempt(attemptID, studentID uuid.UUID, answers []models.QuizAnswer) (models.QuizAttempt, error)
+	GradeAnswer(attemptID, questionID, tutorID uuid.UUID, points float64) (models.QuizAttempt, error)
+	GetAttempts(quizID, userID uuid.UUID) ([]models.QuizAttempt, error)
+	ExpireAttempts() (int64, error)
+
+	GetCourseQuizResults(courseID uuid.UUID) ([]models.QuizResult, error)
+}
+
+type quizService struct {
+	repo       repositories.QuizRepository
+	courseRepo repositories.CourseRepository
+	lessonRepo repositories.LessonRepository
+}
+
+// NewQuizService creates a new instance of QuizService.
+func NewQuizService(repo repositories.QuizRepository, courseRepo repositories.CourseRepository, lessonRepo repositories.LessonRepository) QuizService {
+	return &quizService{
+		repo:       repo,
+		courseRepo: courseRepo,
+		lessonRepo: lessonRepo,
+	}
+}
+
+// CreateQuiz adds a quiz to a course or lesson of the tutor.
+func (s *quizService) CreateQuiz(quiz models.Quiz, tutorID uuid.UUID) (models.Quiz, error) {
+	if (quiz.CourseID == nil) == (quiz.LessonID == nil) {
+		return models.Quiz{}, errors.New("a quiz belongs to either a course or a lesson")
+	}
+	ownerID, _, err := s.quizParticipants(quiz)
+	if err != nil {
+		return models.Quiz{}, err
+	}
+	if ownerID != tutorID {
+		return models.Quiz{}, errors.New("only the tutor can add quizzes")
+	}
+	if err := validateQuiz(&quiz); err != nil {
+		return models.Quiz{}, err
+	}
+
+	quiz.ID = uuid.Nil
+	quiz.TutorID = tutorID
+	if err := s.repo.CreateQuiz(&quiz); err != nil {
+		return models.Quiz{}, err
+	}
+	return s.repo.GetQuizByID(quiz.ID)
+}
+
+// GetQuiz returns a quiz to its tutor or a participant of its course or lesson. It
+// includes the answers, so callers only show them to the tutor.
+func (s *quizService) GetQuiz(quizID, userID uuid.UUID) (models.Quiz, error) {
+	quiz, err := s.repo.GetQuizByID(quizID)
+	if err != nil {
+		return models.Quiz{}, errors.New("quiz not found")
+	}
+	if err := s.checkAccess(quiz, userID); err != nil {
+		return models.Quiz{}, err
+	}
+	return quiz, nil
+}
+
+func (s *quizService) GetCourseQuizzes(courseID, userID uuid.UUID) ([]models.Quiz, error) {
+	if err := s.checkAccess(models.Quiz{CourseID: &courseID}, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetQuizzesByCourseID(courseID)
+}
+
+func (s *quizService) GetLessonQuizzes(lessonID, userID uuid.UUID) ([]models.Quiz, error) {
+	if err := s.checkAccess(models.Quiz{LessonID: &lessonID}, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetQuizzesByLessonID(lessonID)
+}
+
+// UpdateQuiz replaces the details and questions of a quiz nobody has attempted yet.
+func (s *quizService) UpdateQuiz(quizID, tutorID uuid.UUID, quiz models.Quiz) (models.Quiz, error) {
+	existing, err := s.editableQuiz(quizID, tutorID)
+	if err != nil {
+		return models.Quiz{}, err
+	}
+	if err := validateQuiz(&quiz); err != nil {
+		return models.Quiz{}, err
+	}
+
+	existing.Title = quiz.Title
+	existing.Description = quiz.Description
+	existing.TimeLimitMinutes = quiz.TimeLimitMinutes
+	existing.MaxAttempts = quiz.MaxAttempts
+	existing.Questions = quiz.Questions
+	if err := s.repo.UpdateQuiz(&existing); err != nil {
+		return models.Quiz{}, err
+	}
+	return s.repo.GetQuizByID(quizID)
+}
+
+// DeleteQuiz removes a quiz nobody has attempted yet.
+func (s *quizService) DeleteQuiz(quizID, tutorID uuid.UUID) error {
+	if _, err := s.editableQuiz(quizID, tutorID); err != nil {
+		return err
+	}
+	return s.repo.DeleteQuiz(quizID)
+}
+
+// StartAttempt starts a new attempt at the quiz for the student. If the student still has
+// an attempt running, that attempt is returned instead so it can be resumed.
+func (s *quizService) StartAttempt(quizID, studentID uuid.UUID) (models.QuizAttempt, error) {
+	quiz, err := s.repo.GetQuizByID(quizID)
+	if err != nil {
+		return models.QuizAttempt{}, errors.New("quiz not found")
+	}
+	if err := s.checkAccess(quiz, studentID); err != nil {
+		return models.QuizAttempt{}, err
+	}
+	if quiz.TutorID == studentID {
+		return models.QuizAttempt{}, errors.New("tutors cannot attempt their own quizzes")
+	}
+
+	var attempt models.QuizAttempt
+	err = s.repo.Transaction(func(tx repositories.QuizRepository) error {
+		attempts, err := tx.LockStudentAttempts(quizID, studentID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, previous := range attempts {
+			if previous.Status == models.QuizAttemptStatusInProgress && !attemptTimedOut(previous, now) {
+				attempt = previous
+				return nil
+			}
+		}
+		if quiz.MaxAttempts > 0 && len(attempts) >= quiz.MaxAttempts {
+			return ErrQuizAttemptsUsed
+		}
+
+		attempt = models.QuizAttempt{
+			QuizID:    quizID,
+			StudentID: studentID,
+			Status:    models.QuizAttemptStatusInProgress,
+			StartedAt: now,
+			MaxScore:  quiz.MaxScore(),
+		}
+		if quiz.TimeLimitMinutes > 0 {
+			expiresAt := now.Add(time.Duration(quiz.TimeLimitMinutes) * time.Minute)
+			attempt.ExpiresAt = &expiresAt
+		}
+		return tx.CreateAttempt(&attempt)
+	})
+	if err != nil {
+		return models.QuizAttempt{}, err
+	}
+	return s.repo.GetAttemptByID(attempt.ID)
+}
+
+// SubmitAttempt records the student's answers and grades those that can be graded
+// automatically. Unanswered questions score zero. The attempt waits for review while
+// any short answer still needs the tutor.
+func (s *quizService) SubmitAttempt(attemptID, studentID uuid.UUID, answers []models.QuizAnswer) (models.QuizAttempt, error) {
+	var timedOut bool
+	err := s.repo.Transaction(func(tx repositories.QuizRepository) error {
+		attempt, err := tx.LockAttempt(attemptID)
+		if err != nil || attempt.StudentID != studentID {
+			return errors.New("attempt not found")
+		}
+		if attempt.IsFinished() {
+			return errors.New("this attempt has already been submitted")
+		}
+		now := time.Now()
+		if attemptTimedOut(attempt, now) {
+			// Close the attempt; the caller is told after the transaction commits.
+			timedOut = true
+			attempt.Status = models.QuizAttemptStatusExpired
+			return tx.UpdateAttempt(&attempt)
+		}
+
+		quiz, err := tx.GetQuizByID(attempt.QuizID)
+		if err != nil {
+			return err
+		}
+		given := make(map[uuid.UUID]models.QuizAnswer, len(answers))
+		for _, answer := range answers {
+			given[answer.QuestionID] = answer
+		}
+
+		graded := make([]models.QuizAnswer, 0, len(quiz.Questions))
+		for _, question := range quiz.Questions {
+			answer, ok := given[question.ID]
+			delete(given, question.ID)
+			answer.ID = uuid.Nil
+			answer.AttemptID = attemptID
+			answer.QuestionID = question.ID
+			if ok {
+				if err := checkAnswerShape(question, answer); err != nil {
+					return err
+				}
+				answer.Correct, answer.Points = gradeAnswer(question, answer)
+			} else {
+				correct, points := false, 0.0
+				answer.Correct, answer.Points = &correct, &points
+			}
+			graded = append(graded, answer)
+		}
+		if len(given) > 0 {
+			return errors.New("answers given to questions not in this quiz")
+		}
+		if err := tx.CreateAnswers(graded); err != nil {
+			return err
+		}
+
+		attempt.SubmittedAt = &now
+		attempt.Answers = graded
+		scoreAttempt(&attempt)
+		return tx.UpdateAttempt(&attempt)
+	})
+	if err != nil {
+		return models.QuizAttempt{}, err
+	}
+	if timedOut {
+		return models.QuizAttempt{}, ErrQuizTimeUp
+	}
+	return s.repo.GetAttemptByID(attemptID)
+}
+
+// GradeAnswer lets the tutor award points for one answer of a submitted attempt, usually
+// a short answer that could not be graded automatically. Grading an answer again
+// overrides the earlier grade.
+func (s *quizService) GradeAnswer(attemptID, questionID, tutorID uuid.UUID, points float64) (models.QuizAttempt, error) {
+	err := s.repo.Transaction(func(tx repositories.QuizRepository) error {
+		attempt, err := tx.LockAttempt(attemptID)
+		if err != nil {
+			return errors.New("attempt not found")
+		}
+		quiz, err := tx.GetQuizByID(attempt.QuizID)
+		if err != nil {
+			return err
+		}
+		if quiz.TutorID != tutorID {
+			return errors.New("only the quiz's tutor can grade its answers")
+		}
+		if attempt.Status != models.QuizAttemptStatusNeedsReview && attempt.Status != models.QuizAttemptStatusGraded {
+			return errors.New("only submitted attempts can be graded")
+		}
+
+		var question *models.QuizQuestion
+		for i := range quiz.Questions {
+			if quiz.Questions[i].ID == questionID {
+				question = &quiz.Questions[i]
+			}
+		}
+		if question == nil {
+			return errors.New("question not in this quiz")
+		}
+		if points < 0 || points > question.Points {
+			return fmt.Errorf("points must be between 0 and %g", question.Points)
+		}
+
+		for i := range attempt.Answers {
+			answer := &attempt.Answers[i]
+			if answer.QuestionID != questionID {
+				continue
+			}
+			correct := points == question.Points
+			answer.Correct = &correct
+			answer.Points = &points
+			if err := tx.UpdateAnswer(answer); err != nil {
+				return err
+			}
+			scoreAttempt(&attempt)
+			return tx.UpdateAttempt(&attempt)
+		}
+		return errors.New("question not answered in this attempt")
+	})
+	if err != nil {
+		return models.QuizAttempt{}, err
+	}
+	return s.repo.GetAttemptByID(attemptID)
+}
+
+// GetAttempts lists the attempts at a quiz. The tutor sees every student's attempts;
+// a student only sees their own.
+func (s *quizService) GetAttempts(quizID, userID uuid.UUID) ([]models.QuizAttempt, error) {
+	quiz, err := s.GetQuiz(quizID, userID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.repo.GetAttemptsByQuizID(quizID)
+	if err != nil {
+		return nil, err
+	}
+	if quiz.TutorID == userID {
+		return attempts, nil
+	}
+	own := []models.QuizAttempt{}
+	for _, attempt := range attempts {
+		if attempt.StudentID == userID {
+			own = append(own, attempt)
+		}
+	}
+	return own, nil
+}
+
+// ExpireAttempts closes attempts that ran past their time limit without being submitted.
+func (s *quizService) ExpireAttempts() (int64, error) {
+	return s.repo.ExpireAttempts(time.Now().Add(-quizGracePeriod))
+}
+
+// GetCourseQuizResults aggregates the graded attempts at each quiz of a course, using
+// each student's best attempt.
+func (s *quizService) GetCourseQuizResults(courseID uuid.UUID) ([]models.QuizResult, error) {
+	quizzes, err := s.repo.GetQuizzesByCourseID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.repo.GetGradedAttemptsByCourseID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	byQuiz := make(map[uuid.UUID][]models.QuizAttempt)
+	for _, attempt := range attempts {
+		byQuiz[attempt.QuizID] = append(byQuiz[attempt.QuizID], attempt)
+	}
+
+	results := make([]models.QuizResult, 0, len(quizzes))
+	for _, quiz := range quizzes {
+		result := models.QuizResult{
+			QuizID:   quiz.ID,
+			Title:    quiz.Title,
+			MaxScore: quiz.MaxScore(),
+			Scores:   []models.StudentQuizScore{},
+		}
+		// Index of each student's entry in result.Scores.
+		index := make(map[uuid.UUID]int)
+		for _, attempt := range byQuiz[quiz.ID] {
+			result.Attempts++
+			i, ok := index[attempt.StudentID]
+			if !ok {
+				i = len(result.Scores)
+				index[attempt.StudentID] = i
+				result.Scores = append(result.Scores, models.StudentQuizScore{StudentID: attempt.StudentID, BestScore: attempt.Score})
+			}
+			result.Scores[i].Attempts++
+			result.Scores[i].BestScore = math.Max(result.Scores[i].BestScore, attempt.Score)
+		}
+		result.Students = len(result.Scores)
+		if result.Students > 0 {
+			var total float64
+			for _, score := range result.Scores {
+				total += score.BestScore
+			}
+			result.AverageScore = total / float64(result.Students)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// editableQuiz returns the quiz if tutorID owns it and nobody has attempted it yet.
+func (s *quizService) editableQuiz(quizID, tutorID uuid.UUID) (models.Quiz, error) {
+	quiz, err := s.repo.GetQuizByID(quizID)
+	if err != nil {
+		return models.Quiz{}, errors.New("quiz not found")
+	}
+	if quiz.TutorID != tutorID {
+		return models.Quiz{}, errors.New("only the quiz's tutor can change it")
+	}
+	attempts, err := s.repo.CountAttempts(quizID)
+	if err != nil {
+		return models.Quiz{}, err
+	}
+	if attempts > 0 {
+		return models.Quiz{}, ErrQuizLocked
+	}
+	return quiz, nil
+}
+
+// checkAccess allows the tutor and the students of the quiz's course or lesson.
+func (s *quizService) checkAccess(quiz models.Quiz, userID uuid.UUID) error {
+	tutorID, studentIDs, err := s.quizParticipants(quiz)
+	if err != nil {
+		return err
+	}
+	if tutorID == userID {
+		return nil
+	}
+	for _, id := range studentIDs {
+		if id == userID {
+			return nil
+		}
+	}
+	return ErrNoQuizAccess
+}
+
+// quizParticipants returns the tutor and students of the quiz's course or lesson.
+func (s *quizService) quizParticipants(quiz models.Quiz) (uuid.UUID, []uuid.UUID, error) {
+	var studentIDs []uuid.UUID
+	if quiz.CourseID != nil {
+		course, err := s.courseRepo.GetCourseWithParticipants(*quiz.CourseID)
+		if err != nil {
+			return uuid.Nil, nil, errors.New("course not found")
+		}
+		for _, student := range course.Students {
+			studentIDs = append(studentIDs, student.ID)
+		}
+		return course.TutorID, studentIDs, nil
+	}
+	if quiz.LessonID != nil {
+		lesson, err := s.lessonRepo.GetLessonWithParticipants(*quiz.LessonID)
+		if err != nil {
+			return uuid.Nil, nil, errors.New("lesson not found")
+		}
+		for _, student := range lesson.Students {
+			studentIDs = append(studentIDs, student.ID)
+		}
+		return lesson.TutorID, studentIDs, nil
+	}
+	return uuid.Nil, nil, errors.New("quiz has neither a course nor a lesson")
+}
+
+// validateQuiz checks the quiz settings and each question, numbering the questions in
+// the order given.
+func validateQuiz(quiz *models.Quiz) error {
+	quiz.Title = strings.TrimSpace(quiz.Title)
+	if quiz.Title == "" {
+		return errors.New("quiz title is required")
+	}
+	if quiz.TimeLimitMinutes < 0 {
+		return errors.New("time_limit_minutes cannot be negative")
+	}
+	if quiz.MaxAttempts < 0 {
+		return errors.New("max_attempts cannot be negative")
+	}
+	if len(quiz.Questions) == 0 {
+		return errors.New("a quiz needs at least one question")
+	}
+	for i := range quiz.Questions {
+		question := &quiz.Questions[i]
+		question.ID = uuid.Nil
+		question.Position = i + 1
+		if err := validateQuestion(question); err != nil {
+			return fmt.Errorf("question %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateQuestion(question *models.QuizQuestion) error {
+	question.Prompt = strings.TrimSpace(question.Prompt)
+	if question.Prompt == "" {
+		return errors.New("prompt is required")
+	}
+	if question.Points < 0 {
+		return errors.New("points cannot be negative")
+	}
+	if question.Points == 0 {
+		question.Points = 1
+	}
+
+	switch question.Type {
+	case models.QuestionTypeMultipleChoice:
+		if len(question.Options) < 2 {
+			return errors.New("a multiple-choice question needs at least two options")
+		}
+		for _, option := range question.Options {
+			if strings.TrimSpace(option) == "" {
+				return errors.New("options cannot be empty")
+			}
+		}
+		if len(question.CorrectOptions) == 0 {
+			return errors.New("correct_options is required")
+		}
+		seen := make(map[int64]bool)
+		for _, index := range question.CorrectOptions {
+			if index < 0 || index >= int64(len(question.Options)) || seen[index] {
+				return errors.New("correct_options must be distinct option indexes")
+			}
+			seen[index] = true
+		}
+		question.CorrectNumber, question.Tolerance, question.AcceptedAnswers = nil, 0, nil
+	case models.QuestionTypeNumeric:
+		if question.CorrectNumber == nil {
+			return errors.New("correct_number is required")
+		}
+		if question.Tolerance < 0 {
+			return errors.New("tolerance cannot be negative")
+		}
+		question.Options, question.CorrectOptions, question.AcceptedAnswers = nil, nil, nil
+	case models.QuestionTypeShortAnswer:
+		accepted := make([]string, 0, len(question.AcceptedAnswers))
+		for _, answer := range question.AcceptedAnswers {
+			if answer = strings.TrimSpace(answer); answer != "" {
+				accepted = append(accepted, answer)
+			}
+		}
+		question.AcceptedAnswers = accepted
+		question.Options, question.CorrectOptions, question.CorrectNumber, question.Tolerance = nil, nil, nil, 0
+	default:
+		return fmt.Errorf("invalid question type %q", question.Type)
+	}
+	return nil
+}
+
+// checkAnswerShape rejects answers that do not fit the question, such as an option index
+// out of range.
+func checkAnswerShape(question models.QuizQuestion, answer models.QuizAnswer) error {
+	if question.Type == models.QuestionTypeMultipleChoice {
+		for _, choice := range answer.Choices {
+			if choice < 0 || choice >= int64(len(question.Options)) {
+				return fmt.Errorf("question %d: invalid choice %d", question.Position, choice)
+			}
+		}
+	}
+	return nil
+}
+
+// gradeAnswer grades an answer automatically. It returns nil points for a short answer
+// the tutor has to grade.
+func gradeAnswer(question models.QuizQuestion, answer models.QuizAnswer) (*bool, *float64) {
+	var correct bool
+	switch question.Type {
+	case models.QuestionTypeMultipleChoice:
+		correct = sameChoices(question.CorrectOptions, answer.Choices)
+	case models.QuestionTypeNumeric:
+		correct = answer.Number != nil && math.Abs(*answer.Number-*question.CorrectNumber) <= question.Tolerance
+	case models.QuestionTypeShortAnswer:
+		if len(question.AcceptedAnswers) == 0 {
+			return nil, nil
+		}
+		given := normalizeAnswer(answer.Text)
+		for _, accepted := range question.AcceptedAnswers {
+			if normalizeAnswer(accepted) == given {
+				correct = true
+			}
+		}
+	}
+
+	points := 0.0
+	if correct {
+		points = question.Points
+	}
+	return &correct, &points
+}
+
+// sameChoices reports whether the chosen options are exactly the correct ones.
+func sameChoices(correct, chosen []int64) bool {
+	want := make(map[int64]bool, len(correct))
+	for _, index := range correct {
+		want[index] = true
+	}
+	got := make(map[int64]bool, len(chosen))
+	for _, index := range chosen {
+		if !want[index] {
+			return false
+		}
+		got[index] = true
+	}
+	return len(got) == len(want)
+}
+
+// normalizeAnswer lowercases a short answer and collapses its whitespace.
+func normalizeAnswer(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// scoreAttempt sums the points awarded so far and sets whether the attempt is fully graded.
+func scoreAttempt(attempt *models.QuizAttempt) {
+	attempt.Score = 0
+	attempt.Status = models.QuizAttemptStatusGraded
+	for _, answer := range attempt.Answers {
+		if answer.Points == nil {
+			attempt.Status = models.QuizAttemptStatusNeedsReview
+			continue
+		}
+		attempt.Score += *answer.Points
+	}
+}
+
+// attemptTimedOut reports whether the attempt's time limit, plus the grace period, has
+// passed at now.
+func attemptTimedOut(attempt models.QuizAttempt, now time.Time) bool {
+	return attempt.ExpiresAt != nil && now.After(attempt.ExpiresAt.Add(quizGracePeriod))
+}
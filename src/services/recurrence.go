@@ -0,0 +1,233 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// Series are expanded in the tutor's IANA time zone, so make sure the
+	// zone database is available even on minimal hosts.
+	_ "time/tzdata"
+)
+
+// maxSeriesOccurrences bounds how many occurrences a single rule may expand to.
+const maxSeriesOccurrences = 1000
+
+// recurrenceRule is the subset of RFC 5545 RRULE supported for lesson series:
+// FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL, BYDAY (weekly only), COUNT and UNTIL.
+type recurrenceRule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday
+	Count    int
+	Until    time.Time
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseRRule parses an RRULE string such as "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20260630".
+// A leading "RRULE:" prefix is accepted.
+func parseRRule(value string) (recurrenceRule, error) {
+	rule := recurrenceRule{Interval: 1}
+
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return rule, errors.New("rrule is required")
+	}
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return rule, fmt.Errorf("invalid rrule part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, errors.New("rrule INTERVAL must be a positive integer")
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				weekday, ok := rruleWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return rule, fmt.Errorf("invalid rrule BYDAY value %q", day)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, errors.New("rrule COUNT must be a positive integer")
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseRRuleTime(val)
+			if err != nil {
+				return rule, err
+			}
+			rule.Until = until
+		default:
+			return rule, fmt.Errorf("unsupported rrule part %q", key)
+		}
+	}
+
+	switch rule.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY":
+	case "":
+		return rule, errors.New("rrule FREQ is required")
+	default:
+		return rule, fmt.Errorf("unsupported rrule FREQ %q", rule.Freq)
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != "WEEKLY" {
+		return rule, errors.New("rrule BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return rule, errors.New("rrule COUNT and UNTIL cannot be combined")
+	}
+	if rule.Count > maxSeriesOccurrences {
+		return rule, fmt.Errorf("rrule COUNT cannot exceed %d", maxSeriesOccurrences)
+	}
+	return rule, nil
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day.
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid rrule UNTIL value %q", value)
+}
+
+// String renders the rule back into RRULE form.
+func (r recurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, weekday := range r.ByDay {
+			for code, d := range rruleWeekdays {
+				if d == weekday {
+					days = append(days, code)
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// shiftDays returns the rule with its BYDAY weekdays moved by days, e.g. MO,WE shifted
+// by one day becomes TU,TH.
+func (r recurrenceRule) shiftDays(days int) recurrenceRule {
+	shifted := r
+	shifted.ByDay = make([]time.Weekday, 0, len(r.ByDay))
+	for _, weekday := range r.ByDay {
+		shifted.ByDay = append(shifted.ByDay, time.Weekday(((int(weekday)+days)%7+7)%7))
+	}
+	return shifted
+}
+
+// occurrences expands the rule starting at dtstart and returns the occurrence start
+// times that fall in [from, to). COUNT is always counted from dtstart. Occurrences keep
+// dtstart's wall-clock time in dtstart's location, so they follow DST changes.
+func (r recurrenceRule) occurrences(dtstart, from, to time.Time) []time.Time {
+	var result []time.Time
+	emitted := 0
+
+	// emit reports whether expansion should continue.
+	emit := func(t time.Time) bool {
+		if t.Before(dtstart) {
+			return true
+		}
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return false
+		}
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		if emitted >= maxSeriesOccurrences || !t.Before(to) {
+			return false
+		}
+		emitted++
+		if !t.Before(from) {
+			result = append(result, t)
+		}
+		return true
+	}
+
+	loc := dtstart.Location()
+	hour, minute, second := dtstart.Clock()
+	year, month, day := dtstart.Date()
+
+	switch r.Freq {
+	case "DAILY":
+		for i := 0; ; i += r.Interval {
+			if !emit(time.Date(year, month, day+i, hour, minute, second, 0, loc)) {
+				return result
+			}
+		}
+	case "WEEKLY":
+		byDay := r.ByDay
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{dtstart.Weekday()}
+		}
+		// Order the days as they fall in an ISO week starting on Monday.
+		offsets := make([]int, 0, len(byDay))
+		for _, weekday := range byDay {
+			offsets = append(offsets, (int(weekday)+6)%7)
+		}
+		sort.Ints(offsets)
+
+		weekStart := day - (int(dtstart.Weekday())+6)%7
+		for week := 0; ; week += r.Interval {
+			for _, offset := range offsets {
+				t := time.Date(year, month, weekStart+week*7+offset, hour, minute, second, 0, loc)
+				if !emit(t) {
+					return result
+				}
+			}
+		}
+	case "MONTHLY":
+		for i := 0; ; i += r.Interval {
+			t := time.Date(year, month+time.Month(i), day, hour, minute, second, 0, loc)
+			if t.Day() != day {
+				// Skip months without this day (e.g. the 31st), as RFC 5545 does.
+				if i > maxSeriesOccurrences*r.Interval {
+					return result
+				}
+				continue
+			}
+			if !emit(t) {
+				return result
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// maxRescheduleOptions bounds how many alternative time windows one proposal may offer.
+const maxRescheduleOptions = 5
+
+// Reschedule errors
+var (
+	// ErrProposalPending is returned when a lesson already has a pending reschedule proposal.
+	ErrProposalPending = errors.New("lesson already has a pending reschedule proposal")
+	// ErrProposalNotPending is returned when answering a proposal that was already answered or has expired.
+	ErrProposalNotPending = errors.New("reschedule proposal is no longer pending")
+	// ErrNotProposalCounterpart is returned when someone other than the counterpart answers a proposal.
+	ErrNotProposalCounterpart = errors.New("only the other party of the lesson can answer this proposal")
+)
+
+// RescheduleService defines business logic for lesson reschedule proposals.
+// A participant proposes one or more time windows; the lesson moves only when the
+// counterpart accepts one: the tutor for a student's proposal, and the student for the
+// tutor's, or every student of a group lesson.
+type RescheduleService interface {
+	ProposeReschedule(lessonID, proposerID uuid.UUID, options []models.RescheduleOption, message string) (models.RescheduleProposal, error)
+	GetProposalsForLesson(lessonID, userID uuid.UUID) ([]models.RescheduleProposal, error)
+	AcceptProposal(proposalID, userID, optionID uuid.UUID) (models.RescheduleProposal, models.Lesson, error)
+	RejectProposal(proposalID, userID uuid.UUID, note string) (models.RescheduleProposal, error)
+	CounterProposal(proposalID, userID uuid.UUID, options []models.RescheduleOption, message string) (models.RescheduleProposal, error)
+
+	// ExpireProposals expires pending proposals past their deadline.
+	ExpireProposals() (int64, error)
+}
+
+type rescheduleService struct {
+	repo          repositories.RescheduleRepository
+	lessonService LessonService
+	ttl           time.Duration
+}
+
+// NewRescheduleService creates a new instance of RescheduleService.
+// Proposals expire after ttl, or when the lesson was due to start if that is sooner.
+func NewRescheduleService(repo repositories.RescheduleRepository, lessonService LessonService, ttl time.Duration) RescheduleService {
+	return &rescheduleService{
+		repo:          repo,
+		lessonService: lessonService,
+		ttl:           ttl,
+	}
+}
+
+// ProposeReschedule creates a pending proposal to move the lesson to one of options.
+func (s *rescheduleService) ProposeReschedule(lessonID, proposerID uuid.UUID, options []models.RescheduleOption, message string) (models.RescheduleProposal, error) {
+	lesson, err := s.lessonService.GetLessonWithParticipants(lessonID)
+	if err != nil {
+		return models.RescheduleProposal{}, errors.New("lesson not found")
+	}
+	if !isLessonParticipant(lesson, proposerID) {
+		return models.RescheduleProposal{}, errors.New("only lesson participants can propose a new time")
+	}
+	return s.createProposal(lesson, proposerID, nil, options, message)
+}
+
+// GetProposalsForLesson returns the lesson's proposals, newest first, to one of its participants.
+func (s *rescheduleService) GetProposalsForLesson(lessonID, userID uuid.UUID) ([]models.RescheduleProposal, error) {
+	lesson, err := s.lessonService.GetLessonWithParticipants(lessonID)
+	if err != nil {
+		return nil, errors.New("lesson not found")
+	}
+	if !isLessonParticipant(lesson, userID) {
+		return nil, errors.New("only lesson participants can view reschedule proposals")
+	}
+	return s.repo.GetProposalsByLesson(lessonID)
+}
+
+// AcceptProposal moves the lesson to the chosen option. A lesson that was confirmed
+// stays confirmed, since both parties agreed on the new time. A tutor's proposal for a
+// group lesson only moves it once every student has accepted the same option; until
+// then the proposal stays pending and the lesson is returned unchanged.
+func (s *rescheduleService) AcceptProposal(proposalID, userID, optionID uuid.UUID) (models.RescheduleProposal, models.Lesson, error) {
+	proposal, lesson, err := s.getProposalForResponse(proposalID, userID)
+	if err != nil {
+		return models.RescheduleProposal{}, models.Lesson{}, err
+	}
+
+	var option *models.RescheduleOption
+	for i := range proposal.Options {
+		if proposal.Options[i].ID == optionID {
+			option = &proposal.Options[i]
+		}
+	}
+	if option == nil {
+		return models.RescheduleProposal{}, models.Lesson{}, errors.New("option does not belong to this proposal")
+	}
+
+	// Claim the proposal first so two concurrent answers cannot both move the lesson.
+	agreed := true
+	err = s.repo.Transaction(func(tx repositories.RescheduleRepository) error {
+		if needsEveryStudent(lesson, proposal) {
+			var err error
+			if agreed, err = recordAcceptance(tx, lesson, proposal.ID, userID, option.ID); err != nil || !agreed {
+				return err
+			}
+		}
+		proposal.AcceptedOptionID = &option.ID
+		return respond(tx, &proposal, models.RescheduleStatusAccepted, userID, "")
+	})
+	if err != nil {
+		return models.RescheduleProposal{}, models.Lesson{}, err
+	}
+	if !agreed {
+		proposal, err = s.repo.GetProposalByID(proposal.ID)
+		if err != nil {
+			return models.RescheduleProposal{}, models.Lesson{}, err
+		}
+		return proposal, lesson, nil
+	}
+
+	moved, err := s.lessonService.RescheduleLesson(lesson.ID, userID, option.StartTime, option.EndTime)
+	if err != nil {
+		// Reopen the proposal so another option can still be accepted.
+		proposal.Status = models.RescheduleStatusPending
+		proposal.RespondedByID = nil
+		proposal.RespondedAt = nil
+		proposal.AcceptedOptionID = nil
+		if _, revertErr := s.repo.UpdateProposalIfStatus(&proposal, models.RescheduleStatusAccepted); revertErr != nil {
+			return models.RescheduleProposal{}, models.Lesson{}, revertErr
+		}
+		return models.RescheduleProposal{}, models.Lesson{}, err
+	}
+	return proposal, moved, nil
+}
+
+// RejectProposal declines a proposal; the lesson keeps its current time.
+func (s *rescheduleService) RejectProposal(proposalID, userID uuid.UUID, note string) (models.RescheduleProposal, error) {
+	proposal, _, err := s.getProposalForResponse(proposalID, userID)
+	if err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	if err := respond(s.repo, &proposal, models.RescheduleStatusRejected, userID, note); err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	return proposal, nil
+}
+
+// CounterProposal replaces a proposal with one offering different options, which the
+// original proposer must now answer.
+func (s *rescheduleService) CounterProposal(proposalID, userID uuid.UUID, options []models.RescheduleOption, message string) (models.RescheduleProposal, error) {
+	proposal, lesson, err := s.getProposalForResponse(proposalID, userID)
+	if err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	counter, err := s.newProposal(lesson, userID, &proposal.ID, options, message)
+	if err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	// The countered proposal stays pending unless its counter is stored.
+	err = s.repo.Transaction(func(tx repositories.RescheduleRepository) error {
+		if err := respond(tx, &proposal, models.RescheduleStatusCountered, userID, message); err != nil {
+			return err
+		}
+		return insertProposal(tx, &counter)
+	})
+	if err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	return s.repo.GetProposalByID(counter.ID)
+}
+
+// ExpireProposals expires pending proposals past their deadline.
+func (s *rescheduleService) ExpireProposals() (int64, error) {
+	return s.repo.ExpirePendingProposals(time.Now())
+}
+
+// createProposal validates options and stores a new pending proposal for lesson.
+func (s *rescheduleService) createProposal(lesson models.Lesson, proposerID uuid.UUID, counterOfID *uuid.UUID, options []models.RescheduleOption, message string) (models.RescheduleProposal, error) {
+	proposal, err := s.newProposal(lesson, proposerID, counterOfID, options, message)
+	if err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	if err := insertProposal(s.repo, &proposal); err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	return s.repo.GetProposalByID(proposal.ID)
+}
+
+// newProposal validates options and builds a pending proposal for lesson, without storing it.
+func (s *rescheduleService) newProposal(lesson models.Lesson, proposerID uuid.UUID, counterOfID *uuid.UUID, options []models.RescheduleOption, message string) (models.RescheduleProposal, error) {
+	if err := checkLessonTransition(lesson.Status, models.LessonStatusScheduled); err != nil {
+		return models.RescheduleProposal{}, err
+	}
+	if err := validateRescheduleOptions(options); err != nil {
+		return models.RescheduleProposal{}, err
+	}
+
+	expiresAt := time.Now().Add(s.ttl)
+	if lesson.StartTime.Before(expiresAt) {
+		expiresAt = lesson.StartTime
+	}
+
+	return models.RescheduleProposal{
+		LessonID:     lesson.ID,
+		ProposedByID: proposerID,
+		CounterOfID:  counterOfID,
+		Message:      message,
+		Options:      options,
+		Status:       models.RescheduleStatusPending,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// insertProposal stores a new pending proposal unless its lesson already has one.
+func insertProposal(repo repositories.RescheduleRepository, proposal *models.RescheduleProposal) error {
+	if _, err := repo.GetPendingProposalForLesson(proposal.LessonID); err == nil {
+		return ErrProposalPending
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err := repo.CreateProposal(proposal); err != nil {
+		// The one-pending-proposal-per-lesson index catches concurrent proposals.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrProposalPending
+		}
+		return err
+	}
+	return nil
+}
+
+// getProposalForResponse loads a pending proposal and its lesson, and checks that
+// userID is the counterpart who may answer it.
+func (s *rescheduleService) getProposalForResponse(proposalID, userID uuid.UUID) (models.RescheduleProposal, models.Lesson, error) {
+	proposal, err := s.repo.GetProposalByID(proposalID)
+	if err != nil {
+		return models.RescheduleProposal{}, models.Lesson{}, errors.New("reschedule proposal not found")
+	}
+	if proposal.Status != models.RescheduleStatusPending || !time.Now().Before(proposal.ExpiresAt) {
+		return models.RescheduleProposal{}, models.Lesson{}, ErrProposalNotPending
+	}
+	lesson, err := s.lessonService.GetLessonWithParticipants(proposal.LessonID)
+	if err != nil {
+		return models.RescheduleProposal{}, models.Lesson{}, err
+	}
+	if !isProposalCounterpart(lesson, proposal.ProposedByID, userID) {
+		return models.RescheduleProposal{}, models.Lesson{}, ErrNotProposalCounterpart
+	}
+	return proposal, lesson, nil
+}
+
+// respond moves a pending proposal to status on behalf of userID.
+func respond(repo repositories.RescheduleRepository, proposal *models.RescheduleProposal, status string, userID uuid.UUID, note string) error {
+	now := time.Now()
+	proposal.Status = status
+	proposal.RespondedByID = &userID
+	proposal.RespondedAt = &now
+	proposal.ResponseNote = note
+
+	updated, err := repo.UpdateProposalIfStatus(proposal, models.RescheduleStatusPending)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrProposalNotPending
+	}
+	return nil
+}
+
+// isProposalCounterpart reports whether userID may answer a proposal made by proposerID:
+// the tutor answers a student's proposal and the students answer the tutor's.
+func isProposalCounterpart(lesson models.Lesson, proposerID, userID uuid.UUID) bool {
+	if userID == proposerID || !isLessonParticipant(lesson, userID) {
+		return false
+	}
+	return proposerID == lesson.TutorID || userID == lesson.TutorID
+}
+
+// needsEveryStudent reports whether a proposal must be accepted by every student of the
+// lesson rather than by a single counterpart: a tutor's proposal for a group lesson.
+func needsEveryStudent(lesson models.Lesson, proposal models.RescheduleProposal) bool {
+	return proposal.ProposedByID == lesson.TutorID && len(lesson.Students) > 1
+}
+
+// recordAcceptance records that userID accepted optionID of a proposal for a group lesson
+// and reports whether every student of the lesson has now accepted that option. It must
+// run inside a RescheduleRepository transaction.
+func recordAcceptance(tx repositories.RescheduleRepository, lesson models.Lesson, proposalID, userID, optionID uuid.UUID) (bool, error) {
+	proposal, err := tx.LockProposal(proposalID)
+	if err != nil {
+		return false, err
+	}
+	if proposal.Status != models.RescheduleStatusPending {
+		return false, ErrProposalNotPending
+	}
+	if err := tx.SaveAcceptance(&models.RescheduleAcceptance{
+		ProposalID: proposalID,
+		UserID:     userID,
+		OptionID:   optionID,
+	}); err != nil {
+		return false, err
+	}
+
+	acceptances, err := tx.GetAcceptances(proposalID)
+	if err != nil {
+		return false, err
+	}
+	accepted := make(map[uuid.UUID]bool, len(acceptances))
+	for _, acceptance := range acceptances {
+		if acceptance.OptionID == optionID {
+			accepted[acceptance.UserID] = true
+		}
+	}
+	for _, student := range lesson.Students {
+		if !accepted[student.ID] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// validateRescheduleOptions checks that there are between one and maxRescheduleOptions
+// well-formed future time windows. Availability and conflicts are checked on acceptance.
+func validateRescheduleOptions(options []models.RescheduleOption) error {
+	if len(options) == 0 {
+		return fmt.Errorf("%w: at least one time option is required", ErrInvalidLessonTime)
+	}
+	if len(options) > maxRescheduleOptions {
+		return fmt.Errorf("%w: at most %d time options can be proposed", ErrInvalidLessonTime, maxRescheduleOptions)
+	}
+	now := time.Now()
+	for _, option := range options {
+		if !option.EndTime.After(option.StartTime) {
+			return fmt.Errorf("%w: end_time must be after start_time", ErrInvalidLessonTime)
+		}
+		if option.StartTime.Before(now) {
+			return fmt.Errorf("%w: lessons cannot be moved into the past", ErrInvalidLessonTime)
+		}
+	}
+	return nil
+}
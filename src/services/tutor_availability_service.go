@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"time"
+	"vibely-backend/src/events"
 	"vibely-backend/src/models"
 	"vibely-backend/src/repositories"
 )
@@ -29,15 +30,22 @@ type TutorAvailabilityService interface {
 type tutorAvailabilityService struct {
 	availabilityRepo repositories.TutorAvailabilityRepository
 	userRepo         repositories.UserRepository
+	publisher        events.Publisher
 }
 
-func NewTutorAvailabilityService(availabilityRepo repositories.TutorAvailabilityRepository, userRepo repositories.UserRepository) TutorAvailabilityService {
+func NewTutorAvailabilityService(availabilityRepo repositories.TutorAvailabilityRepository, userRepo repositories.UserRepository, publisher events.Publisher) TutorAvailabilityService {
 	return &tutorAvailabilityService{
 		availabilityRepo: availabilityRepo,
 		userRepo:         userRepo,
+		publisher:        publisher,
 	}
 }
 
+// publishAvailabilityChange tells those following the tutor's calendar that it changed.
+func publishAvailabilityChange(publisher events.Publisher, tutorID uuid.UUID) {
+	publisher.Publish(events.AvailabilityChanged, events.AvailabilityChange{TutorID: tutorID}, events.AvailabilityTopic(tutorID))
+}
+
 // CreateWeeklySchedule adds a new recurring time slot to a tutor's weekly schedule
 func (s *tutorAvailabilityService) CreateWeeklySchedule(tutorID uuid.UUID, dayOfWeek int, startTime, endTime string) (models.TutorWeeklySchedule, error) {
 	// Validate day of week (0-6)
@@ -79,7 +87,12 @@ func (s *tutorAvailabilityService) CreateWeeklySchedule(tutorID uuid.UUID, dayOf
 		EndTime:   endTime,
 	}
 
-	return s.availabilityRepo.CreateWeeklySchedule(schedule)
+	schedule, err = s.availabilityRepo.CreateWeeklySchedule(schedule)
+	if err != nil {
+		return models.TutorWeeklySchedule{}, err
+	}
+	publishAvailabilityChange(s.publisher, tutorID)
+	return schedule, nil
 }
 
 // GetWeeklySchedulesByTutorID retrieves all weekly schedule slots for a tutor
@@ -136,6 +149,7 @@ func (s *tutorAvailabilityService) UpdateWeeklySchedule(scheduleID uuid.UUID, da
 	if err != nil {
 		return models.TutorWeeklySchedule{}, err
 	}
+	publishAvailabilityChange(s.publisher, schedule.TutorID)
 
 	return schedule, nil
 }
@@ -143,12 +157,16 @@ func (s *tutorAvailabilityService) UpdateWeeklySchedule(scheduleID uuid.UUID, da
 // DeleteWeeklySchedule deletes a weekly schedule slot
 func (s *tutorAvailabilityService) DeleteWeeklySchedule(scheduleID uuid.UUID) error {
 	// Verify schedule exists
-	_, err := s.availabilityRepo.GetWeeklyScheduleByID(scheduleID)
+	schedule, err := s.availabilityRepo.GetWeeklyScheduleByID(scheduleID)
 	if err != nil {
 		return errors.New("schedule not found")
 	}
 
-	return s.availabilityRepo.DeleteWeeklySchedule(scheduleID)
+	if err := s.availabilityRepo.DeleteWeeklySchedule(scheduleID); err != nil {
+		return err
+	}
+	publishAvailabilityChange(s.publisher, schedule.TutorID)
+	return nil
 }
 
 // AddException adds a new exception to the tutor's schedule
@@ -191,7 +209,12 @@ func (s *tutorAvailabilityService) AddException(tutorID uuid.UUID, date time.Tim
 		IsRemoval: isRemoval,
 	}
 
-	return s.availabilityRepo.CreateException(exception)
+	exception, err = s.availabilityRepo.CreateException(exception)
+	if err != nil {
+		return models.TutorScheduleException{}, err
+	}
+	publishAvailabilityChange(s.publisher, tutorID)
+	return exception, nil
 }
 
 // GetExceptionsByTutorID retrieves all exceptions for a tutor in a date range
@@ -247,6 +270,7 @@ func (s *tutorAvailabilityService) UpdateException(exceptionID uuid.UUID, date t
 	if err != nil {
 		return models.TutorScheduleException{}, err
 	}
+	publishAvailabilityChange(s.publisher, exception.TutorID)
 
 	return exception, nil
 }
@@ -254,12 +278,16 @@ func (s *tutorAvailabilityService) UpdateException(exceptionID uuid.UUID, date t
 // DeleteException deletes a schedule exception
 func (s *tutorAvailabilityService) DeleteException(exceptionID uuid.UUID) error {
 	// Verify exception exists
-	_, err := s.availabilityRepo.GetExceptionByID(exceptionID)
+	exception, err := s.availabilityRepo.GetExceptionByID(exceptionID)
 	if err != nil {
 		return errors.New("exception not found")
 	}
 
-	return s.availabilityRepo.DeleteException(exceptionID)
+	if err := s.availabilityRepo.DeleteException(exceptionID); err != nil {
+		return err
+	}
+	publishAvailabilityChange(s.publisher, exception.TutorID)
+	return nil
 }
 
 // GetAvailabilityForDateRange calculates a tutor's availability for a date range
@@ -400,3 +428,21 @@ func (s *tutorAvailabilityService) GetAvailabilityForDateRange(tutorID uuid.UUID
 
 	return availableSlots, nil
 }
+
+// slotTimeRange converts an availability slot's "15:04" bounds into absolute times on
+// the slot's date, interpreting them as wall-clock times in loc.
+func slotTimeRange(slot models.AvailabilitySlot, loc *time.Location) (time.Time, time.Time, error) {
+	startClock, err := time.Parse("15:04", slot.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid slot start time %q", slot.StartTime)
+	}
+	endClock, err := time.Parse("15:04", slot.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid slot end time %q", slot.EndTime)
+	}
+
+	year, month, day := slot.Date.Date()
+	start := time.Date(year, month, day, startClock.Hour(), startClock.Minute(), 0, 0, loc)
+	end := time.Date(year, month, day, endClock.Hour(), endClock.Minute(), 0, 0, loc)
+	return start, end, nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+)
+
+// TutorOfferingService defines business logic for the lesson offerings tutors publish.
+type TutorOfferingService interface {
+	CreateOffering(offering models.TutorOffering) (models.TutorOffering, error)
+	GetOffering(offeringID uuid.UUID) (models.TutorOffering, error)
+	GetOfferings(tutorID uuid.UUID, includeArchived bool) ([]models.TutorOffering, error)
+	UpdateOffering(offering models.TutorOffering) (models.TutorOffering, error)
+	ArchiveOffering(tutorID, offeringID uuid.UUID) (models.TutorOffering, error)
+}
+
+type tutorOfferingService struct {
+	repo     repositories.TutorOfferingRepository
+	userRepo repositories.UserRepository
+}
+
+// NewTutorOfferingService creates a new instance of TutorOfferingService.
+func NewTutorOfferingService(repo repositories.TutorOfferingRepository, userRepo repositories.UserRepository) TutorOfferingService {
+	return &tutorOfferingService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// CreateOffering validates and publishes a new offering of the tutor.
+func (s *tutorOfferingService) CreateOffering(offering models.TutorOffering) (models.TutorOffering, error) {
+	if err := s.validateOffering(&offering); err != nil {
+		return models.TutorOffering{}, err
+	}
+	offering.ID = uuid.Nil
+	offering.Active = true
+	if err := s.repo.CreateOffering(&offering); err != nil {
+		return models.TutorOffering{}, err
+	}
+	return offering, nil
+}
+
+// GetOffering retrieves an offering by its ID.
+func (s *tutorOfferingService) GetOffering(offeringID uuid.UUID) (models.TutorOffering, error) {
+	return s.repo.GetOfferingByID(offeringID)
+}
+
+// GetOfferings lists the tutor's offerings, optionally including archived ones.
+func (s *tutorOfferingService) GetOfferings(tutorID uuid.UUID, includeArchived bool) ([]models.TutorOffering, error) {
+	return s.repo.GetOfferingsByTutorID(tutorID, !includeArchived)
+}
+
+// UpdateOffering replaces the editable fields of an existing offering. Lessons already
+// booked against it keep the price they were booked at.
+func (s *tutorOfferingService) UpdateOffering(offering models.TutorOffering) (models.TutorOffering, error) {
+	existing, err := s.repo.GetOfferingByID(offering.ID)
+	if err != nil || existing.TutorID != offering.TutorID {
+		return models.TutorOffering{}, errors.New("offering not found")
+	}
+	if err := s.validateOffering(&offering); err != nil {
+		return models.TutorOffering{}, err
+	}
+
+	existing.Title = offering.Title
+	existing.Description = offering.Description
+	existing.DurationMinutes = offering.DurationMinutes
+	existing.Price = offering.Price
+	existing.MaxStudents = offering.MaxStudents
+	existing.Subjects = offering.Subjects
+	existing.Levels = offering.Levels
+	existing.IsTrial = offering.IsTrial
+	if err := s.repo.UpdateOffering(&existing); err != nil {
+		return models.TutorOffering{}, err
+	}
+	return existing, nil
+}
+
+// ArchiveOffering stops an offering from being booked.
+func (s *tutorOfferingService) ArchiveOffering(tutorID, offeringID uuid.UUID) (models.TutorOffering, error) {
+	offering, err := s.repo.GetOfferingByID(offeringID)
+	if err != nil || offering.TutorID != tutorID {
+		return models.TutorOffering{}, errors.New("offering not found")
+	}
+	offering.Active = false
+	if err := s.repo.UpdateOffering(&offering); err != nil {
+		return models.TutorOffering{}, err
+	}
+	return offering, nil
+}
+
+// validateOffering checks an offering against the booking limits and the tutor's own
+// subjects and levels, normalizing the latter to the tutor's spelling.
+func (s *tutorOfferingService) validateOffering(offering *models.TutorOffering) error {
+	tutor, err := s.userRepo.GetUserByID(offering.TutorID)
+	if err != nil || tutor.Role != models.UserRoleTutor {
+		return errors.New("tutor not found")
+	}
+	if offering.Title == "" {
+		return errors.New("title is required")
+	}
+	if err := validateBookingMinutes(offering.DurationMinutes); err != nil {
+		return err
+	}
+	// A trial lesson may be free; anything else has a price.
+	if offering.Price < 0 || (offering.Price == 0 && !offering.IsTrial) {
+		return errors.New("price must be positive")
+	}
+	if offering.MaxStudents < 0 {
+		return errors.New("max_students cannot be negative")
+	}
+	if offering.MaxStudents == 0 {
+		offering.MaxStudents = 1
+	}
+	if offering.IsTrial && offering.IsGroup() {
+		return errors.New("a trial offering is for 1:1 lessons")
+	}
+
+	for i, subject := range offering.Subjects {
+		if offering.Subjects[i], err = pickTutorValue(tutor.Subjects, subject, "subject"); err != nil {
+			return err
+		}
+	}
+	for i, level := range offering.Levels {
+		if offering.Levels[i], err = pickTutorValue(tutor.Levels, level, "level"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateBookingMinutes checks a lesson length against the self-booking limits.
+func validateBookingMinutes(minutes int) error {
+	if minutes < minBookingMinutes || minutes > maxBookingMinutes || minutes%bookingMinuteStep != 0 {
+		return fmt.Errorf("%w: duration_minutes must be between %d and %d in steps of %d",
+			ErrInvalidLessonTime, minBookingMinutes, maxBookingMinutes, bookingMinuteStep)
+	}
+	return nil
+}
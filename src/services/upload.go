@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"vibely-backend/src/storage"
+)
+
+// ErrAttachmentTooLarge is returned when an uploaded file exceeds the size limit.
+var ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+// Upload is a file sent by a user, such as a lesson attachment or a homework submission.
+type Upload struct {
+	FileName    string
+	ContentType string
+	Body        io.Reader
+}
+
+// storedUpload describes an Upload once it has been saved.
+type storedUpload struct {
+	FileName    string
+	ContentType string
+	Size        int64
+}
+
+// saveUpload stores the upload under key. It reads at most maxSize bytes, so an oversized
+// upload is never stored in full.
+func saveUpload(files storage.Storage, key string, upload Upload, maxSize int64) (storedUpload, error) {
+	fileName := filepath.Base(strings.TrimSpace(upload.FileName))
+	if fileName == "" || fileName == "." || fileName == string(filepath.Separator) {
+		return storedUpload{}, errors.New("file name is required")
+	}
+	contentType := upload.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	size, err := files.Save(key, io.LimitReader(upload.Body, maxSize+1))
+	if err != nil {
+		return storedUpload{}, err
+	}
+	if size > maxSize {
+		deleteStoredFile(files, key)
+		return storedUpload{}, ErrAttachmentTooLarge
+	}
+	return storedUpload{FileName: fileName, ContentType: contentType, Size: size}, nil
+}
+
+// deleteStoredFile removes a file from storage, logging rather than failing when it
+// cannot; the file is then merely orphaned.
+func deleteStoredFile(files storage.Storage, key string) {
+	if err := files.Delete(key); err != nil {
+		log.Printf("failed to delete stored file %s: %v", key, err)
+	}
+}
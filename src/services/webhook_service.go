@@ -0,0 +1,382 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vibely-backend/src/events"
+	"vibely-backend/src/models"
+	"vibely-backend/src/repositories"
+	"vibely-backend/src/webhook"
+)
+
+// WebhookDeliveryJob is the kind of the queued jobs sending webhook deliveries.
+const WebhookDeliveryJob = "webhook-delivery"
+
+const (
+	// maxWebhooksPerUser caps the subscriptions of one account.
+	maxWebhooksPerUser = 10
+	// webhookMaxAttempts is how many times a delivery is attempted before it fails.
+	webhookMaxAttempts = 8
+)
+
+// WebhookEventTypes are the domain events sent to webhook subscriptions.
+var WebhookEventTypes = []string{
+	events.LessonCreated,
+	events.LessonUpdated,
+	events.CourseUpdated,
+	events.EnrollmentCreated,
+}
+
+var (
+	// ErrWebhookNotFound is returned when a user has no webhook subscription with the given ID.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookDeliveryNotFound is returned when a subscription has no delivery with the given ID.
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrWebhookInactive is returned when redelivering to a deactivated subscription.
+	ErrWebhookInactive = errors.New("webhook is inactive")
+	// ErrTooManyWebhooks is returned when a user already has maxWebhooksPerUser subscriptions.
+	ErrTooManyWebhooks = fmt.Errorf("no more than %d webhooks per account", maxWebhooksPerUser)
+)
+
+// webhookDeliveryJob is the payload of a WebhookDeliveryJob.
+type webhookDeliveryJob struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+// webhookPayload is the JSON body of a delivery.
+type webhookPayload struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookService sends the lesson and course events of an account to the endpoints it
+// subscribed, so partners can mirror bookings in their own systems. Every event sent is
+// logged as a delivery, attempted as a queued job and retried with exponential backoff
+// while the endpoint fails.
+type WebhookService interface {
+	// Publish creates deliveries of the events addressed to users with subscriptions.
+	events.Publisher
+
+	CreateSubscription(subscription models.WebhookSubscription) (models.WebhookSubscription, error)
+	GetSubscriptions(userID uuid.UUID) ([]models.WebhookSubscription, error)
+	UpdateSubscription(subscription models.WebhookSubscription) (models.WebhookSubscription, error)
+	DeleteSubscription(subscriptionID, userID uuid.UUID) error
+
+	GetDeliveries(subscriptionID, userID uuid.UUID, page, limit int) ([]models.WebhookDelivery, int64, error)
+	// Redeliver sends a logged delivery again, as a new delivery.
+	Redeliver(subscriptionID, deliveryID, userID uuid.UUID) (models.WebhookDelivery, error)
+
+	// Deliver runs a webhook delivery job.
+	Deliver(payload []byte) error
+	// PurgeDeliveries deletes the finished deliveries older than maxAge, run by the scheduler.
+	PurgeDeliveries(maxAge time.Duration) (int64, error)
+}
+
+type webhookService struct {
+	repo          repositories.WebhookRepository
+	jobRepo       repositories.JobRepository
+	sender        webhook.Sender
+	allowInsecure bool
+}
+
+// NewWebhookService creates a new instance of WebhookService posting deliveries with sender.
+// Endpoints must be https URLs on public addresses unless allowInsecure is set, which is
+// meant for local development.
+func NewWebhookService(repo repositories.WebhookRepository, jobRepo repositories.JobRepository, sender webhook.Sender, allowInsecure bool) WebhookService {
+	return &webhookService{
+		repo:          repo,
+		jobRepo:       jobRepo,
+		sender:        sender,
+		allowInsecure: allowInsecure,
+	}
+}
+
+// Publish creates a delivery of the event for each active subscription of its users that
+// wants it. All deliveries of an event share its ID.
+func (s *webhookService) Publish(eventType string, data interface{}, topics ...string) {
+	if !isWebhookEventType(eventType) {
+		return
+	}
+	subscriptions, err := s.repo.GetActiveSubscriptionsForUsers(topicUsers(topics))
+	if err != nil {
+		log.Printf("Failed to load webhook subscriptions for %s event: %v", eventType, err)
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	eventID := uuid.New()
+	payload, err := json.Marshal(webhookPayload{ID: eventID, Type: eventType, CreatedAt: time.Now(), Data: data})
+	if err != nil {
+		log.Printf("Failed to encode %s webhook payload: %v", eventType, err)
+		return
+	}
+	for _, subscription := range subscriptions {
+		if !subscription.Wants(eventType) {
+			continue
+		}
+		delivery := models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        string(payload),
+		}
+		if err := s.createDelivery(&delivery); err != nil {
+			log.Printf("Failed to queue %s webhook delivery to subscription %s: %v", eventType, subscription.ID, err)
+		}
+	}
+}
+
+// createDelivery stores a pending delivery and queues its first attempt.
+func (s *webhookService) createDelivery(delivery *models.WebhookDelivery) error {
+	delivery.Status = models.WebhookDeliveryPending
+	if err := s.repo.CreateDelivery(delivery); err != nil {
+		return err
+	}
+	return s.queue(delivery.ID, time.Now())
+}
+
+func (s *webhookService) queue(deliveryID uuid.UUID, runAt time.Time) error {
+	payload, err := json.Marshal(webhookDeliveryJob{DeliveryID: deliveryID})
+	if err != nil {
+		return err
+	}
+	return s.jobRepo.Enqueue(&models.QueuedJob{
+		Kind:    WebhookDeliveryJob,
+		Key:     WebhookDeliveryJob + ":" + deliveryID.String(),
+		Payload: string(payload),
+		RunAt:   runAt,
+	})
+}
+
+// CreateSubscription validates the subscription and creates it active, with a new signing
+// secret.
+func (s *webhookService) CreateSubscription(subscription models.WebhookSubscription) (models.WebhookSubscription, error) {
+	if err := s.validateWebhook(&subscription); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	existing, err := s.repo.GetSubscriptionsByUserID(subscription.UserID)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		return models.WebhookSubscription{}, ErrTooManyWebhooks
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	subscription.ID = uuid.Nil
+	subscription.Active = true
+	subscription.Secret = "whsec_" + hex.EncodeToString(secret)
+	if err := s.repo.CreateSubscription(&subscription); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	return subscription, nil
+}
+
+func (s *webhookService) GetSubscriptions(userID uuid.UUID) ([]models.WebhookSubscription, error) {
+	return s.repo.GetSubscriptionsByUserID(userID)
+}
+
+// UpdateSubscription replaces the URL, description, event filter and active flag of one
+// of the user's subscriptions. Its secret is kept.
+func (s *webhookService) UpdateSubscription(subscription models.WebhookSubscription) (models.WebhookSubscription, error) {
+	existing, err := s.getSubscription(subscription.ID, subscription.UserID)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	if err := s.validateWebhook(&subscription); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	existing.URL = subscription.URL
+	existing.Description = subscription.Description
+	existing.EventTypes = subscription.EventTypes
+	existing.Active = subscription.Active
+	if err := s.repo.UpdateSubscription(&existing); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	return existing, nil
+}
+
+// DeleteSubscription deletes one of the user's subscriptions and its delivery log.
+func (s *webhookService) DeleteSubscription(subscriptionID, userID uuid.UUID) error {
+	if _, err := s.getSubscription(subscriptionID, userID); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscription(subscriptionID)
+}
+
+// GetDeliveries returns a page of the delivery log of one of the user's subscriptions.
+func (s *webhookService) GetDeliveries(subscriptionID, userID uuid.UUID, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.getSubscription(subscriptionID, userID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.GetDeliveries(subscriptionID, page, limit)
+}
+
+// Redeliver queues the payload of a logged delivery again. The original delivery is left
+// as it is, so the log shows both.
+func (s *webhookService) Redeliver(subscriptionID, deliveryID, userID uuid.UUID) (models.WebhookDelivery, error) {
+	subscription, err := s.getSubscription(subscriptionID, userID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	if !subscription.Active {
+		return models.WebhookDelivery{}, ErrWebhookInactive
+	}
+	original, err := s.repo.GetDeliveryByID(deliveryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && original.SubscriptionID != subscriptionID) {
+		return models.WebhookDelivery{}, ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	delivery := models.WebhookDelivery{
+		SubscriptionID: subscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		RedeliveryOf:   &original.ID,
+	}
+	if err := s.createDelivery(&delivery); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// getSubscription returns one of the user's subscriptions.
+func (s *webhookService) getSubscription(subscriptionID, userID uuid.UUID) (models.WebhookSubscription, error) {
+	subscription, err := s.repo.GetSubscriptionByID(subscriptionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && subscription.UserID != userID) {
+		return models.WebhookSubscription{}, ErrWebhookNotFound
+	}
+	return subscription, err
+}
+
+// Deliver makes one attempt at a pending delivery and logs its outcome. Until the
+// endpoint accepts it or webhookMaxAttempts is reached, the next attempt is queued after
+// webhookRetryDelay; deliveries to deactivated subscriptions fail right away.
+func (s *webhookService) Deliver(payload []byte) error {
+	var job webhookDeliveryJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+	delivery, err := s.repo.GetDeliveryByID(job.DeliveryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.Status != models.WebhookDeliveryPending {
+		return nil
+	}
+	subscription, err := s.repo.GetSubscriptionByID(delivery.SubscriptionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.NextAttemptAt = nil
+	delivery.ResponseStatus = 0
+	delivery.ResponseBody = ""
+	delivery.Error = ""
+	if subscription.Active {
+		resp, err := s.sender.Send(subscription.URL, subscription.Secret, delivery.EventType, delivery.ID.String(), []byte(delivery.Payload))
+		delivery.ResponseStatus = resp.StatusCode
+		delivery.ResponseBody = strings.ToValidUTF8(resp.Body, "")
+		switch {
+		case err != nil:
+			delivery.Error = err.Error()
+		case resp.OK():
+			delivery.Status = models.WebhookDeliverySucceeded
+			delivery.DeliveredAt = &now
+		default:
+			delivery.Error = fmt.Sprintf("endpoint returned %d", resp.StatusCode)
+		}
+	} else {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.Error = ErrWebhookInactive.Error()
+	}
+	if delivery.Status == models.WebhookDeliveryPending {
+		if delivery.Attempts >= webhookMaxAttempts {
+			delivery.Status = models.WebhookDeliveryFailed
+		} else {
+			next := now.Add(webhookRetryDelay(delivery.Attempts))
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := s.repo.UpdateDelivery(&delivery); err != nil {
+		return err
+	}
+	if delivery.NextAttemptAt != nil {
+		return s.queue(delivery.ID, *delivery.NextAttemptAt)
+	}
+	return nil
+}
+
+// webhookRetryDelay is the wait after the given failed attempt: 1 minute after the first,
+// doubling with each attempt, which spreads the attempts over about two hours.
+func webhookRetryDelay(attempt int) time.Duration {
+	return time.Minute << (attempt - 1)
+}
+
+func (s *webhookService) PurgeDeliveries(maxAge time.Duration) (int64, error) {
+	return s.repo.DeleteDeliveriesBefore(time.Now().Add(-maxAge))
+}
+
+// validateWebhook checks the subscription's URL and normalizes its event filter.
+func (s *webhookService) validateWebhook(subscription *models.WebhookSubscription) error {
+	endpoint, err := url.Parse(subscription.URL)
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if !s.allowInsecure {
+		if endpoint.Scheme != "https" {
+			return errors.New("url must be an https URL")
+		}
+		if err := webhook.CheckURL(endpoint); err != nil {
+			return err
+		}
+	}
+
+	eventTypes := []string{}
+	for _, eventType := range subscription.EventTypes {
+		if !isWebhookEventType(eventType) {
+			return fmt.Errorf("unknown event type %q, use one of %s", eventType, strings.Join(WebhookEventTypes, ", "))
+		}
+		if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	subscription.EventTypes = eventTypes
+	return nil
+}
+
+func isWebhookEventType(eventType string) bool {
+	return slices.Contains(WebhookEventTypes, eventType)
+}
@@ -0,0 +1,22 @@
+// Package storage keeps uploaded files, such as lesson attachments, behind a small
+// interface so the backend holding them can be swapped without touching the services.
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when no file is stored under a key.
+var ErrNotFound = errors.New("file not found")
+
+// Storage stores files under slash-separated keys such as "lessons/<id>/<file>".
+type Storage interface {
+	// Save writes everything read from r under key, replacing any existing file, and
+	// returns the number of bytes written.
+	Save(key string, r io.Reader) (int64, error)
+	// Open returns the file stored under key. The caller closes it.
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the file stored under key. Deleting a missing file is not an error.
+	Delete(key string) error
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned for endpoints on loopback, private or link-local
+// addresses, which would let subscribers reach the server's own network.
+var ErrPrivateAddress = errors.New("webhook endpoints must be on a public address")
+
+// IsPublicAddress tells whether deliveries may be sent to addr.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsUnspecified() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() && // including 169.254.169.254, the cloud metadata service
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast()
+}
+
+// CheckURL resolves the host of an endpoint URL and refuses it unless all its addresses
+// are public. Send checks the address it connects to again, as DNS answers may change.
+func CheckURL(endpoint *url.URL) error {
+	host := endpoint.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !IsPublicAddress(addr) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("could not resolve %s", host)
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddress(addr) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// refusePrivateAddresses is a net.Dialer Control refusing connections to addresses that
+// aren't public.
+func refusePrivateAddresses(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddress(addrPort.Addr()) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
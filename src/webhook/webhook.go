@@ -0,0 +1,136 @@
+// Package webhook posts signed event payloads to the endpoints partners subscribe with.
+//
+// Each request carries the event type, the delivery ID and a signature header of the
+// form "t=<unix time>,v1=<hex HMAC-SHA256>", computed with the subscription's secret over
+// "<unix time>.<body>". Receivers verify it with Verify and should reject old timestamps.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request headers
+const (
+	HeaderEvent     = "X-Vibely-Event"
+	HeaderDelivery  = "X-Vibely-Delivery"
+	HeaderSignature = "X-Vibely-Signature"
+)
+
+// maxResponseBody caps how much of an endpoint's response is kept for the delivery log.
+const maxResponseBody = 2048
+
+// Response is what an endpoint answered to a delivery.
+type Response struct {
+	StatusCode int
+	// Body is the start of the response body.
+	Body string
+}
+
+// OK tells whether the endpoint accepted the delivery.
+func (r Response) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Sender posts webhook deliveries.
+type Sender interface {
+	// Send posts body to url, signed with secret. A response is returned whenever the
+	// endpoint answered, even with an error status; err is only set when it didn't.
+	Send(url, secret, eventType, deliveryID string, body []byte) (Response, error)
+}
+
+type sender struct {
+	http *http.Client
+}
+
+// NewSender creates a Sender giving up on endpoints after timeout. Redirects are not
+// followed: endpoints must answer at the URL they were registered with. Unless
+// allowPrivate is set, e.g. to test against local receivers during development,
+// connections to addresses that aren't public are refused when dialing.
+func NewSender(timeout time.Duration, allowPrivate bool) Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   refusePrivateAddresses,
+		}
+		transport.DialContext = dialer.DialContext
+		// Going through a proxy would hide the endpoint's address from the dialer.
+		transport.Proxy = nil
+	}
+	return &sender{http: &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+func (s *sender) Send(url, secret, eventType, deliveryID string, body []byte) (Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Response{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Vibely-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderSignature, Sign(secret, time.Now(), body))
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return Response{StatusCode: resp.StatusCode, Body: string(respBody)}, nil
+}
+
+// Sign returns the signature header of body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, signature(secret, timestamp, body))
+}
+
+// Verify checks a signature header against body and returns when it was signed.
+func Verify(secret, header string, body []byte) (time.Time, error) {
+	var timestamp, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			sig = value
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || sig == "" {
+		return time.Time{}, errors.New("malformed webhook signature")
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, timestamp, body))) {
+		return time.Time{}, errors.New("webhook signature mismatch")
+	}
+	return time.Unix(unix, 0), nil
+}
+
+func signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}